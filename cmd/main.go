@@ -22,8 +22,13 @@ import (
 	_ "github.com/lib/pq"
 
 	api "github.com/developer-overheid-nl/don-api-register/pkg/api_client"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/auth"
 	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/database"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/httpclient"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/httpcache"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/idempotency"
 	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/jobs"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/ratelimit"
 	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/repositories"
 	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/services"
 )
@@ -112,6 +117,13 @@ func main() {
 	if err != nil {
 		log.Fatalf("failed to load OAS version: %v", err)
 	}
+	// Het ophalen van OAS-documenten gebruikt overal de gedeelde Fetcher, dus
+	// die moet staan voordat services en jobs worden aangemaakt.
+	fetchPolicy, err := httpclient.FetchPolicyFromEnv()
+	if err != nil {
+		log.Fatalf("fetch configuratie ongeldig: %v", err)
+	}
+	httpclient.SetDefaultFetcher(httpclient.NewFetcher(fetchPolicy))
 	host := os.Getenv("DB_HOSTNAME")
 	user := os.Getenv("DB_USERNAME")
 	pass := os.Getenv("DB_PASSWORD")
@@ -145,14 +157,44 @@ func main() {
 	refreshJob := jobs.NewOASRefreshJob(APIsAPIService, context.Background())
 	harvesterService := services.NewHarvesterService(APIsAPIService)
 	jobs.SchedulePDOKHarvest(context.Background(), harvesterService)
+	jobs.ScheduleDeletedApiPurge(context.Background(), APIsAPIService)
 	defer func() {
 		if refreshJob != nil {
 			refreshJob.Stop()
 		}
 	}()
 
+	authenticator, err := auth.NewAuthenticator(auth.ConfigFromEnv())
+	if err != nil {
+		log.Fatalf("auth configuratie ongeldig: %v", err)
+	}
+	rateLimitConfig, err := ratelimit.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("rate limit configuratie ongeldig: %v", err)
+	}
+	trustedProxies, err := ratelimit.TrustedProxiesFromEnv()
+	if err != nil {
+		log.Fatalf("proxy configuratie ongeldig: %v", err)
+	}
+	idempotencyConfig, err := idempotency.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("idempotency configuratie ongeldig: %v", err)
+	}
+	cacheConfig, err := httpcache.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("cache configuratie ongeldig: %v", err)
+	}
+
 	// Start server
-	router := api.NewRouter(version, APIsAPIController)
+	apiKeysController := handler.NewApiKeysController(services.NewApiKeyService(repositories.NewApiKeyRepository(db)))
+	router := api.NewRouter(version, APIsAPIController,
+		api.WithAuthenticator(authenticator),
+		api.WithApiKeys(apiKeysController),
+		api.WithRateLimiter(ratelimit.New(rateLimitConfig)),
+		api.WithTrustedProxies(trustedProxies),
+		api.WithIdempotency(idempotency.New(repositories.NewIdempotencyRepository(db), idempotencyConfig)),
+		api.WithCacheControl(cacheConfig),
+	)
 
 	log.Println("Server is running on port 1337")
 	log.Fatal(http.ListenAndServe(":1337", router))
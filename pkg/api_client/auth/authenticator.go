@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Config bevat de instellingen voor het valideren van client credentials tokens.
+type Config struct {
+	Issuer              string
+	Audience            string
+	JWKSURL             string
+	JWKSFile            string // lokaal JWKS bestand, bv. voor tests
+	JWKSRefreshInterval time.Duration
+	Leeway              time.Duration
+	// OrganisationClaim is de claim met de TOOI-URI's van de organisaties
+	// waarvoor de client mag schrijven (standaard "organisations").
+	OrganisationClaim string
+}
+
+// DefaultOrganisationClaim is de claim die gebruikt wordt wanneer
+// AUTH_ORGANISATION_CLAIM niet gezet is.
+const DefaultOrganisationClaim = "organisations"
+
+// ConfigFromEnv leest de auth-configuratie uit de omgeving.
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Issuer:   strings.TrimSpace(os.Getenv("AUTH_ISSUER")),
+		Audience: strings.TrimSpace(os.Getenv("AUTH_AUDIENCE")),
+		JWKSURL:  strings.TrimSpace(os.Getenv("AUTH_JWKS_URL")),
+		JWKSFile: strings.TrimSpace(os.Getenv("AUTH_JWKS_FILE")),
+
+		OrganisationClaim: strings.TrimSpace(os.Getenv("AUTH_ORGANISATION_CLAIM")),
+	}
+	if raw := strings.TrimSpace(os.Getenv("AUTH_JWKS_REFRESH_INTERVAL")); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil {
+			cfg.JWKSRefreshInterval = d
+		}
+	}
+	return cfg
+}
+
+// Validate controleert of de verplichte velden gevuld zijn.
+func (c Config) Validate() error {
+	var missing []string
+	if c.Issuer == "" {
+		missing = append(missing, "AUTH_ISSUER")
+	}
+	if c.Audience == "" {
+		missing = append(missing, "AUTH_AUDIENCE")
+	}
+	if c.JWKSURL == "" && c.JWKSFile == "" {
+		missing = append(missing, "AUTH_JWKS_URL of AUTH_JWKS_FILE")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("auth configuratie onvolledig: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+var (
+	// ErrMissingToken wordt teruggegeven als er geen bearer token is meegestuurd.
+	ErrMissingToken = errors.New("bearer token ontbreekt")
+	// ErrInvalidToken wordt teruggegeven als het token niet gevalideerd kan worden.
+	ErrInvalidToken = errors.New("ongeldig bearer token")
+)
+
+// Authenticator valideert JWT bearer tokens tegen issuer, audience en JWKS.
+type Authenticator struct {
+	cfg    Config
+	keys   *keySet
+	parser *jwt.Parser
+}
+
+// NewAuthenticator laadt de JWKS en maakt een Authenticator.
+func NewAuthenticator(cfg Config) (*Authenticator, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	keys, err := newKeySet(cfg)
+	if err != nil {
+		return nil, err
+	}
+	leeway := cfg.Leeway
+	if leeway <= 0 {
+		leeway = 30 * time.Second
+	}
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(cfg.Issuer),
+		jwt.WithAudience(cfg.Audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(leeway),
+	)
+	if cfg.OrganisationClaim == "" {
+		cfg.OrganisationClaim = DefaultOrganisationClaim
+	}
+	return &Authenticator{cfg: cfg, keys: keys, parser: parser}, nil
+}
+
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	Scope    string   `json:"scope,omitempty"`
+	Scp      []string `json:"scp,omitempty"`
+	ClientID string   `json:"client_id,omitempty"`
+	Azp      string   `json:"azp,omitempty"`
+
+	raw map[string]any
+}
+
+// UnmarshalJSON bewaart naast de bekende velden ook alle claims, zodat de
+// organisatie-claim configureerbaar kan zijn.
+func (c *tokenClaims) UnmarshalJSON(data []byte) error {
+	type plain tokenClaims
+	if err := json.Unmarshal(data, (*plain)(c)); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &c.raw)
+}
+
+// Authenticate valideert het ruwe token en geeft de bijbehorende Principal terug.
+func (a *Authenticator) Authenticate(ctx context.Context, raw string) (*Principal, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, ErrMissingToken
+	}
+	var claims tokenClaims
+	_, err := a.parser.ParseWithClaims(raw, &claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return a.keys.lookup(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	principal := &Principal{
+		Subject:       claims.Subject,
+		ClientID:      firstNonEmpty(claims.ClientID, claims.Azp),
+		Scopes:        mergeScopes(claims.Scope, claims.Scp),
+		Organisations: organisationsFromClaim(claims.raw[a.cfg.OrganisationClaim]),
+	}
+	return principal, nil
+}
+
+// organisationsFromClaim accepteert zowel een lijst als een string met
+// spatie- of komma-gescheiden organisatie-URI's.
+func organisationsFromClaim(value any) []string {
+	var values []string
+	switch v := value.(type) {
+	case string:
+		values = strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' })
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if trimmed := strings.TrimSpace(v); trimmed != "" {
+			out = append(out, trimmed)
+		}
+	}
+	return out
+}
+
+func mergeScopes(scope string, scp []string) []string {
+	seen := make(map[string]bool)
+	out := make([]string, 0, len(scp))
+	for _, s := range append(strings.Fields(scope), scp...) {
+		if s == "" || seen[s] {
+			continue
+		}
+		seen[s] = true
+		out = append(out, s)
+	}
+	return out
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if trimmed := strings.TrimSpace(v); trimmed != "" {
+			return trimmed
+		}
+	}
+	return ""
+}
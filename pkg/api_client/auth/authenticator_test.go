@@ -0,0 +1,252 @@
+package auth_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/auth"
+	problem "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/problem"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/testutil"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIssuer   = "https://auth.example.com/realms/don"
+	testAudience = "api-register"
+)
+
+func newAuthenticator(t *testing.T) (*auth.Authenticator, *testutil.TokenIssuer) {
+	t.Helper()
+	issuer := testutil.NewTokenIssuer(t, testIssuer, testAudience)
+	a, err := auth.NewAuthenticator(auth.Config{
+		Issuer:   testIssuer,
+		Audience: testAudience,
+		JWKSFile: issuer.JWKSFile,
+	})
+	require.NoError(t, err)
+	return a, issuer
+}
+
+func TestNewAuthenticator_RequiresConfig(t *testing.T) {
+	_, err := auth.NewAuthenticator(auth.Config{Issuer: testIssuer})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "AUTH_AUDIENCE")
+	assert.Contains(t, err.Error(), "AUTH_JWKS_URL")
+}
+
+func TestAuthenticate_ValidToken(t *testing.T) {
+	a, issuer := newAuthenticator(t)
+
+	principal, err := a.Authenticate(context.Background(), issuer.Token(t, "client-1", "apis:read", "apis:write"))
+	require.NoError(t, err)
+	assert.Equal(t, "client-1", principal.Subject)
+	assert.True(t, principal.HasScope("apis:write"))
+	assert.False(t, principal.HasScope("organisations:write"))
+}
+
+func TestAuthenticate_AcceptsScpArrayAndClientID(t *testing.T) {
+	a, issuer := newAuthenticator(t)
+
+	token := issuer.SignClaims(t, jwt.MapClaims{
+		"iss":       testIssuer,
+		"aud":       []string{"account", testAudience},
+		"sub":       "service-account",
+		"client_id": "harvester",
+		"scp":       []string{"apis:read"},
+		"exp":       time.Now().Add(time.Minute).Unix(),
+	})
+	principal, err := a.Authenticate(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, "harvester", principal.ID())
+	assert.True(t, principal.HasScope("apis:read"))
+}
+
+func TestAuthenticate_OrganisationClaim(t *testing.T) {
+	a, issuer := newAuthenticator(t)
+
+	principal, err := a.Authenticate(context.Background(), issuer.OrganisationToken(t, "client-1",
+		[]string{"https://identifier.overheid.nl/tooi/id/gemeente/gm0363"}, "apis:write"))
+	require.NoError(t, err)
+	assert.True(t, principal.CanActFor("https://identifier.overheid.nl/tooi/id/gemeente/gm0363"))
+	assert.False(t, principal.CanActFor("https://identifier.overheid.nl/tooi/id/gemeente/gm0599"))
+	assert.False(t, principal.CanActFor(""))
+
+	custom, err := auth.NewAuthenticator(auth.Config{
+		Issuer:            testIssuer,
+		Audience:          testAudience,
+		JWKSFile:          issuer.JWKSFile,
+		OrganisationClaim: "tooi_orgs",
+	})
+	require.NoError(t, err)
+	token := issuer.SignClaims(t, jwt.MapClaims{
+		"iss":       testIssuer,
+		"aud":       testAudience,
+		"sub":       "client-2",
+		"tooi_orgs": "https://example.org/a, https://example.org/b",
+		"exp":       time.Now().Add(time.Minute).Unix(),
+	})
+	principal, err = custom.Authenticate(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://example.org/a", "https://example.org/b"}, principal.Organisations)
+}
+
+func TestPrincipal_AdminActsForAllOrganisations(t *testing.T) {
+	admin := &auth.Principal{Subject: "beheer", Scopes: []string{auth.AdminScope}}
+	assert.True(t, admin.IsAdmin())
+	assert.True(t, admin.CanActFor("https://example.org/elders"))
+
+	var anonymous *auth.Principal
+	assert.False(t, anonymous.CanActFor("https://example.org/elders"))
+}
+
+func TestAuthenticate_RejectsInvalidTokens(t *testing.T) {
+	a, issuer := newAuthenticator(t)
+	other := testutil.NewTokenIssuer(t, testIssuer, testAudience)
+
+	tests := map[string]string{
+		"wrong issuer": issuer.SignClaims(t, jwt.MapClaims{
+			"iss": "https://evil.example.com", "aud": testAudience, "exp": time.Now().Add(time.Minute).Unix(),
+		}),
+		"wrong audience": issuer.SignClaims(t, jwt.MapClaims{
+			"iss": testIssuer, "aud": "other", "exp": time.Now().Add(time.Minute).Unix(),
+		}),
+		"expired": issuer.SignClaims(t, jwt.MapClaims{
+			"iss": testIssuer, "aud": testAudience, "exp": time.Now().Add(-time.Hour).Unix(),
+		}),
+		"missing exp": issuer.SignClaims(t, jwt.MapClaims{
+			"iss": testIssuer, "aud": testAudience,
+		}),
+		"unknown key":   other.Token(t, "client-1", "apis:write"),
+		"not a jwt":     "abc.def.ghi",
+		"alg none-like": "eyJhbGciOiJub25lIn0.eyJzdWIiOiJ4In0.",
+	}
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := a.Authenticate(context.Background(), token)
+			require.ErrorIs(t, err, auth.ErrInvalidToken)
+		})
+	}
+
+	_, err := a.Authenticate(context.Background(), " ")
+	require.ErrorIs(t, err, auth.ErrMissingToken)
+}
+
+func TestRequireScopes_Middleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	a, issuer := newAuthenticator(t)
+
+	r := gin.New()
+	r.POST("/apis", auth.RequireScopes(a, "apis:write"), func(c *gin.Context) {
+		p := auth.PrincipalFromContext(c.Request.Context())
+		c.String(http.StatusCreated, p.Subject)
+	})
+
+	do := func(header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/apis", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("missing token", func(t *testing.T) {
+		w := do("")
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Bearer")
+		var prob problem.APIError
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &prob))
+		assert.Equal(t, 401, prob.Status)
+		assert.Equal(t, "Unauthorized", prob.Title)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		w := do("Bearer not-a-token")
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Header().Get("WWW-Authenticate"), `error="invalid_token"`)
+	})
+
+	t.Run("insufficient scope", func(t *testing.T) {
+		w := do("Bearer " + issuer.Token(t, "reader", "apis:read"))
+		require.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("WWW-Authenticate"), `error="insufficient_scope"`)
+		var prob problem.APIError
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &prob))
+		assert.Equal(t, 403, prob.Status)
+	})
+
+	t.Run("valid token with scope", func(t *testing.T) {
+		w := do("bearer " + issuer.Token(t, "writer", "apis:write"))
+		require.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "writer", w.Body.String())
+	})
+}
+
+type stubKeys map[string]*auth.Principal
+
+func (s stubKeys) ValidateAPIKey(_ context.Context, key string) (*auth.Principal, error) {
+	if p, ok := s[key]; ok {
+		return p, nil
+	}
+	return nil, auth.ErrInvalidAPIKey
+}
+
+func TestOptional_Middleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	a, issuer := newAuthenticator(t)
+	keys := stubKeys{"don_geldig": {Subject: "apikey:1", ApiKeyID: "1"}}
+
+	r := gin.New()
+	r.GET("/apis", auth.Optional(a, keys), func(c *gin.Context) {
+		c.String(http.StatusOK, auth.PrincipalFromContext(c.Request.Context()).ID())
+	})
+
+	do := func(headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/apis", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("anonymous", func(t *testing.T) {
+		w := do(nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+
+	t.Run("valid api key", func(t *testing.T) {
+		w := do(map[string]string{auth.APIKeyHeader: "don_geldig"})
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "apikey:1", w.Body.String())
+	})
+
+	t.Run("unknown api key", func(t *testing.T) {
+		w := do(map[string]string{auth.APIKeyHeader: "don_onbekend"})
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	})
+
+	t.Run("valid bearer token", func(t *testing.T) {
+		w := do(map[string]string{"Authorization": "Bearer " + issuer.Token(t, "reader", "apis:read")})
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "reader", w.Body.String())
+	})
+
+	t.Run("invalid bearer token", func(t *testing.T) {
+		w := do(map[string]string{"Authorization": "Bearer not-a-token"})
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
@@ -0,0 +1,246 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	jwksMaxBytes          = 1 << 20
+	jwksMinRefreshBackoff = time.Minute
+)
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type jwksDocument struct {
+	Keys []jwk `json:"keys"`
+}
+
+// keySet houdt de publieke sleutels uit een JWKS bij, geïndexeerd op kid.
+// Sleutels uit een URL worden periodiek ververst en opnieuw opgehaald
+// zodra een token een onbekende kid gebruikt.
+type keySet struct {
+	url        string
+	file       string
+	ttl        time.Duration
+	httpClient *http.Client
+
+	mu          sync.RWMutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	lastAttempt time.Time
+}
+
+func newKeySet(cfg Config) (*keySet, error) {
+	ks := &keySet{
+		url:        strings.TrimSpace(cfg.JWKSURL),
+		file:       strings.TrimSpace(cfg.JWKSFile),
+		ttl:        cfg.JWKSRefreshInterval,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+	if ks.url == "" && ks.file == "" {
+		return nil, errors.New("auth: JWKS URL of JWKS bestand is verplicht")
+	}
+	if ks.ttl <= 0 {
+		ks.ttl = time.Hour
+	}
+	if err := ks.refresh(context.Background()); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// lookup geeft de sleutel voor kid terug. Bij een onbekende kid of verlopen
+// cache wordt de JWKS (met backoff) opnieuw geladen.
+func (ks *keySet) lookup(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	ks.mu.RLock()
+	key, ok := ks.lookupLocked(kid)
+	stale := ks.url != "" && time.Since(ks.fetchedAt) > ks.ttl
+	ks.mu.RUnlock()
+	if ok && !stale {
+		return key, nil
+	}
+
+	if ks.shouldRefresh() {
+		if err := ks.refresh(ctx); err != nil && !ok {
+			return nil, err
+		}
+	}
+
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	if key, ok := ks.lookupLocked(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("onbekende sleutel (kid=%q)", kid)
+}
+
+func (ks *keySet) lookupLocked(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(ks.keys) == 1 {
+		for _, key := range ks.keys {
+			return key, true
+		}
+	}
+	key, ok := ks.keys[kid]
+	return key, ok
+}
+
+func (ks *keySet) shouldRefresh() bool {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if time.Since(ks.lastAttempt) < jwksMinRefreshBackoff {
+		return false
+	}
+	ks.lastAttempt = time.Now()
+	return true
+}
+
+func (ks *keySet) refresh(ctx context.Context) error {
+	raw, err := ks.load(ctx)
+	if err != nil {
+		return err
+	}
+	keys, err := parseJWKS(raw)
+	if err != nil {
+		return err
+	}
+	ks.mu.Lock()
+	ks.keys = keys
+	ks.fetchedAt = time.Now()
+	ks.mu.Unlock()
+	return nil
+}
+
+func (ks *keySet) load(ctx context.Context) (data []byte, err error) {
+	if ks.file != "" {
+		data, err := os.ReadFile(ks.file)
+		if err != nil {
+			return nil, fmt.Errorf("auth: kan JWKS bestand niet lezen: %w", err)
+		}
+		return data, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("auth: ongeldige JWKS URL: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := ks.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("auth: kan JWKS niet ophalen: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("auth: close JWKS response body: %w", closeErr)
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("auth: kan JWKS niet ophalen: status %d", resp.StatusCode)
+	}
+	data, err = io.ReadAll(io.LimitReader(resp.Body, jwksMaxBytes))
+	if err != nil {
+		return nil, fmt.Errorf("auth: kan JWKS niet lezen: %w", err)
+	}
+	return data, nil
+}
+
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var doc jwksDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("auth: ongeldige JWKS: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(doc.Keys))
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("auth: ongeldige sleutel kid=%q: %w", k.Kid, err)
+		}
+		if key == nil {
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("auth: JWKS bevat geen bruikbare signing keys")
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("modulus: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("exponent: %w", err)
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("exponent te groot")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("curve %q niet ondersteund", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("x: %w", err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("y: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		// Onbekende sleuteltypes (bv. symmetrische "oct") negeren we.
+		return nil, nil
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, errors.New("leeg")
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	problem "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/problem"
+	"github.com/gin-gonic/gin"
+)
+
+const bearerPrefix = "bearer "
+
+// RequireScopes valideert het bearer token en controleert of alle opgegeven
+// scopes aanwezig zijn. Bij een ontbrekend of ongeldig token volgt een 401,
+// bij ontbrekende scopes een 403 (beide als problem+json). De middleware
+// roept zelf geen c.Next() aan, zodat hij met andere handlers te combineren is.
+func RequireScopes(a *Authenticator, scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, err := a.Authenticate(c.Request.Context(), bearerToken(c.GetHeader("Authorization")))
+		if err != nil {
+			detail := "Geldig bearer token vereist"
+			if errors.Is(err, ErrInvalidToken) {
+				detail = "Bearer token is ongeldig of verlopen"
+			}
+			c.Header("WWW-Authenticate", wwwAuthenticate(err, scopes))
+			abortWithProblem(c, problem.NewUnauthorized(detail))
+			return
+		}
+		for _, scope := range scopes {
+			if !principal.HasScope(scope) {
+				c.Header("WWW-Authenticate", fmt.Sprintf(`Bearer error="insufficient_scope", scope="%s"`, strings.Join(scopes, " ")))
+				abortWithProblem(c, problem.NewForbidden("", fmt.Sprintf("scope '%s' is vereist voor deze operatie", scope)))
+				return
+			}
+		}
+		SetPrincipal(c, principal)
+	}
+}
+
+// SetPrincipal koppelt de principal aan zowel de gin- als de request-context,
+// zodat services de aanroeper via PrincipalFromContext kunnen opvragen.
+func SetPrincipal(c *gin.Context, p *Principal) {
+	c.Request = c.Request.WithContext(WithPrincipal(c.Request.Context(), p))
+}
+
+func bearerToken(header string) string {
+	header = strings.TrimSpace(header)
+	if len(header) < len(bearerPrefix) || !strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+		return ""
+	}
+	return strings.TrimSpace(header[len(bearerPrefix):])
+}
+
+func wwwAuthenticate(err error, scopes []string) string {
+	value := `Bearer`
+	if errors.Is(err, ErrInvalidToken) {
+		value += ` error="invalid_token"`
+	}
+	if len(scopes) > 0 {
+		if errors.Is(err, ErrInvalidToken) {
+			value += ","
+		}
+		value += fmt.Sprintf(` scope="%s"`, strings.Join(scopes, " "))
+	}
+	return value
+}
+
+func abortWithProblem(c *gin.Context, apiErr problem.APIError) {
+	c.Header("Content-Type", "application/problem+json")
+	c.AbortWithStatusJSON(apiErr.Status, apiErr)
+}
+
+// APIKeyHeader is de header waarin een API key wordt meegestuurd.
+const APIKeyHeader = "X-Api-Key"
+
+// ErrInvalidAPIKey wordt teruggegeven voor onbekende of ingetrokken API keys.
+var ErrInvalidAPIKey = errors.New("ongeldige API key")
+
+// APIKeyValidator valideert een API key en geeft de bijbehorende principal terug.
+type APIKeyValidator interface {
+	ValidateAPIKey(ctx context.Context, key string) (*Principal, error)
+}
+
+// Optional authenticeert de aanroeper op de publieke endpoints wanneer een
+// API key of bearer token is meegestuurd. Anonieme requests worden
+// doorgelaten; een meegestuurde maar ongeldige sleutel of token geeft een 401.
+// Net als RequireScopes roept Optional geen c.Next() aan.
+func Optional(a *Authenticator, keys APIKeyValidator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key := strings.TrimSpace(c.GetHeader(APIKeyHeader)); key != "" && keys != nil {
+			principal, err := keys.ValidateAPIKey(c.Request.Context(), key)
+			if err != nil {
+				detail := "API key is ongeldig of ingetrokken"
+				if !errors.Is(err, ErrInvalidAPIKey) {
+					abortWithProblem(c, problem.NewInternalServerError("kan API key niet valideren: "+err.Error()))
+					return
+				}
+				abortWithProblem(c, problem.NewUnauthorized(detail))
+				return
+			}
+			SetPrincipal(c, principal)
+			return
+		}
+		if token := bearerToken(c.GetHeader("Authorization")); token != "" && a != nil {
+			principal, err := a.Authenticate(c.Request.Context(), token)
+			if err != nil {
+				c.Header("WWW-Authenticate", wwwAuthenticate(err, nil))
+				abortWithProblem(c, problem.NewUnauthorized("Bearer token is ongeldig of verlopen"))
+				return
+			}
+			SetPrincipal(c, principal)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+	"strings"
+)
+
+// AdminScope geeft beheerders rechten over alle organisaties heen.
+const AdminScope = "admin"
+
+// ReviewScope geeft curatoren het recht nieuwe registraties goed of af te keuren.
+const ReviewScope = "apis:review"
+
+// Principal beschrijft de geauthenticeerde aanroeper van een request.
+type Principal struct {
+	Subject  string
+	ClientID string
+	ApiKeyID string // gevuld wanneer de aanroeper zich met een API key heeft gemeld
+	Scopes   []string
+	// Organisations bevat de TOOI-URI's van de organisaties namens wie de
+	// aanroeper mag schrijven.
+	Organisations []string
+}
+
+// HasScope geeft aan of de principal de opgegeven scope heeft.
+func (p *Principal) HasScope(scope string) bool {
+	if p == nil {
+		return false
+	}
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAdmin geeft aan of de principal namens alle organisaties mag handelen.
+func (p *Principal) IsAdmin() bool {
+	return p.HasScope(AdminScope)
+}
+
+// CanReview geeft aan of de principal registraties mag beoordelen.
+func (p *Principal) CanReview() bool {
+	return p.HasScope(ReviewScope) || p.IsAdmin()
+}
+
+// CanActFor geeft aan of de principal namens de organisatie mag schrijven.
+func (p *Principal) CanActFor(organisationURI string) bool {
+	if p == nil {
+		return false
+	}
+	if p.IsAdmin() {
+		return true
+	}
+	organisationURI = strings.TrimSpace(organisationURI)
+	if organisationURI == "" {
+		return false
+	}
+	for _, uri := range p.Organisations {
+		if uri == organisationURI {
+			return true
+		}
+	}
+	return false
+}
+
+// ID geeft een stabiele identificatie van de aanroeper terug.
+func (p *Principal) ID() string {
+	if p == nil {
+		return ""
+	}
+	if p.ApiKeyID != "" {
+		return "apikey:" + p.ApiKeyID
+	}
+	if p.ClientID != "" {
+		return p.ClientID
+	}
+	return p.Subject
+}
+
+type principalKey struct{}
+
+// WithPrincipal koppelt de principal aan de context.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext haalt de principal uit de context, of nil.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	if ctx == nil {
+		return nil
+	}
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
@@ -0,0 +1,34 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/models"
+	"gorm.io/gorm"
+)
+
+// BackfillArtifactDigests vult size en checksum van artifacts die zijn
+// opgeslagen voordat die kolommen bestonden. Nieuwe artifacts krijgen ze bij
+// het opslaan, dus na één run is er niets meer te doen. Hij geeft het aantal
+// bijgewerkte artifacts.
+func BackfillArtifactDigests(db *gorm.DB) (int, error) {
+	var ids []string
+	if err := db.Model(&models.ApiArtifact{}).
+		Where("checksum = '' OR checksum IS NULL").
+		Pluck("id", &ids).Error; err != nil {
+		return 0, fmt.Errorf("kan artifacts zonder checksum niet ophalen: %w", err)
+	}
+	for i, id := range ids {
+		var art models.ApiArtifact
+		if err := db.Select("id", "data").Take(&art, "id = ?", id).Error; err != nil {
+			return i, fmt.Errorf("kan artifact %s niet lezen: %w", id, err)
+		}
+		art.SetDigest(art.Data)
+		if err := db.Model(&models.ApiArtifact{}).
+			Where("id = ?", id).
+			Updates(map[string]any{"size": art.Size, "checksum": art.Checksum}).Error; err != nil {
+			return i, fmt.Errorf("kan checksum van artifact %s niet opslaan: %w", id, err)
+		}
+	}
+	return len(ids), nil
+}
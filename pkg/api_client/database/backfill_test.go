@@ -0,0 +1,37 @@
+package database_test
+
+import (
+	"testing"
+
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/database"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestBackfillArtifactDigests(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.ApiArtifact{}))
+
+	current := &models.ApiArtifact{ID: "current", ApiID: "a1", Kind: "bruno", Data: []byte("PK")}
+	current.SetDigest(current.Data)
+	require.NoError(t, db.Create(current).Error)
+	// Een artifact van voor de kolommen size en checksum.
+	require.NoError(t, db.Create(&models.ApiArtifact{ID: "legacy", ApiID: "a1", Kind: "postman", Data: []byte("{}")}).Error)
+
+	n, err := database.BackfillArtifactDigests(db)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	var stored models.ApiArtifact
+	require.NoError(t, db.Omit("data").Take(&stored, "id = ?", "legacy").Error)
+	assert.Equal(t, int64(2), stored.Size)
+	assert.Equal(t, "44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a", stored.Checksum)
+
+	n, err = database.BackfillArtifactDigests(db)
+	require.NoError(t, err)
+	assert.Zero(t, n, "een tweede run heeft niets meer te doen")
+}
@@ -21,9 +21,17 @@ func Connect(connStr string) (*gorm.DB, error) {
         &models.LintMessage{},
         &models.LintMessageInfo{},
         &models.ApiArtifact{},
+        &models.ApiKey{},
+        &models.AuditEntry{},
+        &models.ApiTransfer{},
+        &models.IdempotencyRecord{},
+        &models.ApiChange{},
     ); err != nil {
         return nil, fmt.Errorf("migration failed: %w", err)
     }
+    if _, err := BackfillArtifactDigests(db); err != nil {
+        return nil, fmt.Errorf("migration failed: %w", err)
+    }
 
     return db, nil
 }
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	problem "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/problem"
 	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/util"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/httpcache"
 	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/models"
 	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/services"
 	"github.com/gin-gonic/gin"
@@ -37,10 +39,24 @@ func (c *APIsAPIController) ListApis(ctx *gin.Context, p *models.ListApisParams)
 		return nil, err
 	}
 	util.SetPaginationHeaders(ctx.Request, ctx.Header, pagination)
+	if httpcache.Check(ctx, apiListETag(apis, pagination), time.Time{}) {
+		return nil, nil
+	}
 
 	return apis, nil
 }
 
+// apiListETag leidt de ETag van een pagina af uit de paginering en de revisie
+// van elke API erop; elke wijziging aan een API verhoogt zijn revisie.
+func apiListETag(apis []models.ApiSummary, pagination models.Pagination) string {
+	parts := make([]string, 0, len(apis)+1)
+	parts = append(parts, fmt.Sprintf("%d/%d/%d", pagination.CurrentPage, pagination.RecordsPerPage, pagination.TotalRecords))
+	for _, api := range apis {
+		parts = append(parts, api.Id+":"+strconv.FormatInt(api.Revision, 10))
+	}
+	return httpcache.WeakETag(parts...)
+}
+
 // ListApiFilters handles GET /apis/filters
 func (c *APIsAPIController) ListApiFilters(ctx *gin.Context, p *models.ApiFiltersParams) ([]models.FilterGroup, error) {
 	return c.Service.GetApiFilters(ctx.Request.Context(), p)
@@ -64,68 +80,223 @@ func (c *APIsAPIController) SearchApis(ctx *gin.Context, p *models.ListApisSearc
 }
 
 // RetrieveApi handles GET /apis/:id
-func (c *APIsAPIController) RetrieveApi(ctx *gin.Context, params *models.ApiParams) (*models.ApiDetail, error) {
-	api, err := c.Service.RetrieveApi(ctx.Request.Context(), params.Id)
+func (c *APIsAPIController) RetrieveApi(ctx *gin.Context, params *models.RetrieveApiParams) (*models.ApiDetail, error) {
+	api, err := c.Service.RetrieveApi(ctx.Request.Context(), params)
 	if err != nil {
 		return nil, err
 	}
 	if api == nil {
 		return nil, problem.NewNotFound(params.Id, "Api not found")
 	}
+	if httpcache.Check(ctx, detailETag(api.Revision, params), time.Time{}) {
+		return nil, nil
+	}
 	return api, nil
 }
 
+// detailETag onderscheidt de JSON-representaties van een revisie naar fields
+// en expand; de parameters zijn door de service al gevalideerd.
+func detailETag(revision int64, params *models.RetrieveApiParams) string {
+	fields, _ := models.ParseApiFields(params.Fields)
+	expand, _ := models.ParseApiExpand(params.Expand, models.DefaultDetailExpand)
+	view := models.ApiView{Fields: fields, Expand: expand}
+	return models.RepresentationETag(revision, "application/json", view.Key())
+}
+
 // ListLintResults handles GET /lint-results
-func (c *APIsAPIController) ListLintResults(ctx *gin.Context) ([]models.LintResult, error) {
-	return c.Service.ListLintResults(ctx.Request.Context())
+func (c *APIsAPIController) ListLintResults(ctx *gin.Context, p *models.ListLintResultsParams) ([]models.LintResult, error) {
+	results, pagination, err := c.Service.ListLintResults(ctx.Request.Context(), p)
+	if err != nil {
+		return nil, err
+	}
+	util.SetPaginationHeaders(ctx.Request, ctx.Header, pagination)
+	return results, nil
+}
+
+// ListApiLintResults handles GET /apis/:id/lint-results
+func (c *APIsAPIController) ListApiLintResults(ctx *gin.Context, p *models.ApiLintResultsParams) ([]models.LintResult, error) {
+	results, pagination, err := c.Service.ListApiLintResults(ctx.Request.Context(), p)
+	if err != nil {
+		return nil, err
+	}
+	util.SetPaginationHeaders(ctx.Request, ctx.Header, pagination)
+	return results, nil
+}
+
+// GetLatestLintResult handles GET /apis/:id/lint-results/latest
+func (c *APIsAPIController) GetLatestLintResult(ctx *gin.Context, params *models.ApiParams) (*models.LintResult, error) {
+	result, err := c.Service.GetLatestLintResult(ctx.Request.Context(), params.Id)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, problem.NewNotFound(params.Id, "Lint result not found")
+	}
+	return result, nil
+}
+
+// ListChanges handles GET /changes
+func (c *APIsAPIController) ListChanges(ctx *gin.Context, p *models.ListChangesParams) (*models.ChangeFeed, error) {
+	return c.Service.ListChanges(ctx.Request.Context(), p)
+}
+
+// ListApiAudit handles GET /apis/:id/audit
+func (c *APIsAPIController) ListApiAudit(ctx *gin.Context, p *models.ApiAuditParams) ([]models.AuditEntry, error) {
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.PerPage < 1 {
+		p.PerPage = 10
+	}
+	entries, pagination, err := c.Service.ListApiAudit(ctx.Request.Context(), p)
+	if err != nil {
+		return nil, err
+	}
+	util.SetPaginationHeaders(ctx.Request, ctx.Header, pagination)
+	return entries, nil
+}
+
+// ListAudit handles GET /audit
+func (c *APIsAPIController) ListAudit(ctx *gin.Context, p *models.ListAuditParams) ([]models.AuditEntry, error) {
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.PerPage < 1 {
+		p.PerPage = 10
+	}
+	entries, pagination, err := c.Service.ListAudit(ctx.Request.Context(), p)
+	if err != nil {
+		return nil, err
+	}
+	util.SetPaginationHeaders(ctx.Request, ctx.Header, pagination)
+	return entries, nil
 }
 
 // CreateApiFromOas handles POST /apis
 func (c *APIsAPIController) CreateApiFromOas(ctx *gin.Context, body *models.ApiPost) (*models.ApiSummary, error) {
-	created, err := c.Service.CreateApiFromOas(*body)
+	created, err := c.Service.CreateApiFromOas(ctx.Request.Context(), *body)
 	if err != nil {
 		return nil, err
 	}
 	return created, nil
 }
 
+// BulkCreateApis handles POST /apis/_bulk
+func (c *APIsAPIController) BulkCreateApis(ctx *gin.Context, body *models.ApiBulkInput) (*models.ApiBulkReport, error) {
+	return c.Service.BulkCreateApis(ctx.Request.Context(), body.Items)
+}
+
+// ValidateApiFromOas handles POST /apis/_validate
+func (c *APIsAPIController) ValidateApiFromOas(ctx *gin.Context, body *models.ApiPost) (*models.ApiValidation, error) {
+	return c.Service.ValidateApiFromOas(ctx.Request.Context(), *body)
+}
+
 // UpdateApi handles PUT /apis/:id
 func (c *APIsAPIController) UpdateApi(ctx *gin.Context, body *models.UpdateApiInput) (*models.ApiSummary, error) {
 	updated, err := c.Service.UpdateOasUri(ctx.Request.Context(), body)
 	if errors.Is(err, services.ErrNeedsPost) {
-		return nil, problem.NewNotFound(body.OasUrl, fmt.Sprintf("'%s' moet als nieuwe API geregistreerd worden via POST en de oude API als deprecated worden gemarkeerd", body.OasUrl),
+		return nil, problem.NewNotFound(body.OasUrl, fmt.Sprintf("'%s' moet als nieuwe API geregistreerd worden via POST met replaces '%s'; de huidige API wordt dan automatisch deprecated", body.OasUrl, body.Id),
 			problem.InvalidParam{Name: "oasUrl", Reason: "Deze URI is nieuw of significant gewijzigd"},
 		)
 	}
 	if err != nil {
 		return nil, err
 	}
+	ctx.Header("ETag", models.RevisionETag(updated.Revision))
 	return updated, nil
 }
 
+// PatchApi handles PATCH /apis/:id
+func (c *APIsAPIController) PatchApi(ctx *gin.Context, body *models.ApiPatch) (*models.ApiSummary, error) {
+	patched, err := c.Service.PatchApi(ctx.Request.Context(), body)
+	if err != nil {
+		return nil, err
+	}
+	ctx.Header("ETag", models.RevisionETag(patched.Revision))
+	return patched, nil
+}
+
+// DeleteApi handles DELETE /apis/:id
+func (c *APIsAPIController) DeleteApi(ctx *gin.Context, params *models.ApiParams) error {
+	return c.Service.DeleteApi(ctx.Request.Context(), params.Id)
+}
+
+// RestoreApi handles POST /apis/:id/restore
+func (c *APIsAPIController) RestoreApi(ctx *gin.Context, params *models.ApiParams) (*models.ApiSummary, error) {
+	return c.Service.RestoreApi(ctx.Request.Context(), params.Id)
+}
+
+// TransferApi handles POST /apis/:id/transfer
+func (c *APIsAPIController) TransferApi(ctx *gin.Context, body *models.ApiTransferInput) (*models.ApiTransfer, error) {
+	return c.Service.TransferApi(ctx.Request.Context(), body)
+}
+
+// ListReviews handles GET /reviews
+func (c *APIsAPIController) ListReviews(ctx *gin.Context, p *models.ListReviewsParams) ([]models.ApiReviewItem, error) {
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.PerPage < 1 {
+		p.PerPage = 10
+	}
+	items, pagination, err := c.Service.ListReviews(ctx.Request.Context(), p)
+	if err != nil {
+		return nil, err
+	}
+	util.SetPaginationHeaders(ctx.Request, ctx.Header, pagination)
+	return items, nil
+}
+
+// ApproveApi handles POST /apis/:id/approve
+func (c *APIsAPIController) ApproveApi(ctx *gin.Context, params *models.ApiParams) (*models.ApiSummary, error) {
+	return c.Service.ApproveApi(ctx.Request.Context(), params.Id)
+}
+
+// RejectApi handles POST /apis/:id/reject
+func (c *APIsAPIController) RejectApi(ctx *gin.Context, body *models.ApiRejectInput) (*models.ApiSummary, error) {
+	return c.Service.RejectApi(ctx.Request.Context(), body)
+}
+
 // ListOrganisations handles GET /organisations
-func (c *APIsAPIController) ListOrganisations(ctx *gin.Context) ([]models.OrganisationSummary, error) {
-	orgs, total, err := c.Service.ListOrganisations(ctx.Request.Context())
+func (c *APIsAPIController) ListOrganisations(ctx *gin.Context, p *models.ListOrganisationsParams) ([]models.OrganisationSummary, error) {
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.PerPage < 1 {
+		p.PerPage = 10
+	}
+	orgs, pagination, err := c.Service.ListOrganisations(ctx.Request.Context(), p)
 	if err != nil {
 		return nil, err
 	}
-	ctx.Header("Total-Count", fmt.Sprintf("%d", total))
-	// Convert []models.Organisation to []models.OrganisationSummary
+	util.SetPaginationHeaders(ctx.Request, ctx.Header, pagination)
 	orgSummaries := make([]models.OrganisationSummary, len(orgs))
-	for i, org := range orgs {
-		orgSummaries[i] = models.OrganisationSummary{
-			Uri:   org.Uri,
-			Label: org.Label,
-			Links: &models.Links{
-				Apis: &models.Link{
-					Href: fmt.Sprintf("/v1/apis?organisation=%s", org.Uri),
-				},
-			},
-		}
+	for i := range orgs {
+		orgSummaries[i] = util.ToOrganisationSummary(&orgs[i])
 	}
 	return orgSummaries, nil
 }
 
+// RetrieveOrganisation handles GET /organisations/*uri
+func (c *APIsAPIController) RetrieveOrganisation(ctx *gin.Context, params *models.OrganisationParams) (*models.OrganisationDetail, error) {
+	return c.Service.RetrieveOrganisation(ctx.Request.Context(), params.OrganisationURI())
+}
+
+// UpdateOrganisation handles PUT /organisations/*uri
+func (c *APIsAPIController) UpdateOrganisation(ctx *gin.Context, body *models.OrganisationUpdateInput) (*models.OrganisationDetail, error) {
+	return c.Service.UpdateOrganisation(ctx.Request.Context(), body)
+}
+
+// DeleteOrganisation handles DELETE /organisations/*uri
+func (c *APIsAPIController) DeleteOrganisation(ctx *gin.Context, params *models.OrganisationParams) error {
+	return c.Service.DeleteOrganisation(ctx.Request.Context(), params.OrganisationURI())
+}
+
+// MergeOrganisations handles POST /organisations/_merge
+func (c *APIsAPIController) MergeOrganisations(ctx *gin.Context, body *models.OrganisationMergeInput) (*models.OrganisationDetail, error) {
+	return c.Service.MergeOrganisations(ctx.Request.Context(), body)
+}
+
 // CreateOrganisation handles POST /organisations
 func (c *APIsAPIController) CreateOrganisation(ctx *gin.Context, body *models.Organisation) (*models.Organisation, error) {
 	created, err := c.Service.CreateOrganisation(ctx.Request.Context(), body)
@@ -137,12 +308,22 @@ func (c *APIsAPIController) CreateOrganisation(ctx *gin.Context, body *models.Or
 
 // GetPostman handles GET /apis/:id/postman
 func (c *APIsAPIController) GetPostman(ctx *gin.Context, params *models.ApiParams) error {
-	art, err := c.Service.GetArtifact(ctx.Request.Context(), params.Id, "postman")
+	return c.downloadArtifact(ctx, params.Id, "postman", "Postman artifact not found")
+}
+
+// GetBruno handles GET /apis/:id/bruno
+func (c *APIsAPIController) GetBruno(ctx *gin.Context, params *models.ApiParams) error {
+	return c.downloadArtifact(ctx, params.Id, "bruno", "Bruno artifact not found")
+}
+
+// GetArazzo handles GET /apis/:id/arazzo
+func (c *APIsAPIController) GetArazzo(ctx *gin.Context, params *models.ApiArazzoParams) error {
+	art, err := c.Service.GetArazzoDocument(ctx.Request.Context(), params.Id, params.Format)
 	if err != nil {
 		return err
 	}
 	if art == nil {
-		return problem.NewNotFound(params.Id, "Postman artifact not found")
+		return problem.NewNotFound(params.Id, "Arazzo artifact not found")
 	}
 	if art.ContentType != "" {
 		ctx.Header("Content-Type", art.ContentType)
@@ -150,8 +331,67 @@ func (c *APIsAPIController) GetPostman(ctx *gin.Context, params *models.ApiParam
 	if art.Filename != "" {
 		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", art.Filename))
 	}
-	ctx.Data(200, art.ContentType, art.Data)
-	return nil
+	if art.Version != "" {
+		ctx.Header("Arazzo-Version", art.Version)
+	}
+	return c.writeArtifact(ctx, art)
+}
+
+// GetArazzoMarkdown handles GET /apis/:id/arazzo/markdown
+func (c *APIsAPIController) GetArazzoMarkdown(ctx *gin.Context, params *models.ApiParams) error {
+	return c.downloadArtifact(ctx, params.Id, "arazzo_markdown", "Arazzo markdown not found")
+}
+
+// GetArazzoMermaid handles GET /apis/:id/arazzo/mermaid
+func (c *APIsAPIController) GetArazzoMermaid(ctx *gin.Context, params *models.ApiParams) error {
+	return c.downloadArtifact(ctx, params.Id, "arazzo_mermaid", "Arazzo mermaid not found")
+}
+
+// ListArtifacts handles GET /apis/:id/artifacts
+func (c *APIsAPIController) ListArtifacts(ctx *gin.Context, params *models.ApiParams) ([]models.ApiArtifact, error) {
+	arts, err := c.Service.ListApiArtifacts(ctx.Request.Context(), params.Id)
+	if err != nil {
+		return nil, err
+	}
+	if arts == nil {
+		return nil, problem.NewNotFound(params.Id, "Api not found")
+	}
+	return arts, nil
+}
+
+// GetArtifactByID handles GET /apis/:id/artifacts/:artifactId
+func (c *APIsAPIController) GetArtifactByID(ctx *gin.Context, params *models.ApiArtifactParams) error {
+	art, err := c.Service.GetArtifactByID(ctx.Request.Context(), params.Id, params.ArtifactId)
+	if err != nil {
+		return err
+	}
+	if art == nil {
+		return problem.NewNotFound(params.ArtifactId, "Artifact not found")
+	}
+	return c.sendArtifact(ctx, art)
+}
+
+// downloadArtifact stuurt het nieuwste artifact van kind als bijlage.
+func (c *APIsAPIController) downloadArtifact(ctx *gin.Context, apiID, kind, notFound string) error {
+	art, err := c.Service.GetArtifact(ctx.Request.Context(), apiID, kind)
+	if err != nil {
+		return err
+	}
+	if art == nil {
+		return problem.NewNotFound(apiID, notFound)
+	}
+	return c.sendArtifact(ctx, art)
+}
+
+// sendArtifact stuurt art als bijlage.
+func (c *APIsAPIController) sendArtifact(ctx *gin.Context, art *models.ApiArtifact) error {
+	if art.ContentType != "" {
+		ctx.Header("Content-Type", art.ContentType)
+	}
+	if art.Filename != "" {
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", art.Filename))
+	}
+	return c.writeArtifact(ctx, art)
 }
 
 func (c *APIsAPIController) GetOas(ctx *gin.Context, params *models.ApiOasParams) error {
@@ -178,7 +418,21 @@ func (c *APIsAPIController) GetOas(ctx *gin.Context, params *models.ApiOasParams
 	if art.Source != "" {
 		ctx.Header("OAS-Source", art.Source)
 	}
-	ctx.Data(200, art.ContentType, art.Data)
+	return c.writeArtifact(ctx, art)
+}
+
+// writeArtifact beantwoordt een conditionele GET op basis van de metadata en
+// laadt pas daarna de inhoud. Een artifact wijzigt nooit; een nieuwe versie
+// krijgt een nieuw id.
+func (c *APIsAPIController) writeArtifact(ctx *gin.Context, art *models.ApiArtifact) error {
+	if httpcache.Check(ctx, `"`+art.ID+`"`, art.CreatedAt) {
+		return nil
+	}
+	data, err := c.Service.ArtifactData(ctx.Request.Context(), art)
+	if err != nil {
+		return err
+	}
+	ctx.Data(200, art.ContentType, data)
 	return nil
 }
 
@@ -6,9 +6,11 @@ import (
 	"net/http/httptest"
 	"strconv"
 	"testing"
+	"time"
 
 	problem "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/problem"
 	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/models"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/testutil"
 	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/services"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -22,19 +24,19 @@ type stubRepo struct {
 	lintResFunc  func(ctx context.Context, apiID string) ([]models.LintResult, error)
 	listLint     func(ctx context.Context) ([]models.LintResult, error)
 	findOasFunc  func(ctx context.Context, oasUrl string) (*models.Api, error)
-	getOrgs      func(ctx context.Context) ([]models.Organisation, int, error)
+	getOrgs      func(ctx context.Context, page, perPage int) ([]models.Organisation, models.Pagination, error)
 	findOrg      func(ctx context.Context, uri string) (*models.Organisation, error)
 	saveOrg      func(org *models.Organisation) error
 	getOasArt    func(ctx context.Context, apiID, version, format string) (*models.ApiArtifact, error)
 	filterCounts func(ctx context.Context, p *models.ApiFiltersParams) (*models.ApiFilterCounts, error)
 }
 
-func (s *stubRepo) GetApis(ctx context.Context, page, perPage int, p *models.ApiFiltersParams) ([]models.Api, models.Pagination, error) {
-	return s.listFunc(ctx, page, perPage, p)
+func (s *stubRepo) GetApis(ctx context.Context, page models.PageRequest, p *models.ApiFiltersParams, order models.ApiSort, expand models.ApiExpand) ([]models.Api, models.Pagination, error) {
+	return s.listFunc(ctx, page.Page, page.PerPage, p)
 }
-func (s *stubRepo) SearchApis(ctx context.Context, page, perPage int, organisation *string, query string) ([]models.Api, models.Pagination, error) {
+func (s *stubRepo) SearchApis(ctx context.Context, page models.PageRequest, organisation *string, query string, order models.ApiSort, expand models.ApiExpand) ([]models.Api, models.Pagination, error) {
 	if s.searchFunc != nil {
-		return s.searchFunc(ctx, page, perPage, organisation, query)
+		return s.searchFunc(ctx, page.Page, page.PerPage, organisation, query)
 	}
 	return []models.Api{}, models.Pagination{}, nil
 }
@@ -44,14 +46,23 @@ func (s *stubRepo) GetApiByID(ctx context.Context, id string) (*models.Api, erro
 	}
 	return nil, nil
 }
+func (s *stubRepo) GetApiExpanded(ctx context.Context, id string, expand models.ApiExpand) (*models.Api, error) {
+	api, err := s.GetApiByID(ctx, id)
+	if err != nil || api == nil || expand.LintResults == "" {
+		return api, err
+	}
+	api.LintResults, err = s.GetLintResults(ctx, id)
+	return api, err
+}
 func (s *stubRepo) GetLintResults(ctx context.Context, apiID string) ([]models.LintResult, error) {
 	return s.lintResFunc(ctx, apiID)
 }
-func (s *stubRepo) ListLintResults(ctx context.Context) ([]models.LintResult, error) {
+func (s *stubRepo) ListLintResults(ctx context.Context, page models.PageRequest, filter models.LintResultFilter) ([]models.LintResult, models.Pagination, error) {
 	if s.listLint != nil {
-		return s.listLint(ctx)
+		results, err := s.listLint(ctx)
+		return results, models.Pagination{}, err
 	}
-	return []models.LintResult{}, nil
+	return []models.LintResult{}, models.Pagination{}, nil
 }
 func (s *stubRepo) FindByOasUrl(ctx context.Context, oasUrl string) (*models.Api, error) {
 	return s.findOasFunc(ctx, oasUrl)
@@ -65,8 +76,8 @@ func (s *stubRepo) SaveOrganisatie(org *models.Organisation) error {
 	}
 	return nil
 }
-func (s *stubRepo) GetOrganisations(ctx context.Context) ([]models.Organisation, int, error) {
-	return s.getOrgs(ctx)
+func (s *stubRepo) GetOrganisations(ctx context.Context, page models.PageRequest) ([]models.Organisation, models.Pagination, error) {
+	return s.getOrgs(ctx, page.Page, page.PerPage)
 }
 
 // unused
@@ -91,6 +102,9 @@ func (s *stubRepo) HasArtifactOfKind(ctx context.Context, apiID, kind string) (b
 func (s *stubRepo) GetArtifact(ctx context.Context, apiID, kind string) (*models.ApiArtifact, error) {
 	return nil, nil
 }
+func (s *stubRepo) GetArtifactData(ctx context.Context, id string) ([]byte, error) {
+	return nil, nil
+}
 func (s *stubRepo) DeleteArtifactsByKind(ctx context.Context, apiID, kind string, keep []string) error {
 	return nil
 }
@@ -100,9 +114,76 @@ func (s *stubRepo) GetApiFilterCounts(ctx context.Context, p *models.ApiFiltersP
 	}
 	return &models.ApiFilterCounts{}, nil
 }
+func (s *stubRepo) SaveAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	return nil
+}
+func (s *stubRepo) ListAuditEntries(ctx context.Context, page, perPage int, filter models.AuditFilter) ([]models.AuditEntry, models.Pagination, error) {
+	return nil, models.Pagination{}, nil
+}
+func (s *stubRepo) DeleteApi(ctx context.Context, id string) error {
+	return nil
+}
+func (s *stubRepo) GetDeletedApiByID(ctx context.Context, id string) (*models.Api, error) {
+	return nil, nil
+}
+func (s *stubRepo) RestoreApi(ctx context.Context, id string) error {
+	return nil
+}
+func (s *stubRepo) PurgeDeletedApis(ctx context.Context, deletedBefore time.Time) ([]string, error) {
+	return nil, nil
+}
+func (s *stubRepo) ListApisByOrganisation(ctx context.Context, uri string) ([]models.Api, error) {
+	return nil, nil
+}
+func (s *stubRepo) CountApisByOrganisation(ctx context.Context, uri string) (int, error) {
+	return 0, nil
+}
+func (s *stubRepo) DeleteOrganisation(ctx context.Context, uri string) error { return nil }
+func (s *stubRepo) MergeOrganisations(ctx context.Context, duplicateURI, canonicalURI string) ([]string, error) {
+	return nil, nil
+}
+func (s *stubRepo) BumpApiRevisionsByOrganisation(ctx context.Context, uri string) error {
+	return nil
+}
+func (s *stubRepo) FindPendingTransfer(ctx context.Context, apiID string) (*models.ApiTransfer, error) {
+	return nil, nil
+}
+func (s *stubRepo) SaveTransfer(ctx context.Context, transfer *models.ApiTransfer) error {
+	return nil
+}
+func (s *stubRepo) CompleteTransfer(ctx context.Context, transfer *models.ApiTransfer, revision int64) error {
+	return nil
+}
+func (s *stubRepo) ListApisByReviewStatus(ctx context.Context, status string, page, perPage int) ([]models.Api, models.Pagination, error) {
+	return nil, models.Pagination{}, nil
+}
+func (s *stubRepo) ListArtifacts(ctx context.Context, apiID string) ([]models.ApiArtifact, error) {
+	return nil, nil
+}
+func (s *stubRepo) GetArtifactByID(ctx context.Context, apiID, id string) (*models.ApiArtifact, error) {
+	return nil, nil
+}
+func (s *stubRepo) GetLatestLintResult(ctx context.Context, apiID string) (*models.LintResult, error) {
+	return nil, nil
+}
+func (s *stubRepo) GetArazzoArtifact(ctx context.Context, apiID, format string) (*models.ApiArtifact, error) {
+	return nil, nil
+}
+func (s *stubRepo) SaveApiChange(ctx context.Context, change *models.ApiChange) error {
+	return nil
+}
+func (s *stubRepo) ListApiChanges(ctx context.Context, afterSeq int64, limit int, settledBefore time.Time) ([]models.ApiChange, bool, error) {
+	return nil, false, nil
+}
+func (s *stubRepo) SaveReplacement(ctx context.Context, successor *models.Api, predecessorID string, link func(predecessor *models.Api)) (*models.Api, error) {
+	return nil, nil
+}
 
 func TestGetOas_Handler(t *testing.T) {
 	repo := &stubRepo{
+		retrFunc: func(ctx context.Context, id string) (*models.Api, error) {
+			return &models.Api{Id: id}, nil
+		},
 		getOasArt: func(ctx context.Context, apiID, version, format string) (*models.ApiArtifact, error) {
 			assert.Equal(t, "api-1", apiID)
 			assert.Equal(t, "3.1", version)
@@ -142,6 +223,9 @@ func TestGetOas_Handler(t *testing.T) {
 func TestGetOas_AllowsPatchVersion(t *testing.T) {
 	var capturedVersion, capturedFormat string
 	repo := &stubRepo{
+		retrFunc: func(ctx context.Context, id string) (*models.Api, error) {
+			return &models.Api{Id: id}, nil
+		},
 		getOasArt: func(ctx context.Context, apiID, version, format string) (*models.ApiArtifact, error) {
 			capturedVersion = version
 			capturedFormat = format
@@ -300,7 +384,7 @@ func TestRetrieveApi_Handler(t *testing.T) {
 	req1.Host = "host"
 	ctx1.Request = req1
 
-	resp1, err1 := ctrl1.RetrieveApi(ctx1, &models.ApiParams{Id: "id1"})
+	resp1, err1 := ctrl1.RetrieveApi(ctx1, &models.RetrieveApiParams{Id: "id1"})
 	assert.NoError(t, err1)
 	assert.NotNil(t, resp1)
 	assert.Equal(t, "id1", resp1.Id)
@@ -318,7 +402,7 @@ func TestRetrieveApi_Handler(t *testing.T) {
 	req2.Host = "host"
 	ctx2.Request = req2
 
-	resp2, err2 := ctrl2.RetrieveApi(ctx2, &models.ApiParams{Id: "missing"})
+	resp2, err2 := ctrl2.RetrieveApi(ctx2, &models.RetrieveApiParams{Id: "missing"})
 	assert.Error(t, err2)
 	assert.Nil(t, resp2)
 }
@@ -333,6 +417,7 @@ func TestCreateApiFromOas_Handler(t *testing.T) {
 
 	w := httptest.NewRecorder()
 	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/apis", nil)
 	body := &models.ApiPost{OasUrl: "u", OrganisationUri: "https://example.org"}
 	resp, err := ctrl.CreateApiFromOas(ctx, body)
 	assert.Nil(t, resp)
@@ -362,6 +447,7 @@ func TestUpdateApi_Handler(t *testing.T) {
 		retrFunc: func(ctx context.Context, id string) (*models.Api, error) {
 			return &models.Api{
 				Id:             id,
+				Revision:       3,
 				OrganisationID: &orgID,
 				Organisation:   &models.Organisation{Uri: orgID, Label: "ORG"},
 				Servers:        []models.Server{}, // altijd een lege slice, nooit nil
@@ -404,20 +490,24 @@ func TestUpdateApi_Handler(t *testing.T) {
 		_, _ = w.Write([]byte(spec))
 	}))
 	defer oasSrv.Close()
+	defer testutil.AllowLoopbackFetches()()
 
-	input2 := &models.UpdateApiInput{OasUrl: oasSrv.URL, OrganisationUri: "https://example.org"}
+	input2 := &models.UpdateApiInput{OasUrl: oasSrv.URL, OrganisationUri: "https://example.org", IfMatch: `"3"`}
 	resp2, err2 := ctrl2.UpdateApi(ctx2, input2)
 	assert.NoError(t, err2)
 	assert.NotNil(t, resp2)
+	assert.Equal(t, `"4"`, w.Header().Get("ETag"))
 }
 
 func TestListOrganisations_Handler(t *testing.T) {
 	repo := &stubRepo{
-		getOrgs: func(ctx context.Context) ([]models.Organisation, int, error) {
+		getOrgs: func(ctx context.Context, page, perPage int) ([]models.Organisation, models.Pagination, error) {
+			assert.Equal(t, 1, page)
+			assert.Equal(t, 10, perPage)
 			return []models.Organisation{
 				{Uri: "https://example.org/1", Label: "Org 1"},
 				{Uri: "https://example.org/2", Label: "Org 2"},
-			}, 2, nil
+			}, models.Pagination{CurrentPage: 1, RecordsPerPage: 10, TotalPages: 1, TotalRecords: 2}, nil
 		},
 	}
 	svc := services.NewAPIsAPIService(repo)
@@ -427,11 +517,13 @@ func TestListOrganisations_Handler(t *testing.T) {
 	ctx, _ := gin.CreateTestContext(w)
 	ctx.Request = httptest.NewRequest("GET", "/v1/organisations", nil)
 
-	result, err := ctrl.ListOrganisations(ctx)
+	result, err := ctrl.ListOrganisations(ctx, &models.ListOrganisationsParams{})
 	assert.NoError(t, err)
 	assert.Len(t, result, 2)
 	assert.Equal(t, "Org 1", result[0].Label)
+	assert.Equal(t, "/v1/organisations/https:%2F%2Fexample.org%2F1", result[0].Links.Self.Href)
 	assert.Equal(t, "2", w.Header().Get("Total-Count"))
+	assert.Contains(t, w.Header().Get("Link"), `rel="self"`)
 }
 
 func TestCreateOrganisation_Handler(t *testing.T) {
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/models"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ApiKeysController binds HTTP requests to the ApiKeyService
+type ApiKeysController struct {
+	Service *services.ApiKeyService
+}
+
+// NewApiKeysController creates a new controller
+func NewApiKeysController(s *services.ApiKeyService) *ApiKeysController {
+	return &ApiKeysController{Service: s}
+}
+
+// CreateApiKey handles POST /api-keys
+func (c *ApiKeysController) CreateApiKey(ctx *gin.Context, body *models.ApiKeyInput) (*models.ApiKeyCreated, error) {
+	return c.Service.CreateApiKey(ctx.Request.Context(), body)
+}
+
+// ListApiKeys handles GET /api-keys
+func (c *ApiKeysController) ListApiKeys(ctx *gin.Context) ([]models.ApiKey, error) {
+	return c.Service.ListApiKeys(ctx.Request.Context())
+}
+
+// RevokeApiKey handles DELETE /api-keys/:id
+func (c *ApiKeysController) RevokeApiKey(ctx *gin.Context, params *models.ApiKeyParams) error {
+	_, err := c.Service.RevokeApiKey(ctx.Request.Context(), params.Id)
+	return err
+}
@@ -4,15 +4,17 @@ import (
 	"encoding/json"
 	"net/http"
 	"strings"
+	"time"
 
 	problem "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/problem"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/httpcache"
 	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/models"
 	"github.com/gin-gonic/gin"
 )
 
 const ApisJsonLdMediaType = "application/ld+json"
 
-var apiDetailJsonLdContext = json.RawMessage(`{"dcat":"http://www.w3.org/ns/dcat#","dct":"http://purl.org/dc/terms/","vcard":"http://www.w3.org/2006/vcard/ns#","dcat:endpointDescription":{"@type":"@id"},"vcard:hasEmail":{"@type":"@id"},"vcard:hasURL":{"@type":"@id"},"dct:publisher":{"@type":"@id"}}`)
+var apiDetailJsonLdContext = json.RawMessage(`{"dcat":"http://www.w3.org/ns/dcat#","dct":"http://purl.org/dc/terms/","vcard":"http://www.w3.org/2006/vcard/ns#","dcat:endpointDescription":{"@type":"@id"},"vcard:hasEmail":{"@type":"@id"},"vcard:hasURL":{"@type":"@id"},"dct:publisher":{"@type":"@id"},"dct:replaces":{"@type":"@id"},"dct:isReplacedBy":{"@type":"@id"}}`)
 
 func oasConformsToURL(version string) string {
 	v := strings.TrimSpace(version)
@@ -35,13 +37,19 @@ func AcceptsJsonLd(accept string) bool {
 
 // RetrieveApiJsonLd handles GET /apis/:id with Accept: application/ld+json.
 func (c *APIsAPIController) RetrieveApiJsonLd(ctx *gin.Context, params *models.ApiParams) error {
-	api, err := c.Service.RetrieveApi(ctx.Request.Context(), params.Id)
+	// JSON-LD gebruikt alleen de organisatie; servers en lintresultaten
+	// hoeven niet geladen te worden.
+	api, err := c.Service.RetrieveApi(ctx.Request.Context(), &models.RetrieveApiParams{Id: params.Id, Expand: models.ApiExpandOrganisation})
 	if err != nil {
 		return err
 	}
-	if api == nil {
+	// JSON-LD beschrijft alleen goedgekeurde APIs.
+	if api == nil || api.Review != nil {
 		return problem.NewNotFound(params.Id, "Api not found")
 	}
+	if httpcache.Check(ctx, models.RepresentationETag(api.Revision, ApisJsonLdMediaType), time.Time{}) {
+		return nil
+	}
 
 	contact := models.ContactJsonLd{FN: api.Contact.Name}
 	if api.Contact.Email != "" {
@@ -62,6 +70,12 @@ func (c *APIsAPIController) RetrieveApiJsonLd(ctx *gin.Context, params *models.A
 		ContactPoint:        contact,
 		Publisher:           api.Organisation.Uri,
 	}
+	if api.Links != nil && api.Links.Replaces != nil {
+		body.Replaces = api.Links.Replaces.Href
+	}
+	if api.Links != nil && api.Links.ReplacedBy != nil {
+		body.IsReplacedBy = api.Links.ReplacedBy.Href
+	}
 	data, err := json.Marshal(body)
 	if err != nil {
 		return err
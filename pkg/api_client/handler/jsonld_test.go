@@ -105,3 +105,41 @@ func TestRetrieveApiJsonLd_NotFound(t *testing.T) {
 		assert.Contains(t, apiErr.Errors[0].Detail, "Api not found")
 	}
 }
+
+func TestRetrieveApiJsonLd_ReplacementRelations(t *testing.T) {
+	predecessor := "api-oud"
+	successor := "api-nieuw"
+	repo := &stubRepo{
+		retrFunc: func(ctx context.Context, id string) (*models.Api, error) {
+			return &models.Api{
+				Id:           id,
+				Title:        "Tussenversie",
+				ReplacesID:   &predecessor,
+				ReplacedByID: &successor,
+				Organisation: &models.Organisation{Uri: "https://example.com/orgs/1", Label: "Org 1"},
+			}, nil
+		},
+		lintResFunc: func(ctx context.Context, apiID string) ([]models.LintResult, error) {
+			return nil, nil
+		},
+	}
+	ctrl := NewAPIsAPIController(services.NewAPIsAPIService(repo))
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("GET", "/v1/apis/api-midden", nil)
+
+	require.NoError(t, ctrl.RetrieveApiJsonLd(ctx, &models.ApiParams{Id: "api-midden"}))
+	var body models.ApiDetailJsonLd
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "/v1/apis/api-oud", body.Replaces)
+	assert.Equal(t, "/v1/apis/api-nieuw", body.IsReplacedBy)
+	assert.Contains(t, string(body.Context), `"dct:isReplacedBy":{"@type":"@id"}`)
+
+	detail, err := ctrl.RetrieveApi(ctx, &models.RetrieveApiParams{Id: "api-midden"})
+	require.NoError(t, err)
+	require.NotNil(t, detail.Links)
+	assert.Nil(t, detail.Links.Self)
+	assert.Equal(t, "/v1/apis/api-oud", detail.Links.Replaces.Href)
+	assert.Equal(t, "/v1/apis/api-nieuw", detail.Links.ReplacedBy.Href)
+}
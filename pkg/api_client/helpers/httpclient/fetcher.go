@@ -0,0 +1,366 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// FetchPolicy beperkt wat de Fetcher mag ophalen. Adressen in AllowNets zijn
+// altijd toegestaan; daarna worden DenyNets en alle niet-publieke adressen
+// (loopback, privé, link-local, multicast, ...) geweigerd.
+type FetchPolicy struct {
+	AllowedSchemes []string
+	MaxBytes       int64
+	MaxRedirects   int
+	Timeout        time.Duration
+	AllowNets      []*net.IPNet
+	DenyNets       []*net.IPNet
+}
+
+const (
+	defaultFetchMaxBytes     = 10 << 20
+	defaultFetchMaxRedirects = 5
+	defaultFetchTimeout      = 30 * time.Second
+)
+
+var (
+	// ErrSchemeNotAllowed wordt teruggegeven voor URL's met een niet-toegestaan schema.
+	ErrSchemeNotAllowed = errors.New("URL-schema is niet toegestaan")
+	// ErrAddressNotAllowed wordt teruggegeven wanneer de host naar een geblokkeerd adres verwijst.
+	ErrAddressNotAllowed = errors.New("adres is niet toegestaan")
+	// ErrTooManyRedirects wordt teruggegeven wanneer het redirect-limiet is overschreden.
+	ErrTooManyRedirects = errors.New("te veel redirects")
+	// ErrResponseTooLarge wordt teruggegeven wanneer het document groter is dan toegestaan.
+	ErrResponseTooLarge = errors.New("document is te groot")
+)
+
+// nonPublicNets vult de checks uit net.IP aan met gereserveerde ranges.
+var nonPublicNets = mustParseCIDRs(
+	"0.0.0.0/8",
+	"100.64.0.0/10",
+	"192.0.0.0/24",
+	"192.0.2.0/24",
+	"198.18.0.0/15",
+	"198.51.100.0/24",
+	"203.0.113.0/24",
+	"240.0.0.0/4",
+	"64:ff9b::/96",
+	"2001:db8::/32",
+)
+
+// DefaultFetchPolicy staat alleen http(s) naar publieke adressen toe.
+func DefaultFetchPolicy() FetchPolicy {
+	return FetchPolicy{
+		AllowedSchemes: []string{"https", "http"},
+		MaxBytes:       defaultFetchMaxBytes,
+		MaxRedirects:   defaultFetchMaxRedirects,
+		Timeout:        defaultFetchTimeout,
+	}
+}
+
+// FetchPolicyFromEnv leest de policy uit OAS_FETCH_* omgevingsvariabelen.
+func FetchPolicyFromEnv() (FetchPolicy, error) {
+	p := DefaultFetchPolicy()
+	if raw := strings.TrimSpace(os.Getenv("OAS_FETCH_ALLOWED_SCHEMES")); raw != "" {
+		p.AllowedSchemes = splitList(raw)
+	}
+	if raw := strings.TrimSpace(os.Getenv("OAS_FETCH_MAX_BYTES")); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n <= 0 {
+			return p, fmt.Errorf("ongeldige OAS_FETCH_MAX_BYTES: %q", raw)
+		}
+		p.MaxBytes = n
+	}
+	if raw := strings.TrimSpace(os.Getenv("OAS_FETCH_MAX_REDIRECTS")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return p, fmt.Errorf("ongeldige OAS_FETCH_MAX_REDIRECTS: %q", raw)
+		}
+		p.MaxRedirects = n
+	}
+	if raw := strings.TrimSpace(os.Getenv("OAS_FETCH_TIMEOUT")); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d <= 0 {
+			return p, fmt.Errorf("ongeldige OAS_FETCH_TIMEOUT: %q", raw)
+		}
+		p.Timeout = d
+	}
+	var err error
+	if p.AllowNets, err = parseCIDRs(os.Getenv("OAS_FETCH_ALLOW_CIDRS")); err != nil {
+		return p, fmt.Errorf("ongeldige OAS_FETCH_ALLOW_CIDRS: %w", err)
+	}
+	if p.DenyNets, err = parseCIDRs(os.Getenv("OAS_FETCH_DENY_CIDRS")); err != nil {
+		return p, fmt.Errorf("ongeldige OAS_FETCH_DENY_CIDRS: %w", err)
+	}
+	return p, nil
+}
+
+// Fetcher haalt door gebruikers opgegeven documenten op met een SSRF-veilige
+// client: het IP-adres wordt bij het verbinden gecontroleerd (dus ook na
+// DNS-resolutie en redirects), de omvang is begrensd en proxies uit de
+// omgeving worden genegeerd.
+type Fetcher struct {
+	policy FetchPolicy
+	client *http.Client
+}
+
+// FetchResponse bevat de volledig gelezen response.
+type FetchResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// NewFetcher maakt een Fetcher; lege velden krijgen de standaardwaarden.
+func NewFetcher(policy FetchPolicy) *Fetcher {
+	def := DefaultFetchPolicy()
+	if len(policy.AllowedSchemes) == 0 {
+		policy.AllowedSchemes = def.AllowedSchemes
+	}
+	if policy.MaxBytes <= 0 {
+		policy.MaxBytes = def.MaxBytes
+	}
+	if policy.Timeout <= 0 {
+		policy.Timeout = def.Timeout
+	}
+	if policy.MaxRedirects < 0 {
+		policy.MaxRedirects = 0
+	}
+
+	f := &Fetcher{policy: policy}
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			return f.checkIP(net.ParseIP(host))
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	f.client = &http.Client{
+		Timeout:   policy.Timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > f.policy.MaxRedirects {
+				return fmt.Errorf("%w (max %d)", ErrTooManyRedirects, f.policy.MaxRedirects)
+			}
+			return f.checkScheme(req.URL)
+		},
+	}
+	return f
+}
+
+// Policy geeft de gebruikte policy terug.
+func (f *Fetcher) Policy() FetchPolicy {
+	return f.policy
+}
+
+// CheckURL valideert schema en de adressen waar de host naar verwijst. Gebruik
+// dit voordat een URL aan een andere dienst (bv. de tools API) wordt doorgegeven.
+func (f *Fetcher) CheckURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return fmt.Errorf("ongeldige URL: %w", err)
+	}
+	if err := f.checkScheme(u); err != nil {
+		return err
+	}
+	host := u.Hostname()
+	if host == "" {
+		return fmt.Errorf("ongeldige URL: host ontbreekt")
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return f.checkIP(ip)
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("kan host %s niet resolven: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if err := f.checkIP(addr.IP); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get haalt raw op binnen de grenzen van de policy.
+func (f *Fetcher) Get(ctx context.Context, raw string, header http.Header) (*FetchResponse, error) {
+	resp, err := f.do(ctx, raw, header)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := f.readBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	return &FetchResponse{StatusCode: resp.StatusCode, Header: resp.Header, Body: body}, nil
+}
+
+// RemoteURLHandler kan gebruikt worden om remote $refs via deze Fetcher op te halen.
+func (f *Fetcher) RemoteURLHandler(ctx context.Context) func(string) (*http.Response, error) {
+	return func(raw string) (*http.Response, error) {
+		resp, err := f.do(ctx, raw, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, err := f.readBody(resp)
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		resp.ContentLength = int64(len(body))
+		return resp, nil
+	}
+}
+
+func (f *Fetcher) do(ctx context.Context, raw string, header http.Header) (*http.Response, error) {
+	if err := f.CheckURL(ctx, raw); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSpace(raw), nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, values := range header {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+	return f.client.Do(req)
+}
+
+func (f *Fetcher) readBody(resp *http.Response) ([]byte, error) {
+	if resp.ContentLength > f.policy.MaxBytes {
+		return nil, fmt.Errorf("%w: %d bytes (max %d)", ErrResponseTooLarge, resp.ContentLength, f.policy.MaxBytes)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.policy.MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > f.policy.MaxBytes {
+		return nil, fmt.Errorf("%w (max %d bytes)", ErrResponseTooLarge, f.policy.MaxBytes)
+	}
+	return body, nil
+}
+
+func (f *Fetcher) checkScheme(u *url.URL) error {
+	for _, scheme := range f.policy.AllowedSchemes {
+		if strings.EqualFold(u.Scheme, scheme) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q", ErrSchemeNotAllowed, u.Scheme)
+}
+
+func (f *Fetcher) checkIP(ip net.IP) error {
+	if ip == nil {
+		return fmt.Errorf("%w: onbekend adres", ErrAddressNotAllowed)
+	}
+	if containsIP(f.policy.AllowNets, ip) {
+		return nil
+	}
+	if containsIP(f.policy.DenyNets, ip) || !isPublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrAddressNotAllowed, ip)
+	}
+	return nil
+}
+
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	return !containsIP(nonPublicNets, ip)
+}
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseCIDRs(raw string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, item := range splitList(raw) {
+		_, n, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+func mustParseCIDRs(items ...string) []*net.IPNet {
+	nets, err := parseCIDRs(strings.Join(items, ","))
+	if err != nil {
+		panic(err)
+	}
+	return nets
+}
+
+func splitList(raw string) []string {
+	var out []string
+	for _, item := range strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+var (
+	defaultFetcherMu sync.Mutex
+	defaultFetcher   *Fetcher
+)
+
+// DefaultFetcher geeft de gedeelde Fetcher terug. Zolang SetDefaultFetcher
+// niet is aangeroepen, is dat een Fetcher met DefaultFetchPolicy.
+func DefaultFetcher() *Fetcher {
+	defaultFetcherMu.Lock()
+	defer defaultFetcherMu.Unlock()
+	if defaultFetcher == nil {
+		defaultFetcher = NewFetcher(DefaultFetchPolicy())
+	}
+	return defaultFetcher
+}
+
+// SetDefaultFetcher vervangt de gedeelde Fetcher en geeft een functie terug
+// die de vorige herstelt. main zet hem bij het opstarten met de policy uit de
+// omgeving; tests gebruiken de restore.
+func SetDefaultFetcher(f *Fetcher) (restore func()) {
+	defaultFetcherMu.Lock()
+	defer defaultFetcherMu.Unlock()
+	prev := defaultFetcher
+	defaultFetcher = f
+	return func() {
+		defaultFetcherMu.Lock()
+		defer defaultFetcherMu.Unlock()
+		defaultFetcher = prev
+	}
+}
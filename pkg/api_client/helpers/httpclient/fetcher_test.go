@@ -0,0 +1,130 @@
+package httpclient_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/httpclient"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loopbackPolicy() httpclient.FetchPolicy {
+	policy := httpclient.DefaultFetchPolicy()
+	_, loopback, _ := net.ParseCIDR("127.0.0.0/8")
+	policy.AllowNets = []*net.IPNet{loopback}
+	return policy
+}
+
+func TestFetcher_BlocksNonPublicAddresses(t *testing.T) {
+	f := httpclient.NewFetcher(httpclient.DefaultFetchPolicy())
+	for _, raw := range []string{
+		"http://127.0.0.1/openapi.json",
+		"http://10.0.0.1/openapi.json",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/openapi.json",
+		"http://100.64.0.1/openapi.json",
+	} {
+		err := f.CheckURL(context.Background(), raw)
+		assert.ErrorIs(t, err, httpclient.ErrAddressNotAllowed, raw)
+	}
+}
+
+func TestFetcher_BlocksLoopbackAtConnectTime(t *testing.T) {
+	srv := testutil.NewTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	f := httpclient.NewFetcher(httpclient.DefaultFetchPolicy())
+	_, err := f.Get(context.Background(), srv.URL, nil)
+	assert.ErrorIs(t, err, httpclient.ErrAddressNotAllowed)
+}
+
+func TestFetcher_RejectsScheme(t *testing.T) {
+	f := httpclient.NewFetcher(httpclient.DefaultFetchPolicy())
+	for _, raw := range []string{"file:///etc/passwd", "gopher://example.org/", "ftp://example.org/spec.yaml"} {
+		err := f.CheckURL(context.Background(), raw)
+		assert.ErrorIs(t, err, httpclient.ErrSchemeNotAllowed, raw)
+	}
+}
+
+func TestFetcher_AllowsAllowlistedNetwork(t *testing.T) {
+	srv := testutil.NewTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "https://developer.overheid.nl", r.Header.Get("Origin"))
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	f := httpclient.NewFetcher(loopbackPolicy())
+	resp, err := f.Get(context.Background(), srv.URL, http.Header{"Origin": {"https://developer.overheid.nl"}})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "ok", string(resp.Body))
+}
+
+func TestFetcher_DenyListOverridesPublicAddress(t *testing.T) {
+	policy := httpclient.DefaultFetchPolicy()
+	_, denied, _ := net.ParseCIDR("93.184.0.0/16")
+	policy.DenyNets = []*net.IPNet{denied}
+	f := httpclient.NewFetcher(policy)
+
+	err := f.CheckURL(context.Background(), "https://93.184.216.34/openapi.json")
+	assert.ErrorIs(t, err, httpclient.ErrAddressNotAllowed)
+}
+
+func TestFetcher_EnforcesMaxBytes(t *testing.T) {
+	payload := strings.Repeat("a", 2048)
+	srv := testutil.NewTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chunked" {
+			w.(http.Flusher).Flush() // geen Content-Length
+		}
+		_, _ = w.Write([]byte(payload))
+	}))
+
+	policy := loopbackPolicy()
+	policy.MaxBytes = 1024
+	f := httpclient.NewFetcher(policy)
+
+	_, err := f.Get(context.Background(), srv.URL, nil)
+	assert.ErrorIs(t, err, httpclient.ErrResponseTooLarge)
+	_, err = f.Get(context.Background(), srv.URL+"/chunked", nil)
+	assert.ErrorIs(t, err, httpclient.ErrResponseTooLarge)
+}
+
+func TestFetcher_EnforcesRedirectLimit(t *testing.T) {
+	srv := testutil.NewTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/again", http.StatusFound)
+	}))
+
+	policy := loopbackPolicy()
+	policy.MaxRedirects = 2
+	f := httpclient.NewFetcher(policy)
+
+	_, err := f.Get(context.Background(), srv.URL, nil)
+	assert.True(t, errors.Is(err, httpclient.ErrTooManyRedirects), "got %v", err)
+}
+
+func TestFetchPolicyFromEnv(t *testing.T) {
+	t.Setenv("OAS_FETCH_ALLOWED_SCHEMES", "https")
+	t.Setenv("OAS_FETCH_MAX_BYTES", "4096")
+	t.Setenv("OAS_FETCH_MAX_REDIRECTS", "1")
+	t.Setenv("OAS_FETCH_TIMEOUT", "5s")
+	t.Setenv("OAS_FETCH_ALLOW_CIDRS", "10.1.0.0/16")
+	t.Setenv("OAS_FETCH_DENY_CIDRS", "203.0.113.0/24, 198.51.100.0/24")
+
+	policy, err := httpclient.FetchPolicyFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https"}, policy.AllowedSchemes)
+	assert.EqualValues(t, 4096, policy.MaxBytes)
+	assert.Equal(t, 1, policy.MaxRedirects)
+	assert.Len(t, policy.AllowNets, 1)
+	assert.Len(t, policy.DenyNets, 2)
+
+	t.Setenv("OAS_FETCH_MAX_BYTES", "veel")
+	_, err = httpclient.FetchPolicyFromEnv()
+	assert.Error(t, err)
+}
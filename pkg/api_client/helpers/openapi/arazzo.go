@@ -0,0 +1,135 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/tools"
+	"sigs.k8s.io/yaml"
+)
+
+// ArazzoResult is een opgehaald en gevalideerd Arazzo-document.
+type ArazzoResult struct {
+	URL     string // leeg als het document als body is meegestuurd
+	Raw     []byte // oorspronkelijke bytes zoals opgehaald
+	JSON    []byte // het document als JSON
+	Format  string // formaat van Raw: json of yaml
+	Version string // arazzo versiestring, bv. 1.0.1
+	Hash    string // sha256 van de JSON-weergave
+}
+
+// arazzoDocument bevat de velden die Arazzo 1.x verplicht stelt.
+type arazzoDocument struct {
+	Arazzo string `json:"arazzo"`
+	Info   struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	} `json:"info"`
+	SourceDescriptions []struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"sourceDescriptions"`
+	Workflows []struct {
+		WorkflowID string            `json:"workflowId"`
+		Steps      []json.RawMessage `json:"steps"`
+	} `json:"workflows"`
+}
+
+// FetchParseValidateArazzo haalt het Arazzo-document uit input op (de body
+// gaat voor) en controleert het tegen de verplichte velden van Arazzo 1.x.
+func FetchParseValidateArazzo(ctx context.Context, input tools.ArazzoInput, opts FetchOpts) (*ArazzoResult, error) {
+	input.Normalize()
+	if input.IsEmpty() {
+		return nil, fmt.Errorf("Arazzo input ontbreekt")
+	}
+	res := &ArazzoResult{}
+	if input.ArazzoBody != "" {
+		res.Raw = []byte(input.ArazzoBody)
+	} else {
+		resp, err := opts.fetcher().Get(ctx, input.ArazzoUrl, nil)
+		if err != nil {
+			return nil, fmt.Errorf("kan Arazzo niet ophalen: %w", err)
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return nil, fmt.Errorf("kan Arazzo niet ophalen: status %d", resp.StatusCode)
+		}
+		res.URL = input.ArazzoUrl
+		res.Raw = resp.Body
+	}
+	if err := parseValidateAndHashArazzo(res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func parseValidateAndHashArazzo(res *ArazzoResult) error {
+	res.Format = "yaml"
+	if trimmed := bytes.TrimSpace(res.Raw); len(trimmed) > 0 && trimmed[0] == '{' {
+		res.Format = "json"
+	}
+	data, err := yaml.YAMLToJSON(res.Raw)
+	if err != nil {
+		return fmt.Errorf("invalid Arazzo (parse): %s", strings.TrimSpace(err.Error()))
+	}
+	var doc arazzoDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("invalid Arazzo (parse): %s", strings.TrimSpace(err.Error()))
+	}
+	if err := validateArazzo(doc); err != nil {
+		return fmt.Errorf("invalid Arazzo: %w", err)
+	}
+
+	// Compacte JSON van een map heeft gesorteerde sleutels en is daarmee een
+	// stabiele basis voor de hash.
+	var normalized any
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return fmt.Errorf("invalid Arazzo (parse): %s", strings.TrimSpace(err.Error()))
+	}
+	canonical, err := json.Marshal(normalized)
+	if err != nil {
+		return fmt.Errorf("kan Arazzo niet normaliseren: %w", err)
+	}
+	sum := sha256.Sum256(canonical)
+	res.JSON, err = json.MarshalIndent(normalized, "", "  ")
+	if err != nil {
+		return fmt.Errorf("kan Arazzo niet normaliseren: %w", err)
+	}
+	res.Version = strings.TrimSpace(doc.Arazzo)
+	res.Hash = hex.EncodeToString(sum[:])
+	return nil
+}
+
+func validateArazzo(doc arazzoDocument) error {
+	version := strings.TrimSpace(doc.Arazzo)
+	switch {
+	case version == "":
+		return fmt.Errorf("ontbrekende arazzo versie")
+	case !strings.HasPrefix(version, "1."):
+		return fmt.Errorf("unsupported Arazzo version %s (alleen 1.x wordt ondersteund)", version)
+	case strings.TrimSpace(doc.Info.Title) == "" || strings.TrimSpace(doc.Info.Version) == "":
+		return fmt.Errorf("info.title en info.version zijn verplicht")
+	case len(doc.SourceDescriptions) == 0:
+		return fmt.Errorf("sourceDescriptions mag niet leeg zijn")
+	case len(doc.Workflows) == 0:
+		return fmt.Errorf("workflows mag niet leeg zijn")
+	}
+	for i, src := range doc.SourceDescriptions {
+		if strings.TrimSpace(src.Name) == "" || strings.TrimSpace(src.URL) == "" {
+			return fmt.Errorf("sourceDescriptions[%d] mist name of url", i)
+		}
+	}
+	for i, wf := range doc.Workflows {
+		if strings.TrimSpace(wf.WorkflowID) == "" {
+			return fmt.Errorf("workflows[%d] mist workflowId", i)
+		}
+		if len(wf.Steps) == 0 {
+			return fmt.Errorf("workflow %s heeft geen steps", wf.WorkflowID)
+		}
+	}
+	return nil
+}
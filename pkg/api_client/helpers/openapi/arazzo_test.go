@@ -0,0 +1,85 @@
+package openapi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	toolslint "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/tools"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/testutil"
+)
+
+const testArazzoYAML = `arazzo: 1.0.1
+info:
+  title: Bestellen
+  version: 1.0.0
+sourceDescriptions:
+  - name: petstore
+    url: https://example.org/openapi.json
+    type: openapi
+workflows:
+  - workflowId: bestel
+    steps:
+      - stepId: zoek
+        operationId: findPets
+`
+
+func TestFetchParseValidateArazzo_Body(t *testing.T) {
+	res, err := FetchParseValidateArazzo(context.Background(), toolslint.ArazzoInput{ArazzoBody: testArazzoYAML}, FetchOpts{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if res.Format != "yaml" || res.Version != "1.0.1" || res.URL != "" {
+		t.Fatalf("unexpected result: format=%s version=%s url=%s", res.Format, res.Version, res.URL)
+	}
+	if !strings.Contains(string(res.JSON), `"workflowId": "bestel"`) {
+		t.Fatalf("expected JSON rendering, got %s", res.JSON)
+	}
+
+	asJSON, err := FetchParseValidateArazzo(context.Background(), toolslint.ArazzoInput{ArazzoBody: string(res.JSON)}, FetchOpts{})
+	if err != nil {
+		t.Fatalf("expected no error for JSON, got %v", err)
+	}
+	if asJSON.Format != "json" {
+		t.Fatalf("expected json format, got %s", asJSON.Format)
+	}
+	if asJSON.Hash != res.Hash {
+		t.Fatalf("expected equal hash for YAML and JSON, got %s and %s", res.Hash, asJSON.Hash)
+	}
+}
+
+func TestFetchParseValidateArazzo_URL(t *testing.T) {
+	server := testutil.NewTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(testArazzoYAML))
+	}))
+
+	res, err := FetchParseValidateArazzo(context.Background(), toolslint.ArazzoInput{ArazzoUrl: server.URL}, FetchOpts{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if res.URL != server.URL || res.Hash == "" {
+		t.Fatalf("unexpected result: url=%s hash=%s", res.URL, res.Hash)
+	}
+}
+
+func TestFetchParseValidateArazzo_Invalid(t *testing.T) {
+	tests := map[string]struct {
+		body string
+		want string
+	}{
+		"version":   {strings.Replace(testArazzoYAML, "arazzo: 1.0.1", "arazzo: 2.0.0", 1), "unsupported Arazzo version"},
+		"openapi":   {"openapi: 3.0.0\ninfo:\n  title: x\n  version: 1.0.0\n", "ontbrekende arazzo versie"},
+		"workflows": {testArazzoYAML[:strings.Index(testArazzoYAML, "workflows:")], "workflows mag niet leeg zijn"},
+		"steps":     {strings.Replace(testArazzoYAML, "    steps:\n      - stepId: zoek\n        operationId: findPets\n", "", 1), "heeft geen steps"},
+		"parse":     {"arazzo: [", "invalid Arazzo (parse)"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := FetchParseValidateArazzo(context.Background(), toolslint.ArazzoInput{ArazzoBody: tc.body}, FetchOpts{})
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
@@ -4,14 +4,15 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
 
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/httpclient"
 	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/tools"
 	"github.com/pb33f/libopenapi"
 	"github.com/pb33f/libopenapi/datamodel"
@@ -19,8 +20,15 @@ import (
 )
 
 type FetchOpts struct {
-	Origin     string       // bv. "https://developer.overheid.nl"
-	HTTPClient *http.Client // optioneel
+	Origin  string              // bv. "https://developer.overheid.nl"
+	Fetcher *httpclient.Fetcher // optioneel, standaard httpclient.DefaultFetcher()
+}
+
+func (o FetchOpts) fetcher() *httpclient.Fetcher {
+	if o.Fetcher != nil {
+		return o.Fetcher
+	}
+	return httpclient.DefaultFetcher()
 }
 
 type OASResult struct {
@@ -36,11 +44,26 @@ type OASResult struct {
 
 var versionPrefixPattern = regexp.MustCompile(`^(\d+)\.(\d+)`)
 
+var (
+	// ErrOASUnreachable betekent dat de OAS niet kon worden opgehaald.
+	ErrOASUnreachable = errors.New("kan OAS niet ophalen")
+	// ErrOASInvalid betekent dat de OAS geen geldig OpenAPI 3.0- of 3.1-document is.
+	ErrOASInvalid = errors.New("invalid OAS")
+)
+
 func FetchParseValidateAndHash(ctx context.Context, input tools.OASInput, opts FetchOpts) (*OASResult, error) {
 	input.Normalize()
 	if input.IsEmpty() {
 		return nil, fmt.Errorf("OAS input ontbreekt")
 	}
+	fetcher := opts.fetcher()
+	// De URL wordt ook door de tools API opgehaald; controleer hem daarom
+	// vooraf en val bij een geweigerde URL niet terug op een directe fetch.
+	if input.OasUrl != "" {
+		if err := fetcher.CheckURL(ctx, input.OasUrl); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrOASUnreachable, err)
+		}
+	}
 
 	var (
 		raw         []byte
@@ -60,7 +83,7 @@ func FetchParseValidateAndHash(ctx context.Context, input tools.OASInput, opts F
 		fromBundle = true
 	}
 
-	res, err := parseValidateAndHash(raw, contentType)
+	res, err := parseValidateAndHash(ctx, fetcher, raw, contentType)
 	if err == nil {
 		return res, nil
 	}
@@ -70,7 +93,7 @@ func FetchParseValidateAndHash(ctx context.Context, input tools.OASInput, opts F
 		if retryErr != nil {
 			return nil, retryErr
 		}
-		return parseValidateAndHash(raw, contentType)
+		return parseValidateAndHash(ctx, fetcher, raw, contentType)
 	}
 	return nil, err
 }
@@ -85,18 +108,21 @@ func shouldRetryRawFetchAfterBundleParseError(err error) bool {
 		strings.Contains(msg, "contains itself")
 }
 
-func parseValidateAndHash(raw []byte, contentType string) (*OASResult, error) {
-	// 2) libopenapi config voor (remote) refs
+func parseValidateAndHash(ctx context.Context, fetcher *httpclient.Fetcher, raw []byte, contentType string) (*OASResult, error) {
+	// 2) libopenapi config voor remote refs; die lopen via de fetcher zodat
+	//    dezelfde SSRF- en groottegrenzen gelden. Bestandsreferenties zijn
+	//    uitgeschakeld omdat de input van buiten komt.
 	cfg := datamodel.DocumentConfiguration{
 		AllowRemoteReferences: true,
-		AllowFileReferences:   true,
+		AllowFileReferences:   false,
+		RemoteURLHandler:      fetcher.RemoteURLHandler(ctx),
 	}
 
 	// 3) Parse document met config
 	doc, docErr := libopenapi.NewDocumentWithConfiguration(raw, &cfg)
 
 	if docErr != nil {
-		return nil, fmt.Errorf("invalid OAS (parse): %s", strings.TrimSpace(docErr.Error()))
+		return nil, fmt.Errorf("%w (parse): %s", ErrOASInvalid, strings.TrimSpace(docErr.Error()))
 	}
 
 	// 4) Build high-level v3 model (lost refs op)
@@ -105,7 +131,7 @@ func parseValidateAndHash(raw []byte, contentType string) (*OASResult, error) {
 		// libopenapi geeft een error; bundel kort samen
 		var parts []string
 		parts = append(parts, buildErrs.Error())
-		return nil, fmt.Errorf("invalid OAS (model): %s", strings.Join(parts, "; "))
+		return nil, fmt.Errorf("%w (model): %s", ErrOASInvalid, strings.Join(parts, "; "))
 	}
 
 	// 6) Hash over de genormaliseerde weergave
@@ -120,11 +146,11 @@ func parseValidateAndHash(raw []byte, contentType string) (*OASResult, error) {
 	spec := model.Model
 	version := strings.TrimSpace(spec.Version)
 	if version == "" {
-		return nil, fmt.Errorf("invalid OAS: ontbrekende openapi versie")
+		return nil, fmt.Errorf("%w: ontbrekende openapi versie", ErrOASInvalid)
 	}
 	match := versionPrefixPattern.FindStringSubmatch(version)
 	if len(match) != 3 {
-		return nil, fmt.Errorf("invalid OAS: ongeldige openapi versie %s", version)
+		return nil, fmt.Errorf("%w: ongeldige openapi versie %s", ErrOASInvalid, version)
 	}
 	major, _ := strconv.Atoi(match[1])
 	minor, _ := strconv.Atoi(match[2])
@@ -135,7 +161,7 @@ func parseValidateAndHash(raw []byte, contentType string) (*OASResult, error) {
 		}
 	}
 	if major != 3 || minor > 1 {
-		return nil, fmt.Errorf("invalid OAS: unsupported OpenAPI version %s (alleen 3.0 en 3.1 worden ondersteund)", version)
+		return nil, fmt.Errorf("%w: unsupported OpenAPI version %s (alleen 3.0 en 3.1 worden ondersteund)", ErrOASInvalid, version)
 	}
 	return &OASResult{
 		Spec:        &spec,
@@ -166,12 +192,9 @@ func fetchRawOAS(ctx context.Context, input tools.OASInput, opts FetchOpts) ([]b
 	}
 	oasURL := strings.TrimSpace(input.OasUrl)
 	if oasURL == "" {
-		return nil, "", fmt.Errorf("geen oasUrl opgegeven")
-	}
-	cli := opts.HTTPClient
-	if cli == nil {
-		cli = http.DefaultClient
+		return nil, "", fmt.Errorf("%w: geen oasUrl opgegeven", ErrOASUnreachable)
 	}
+	fetcher := opts.fetcher()
 	type attempt struct {
 		origin string
 	}
@@ -180,27 +203,17 @@ func fetchRawOAS(ctx context.Context, input tools.OASInput, opts FetchOpts) ([]b
 		attempts = append(attempts, attempt{origin: ""})
 	}
 	for i, att := range attempts {
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, oasURL, nil)
-		if err != nil {
-			return nil, "", err
-		}
+		header := http.Header{}
 		if att.origin != "" {
-			req.Header.Set("Origin", att.origin)
+			header.Set("Origin", att.origin)
 		}
-		resp, err := cli.Do(req)
+		resp, err := fetcher.Get(ctx, oasURL, header)
 		if err != nil {
-			return nil, "", fmt.Errorf("kan OAS niet ophalen: %w", err)
-		}
-		body, readErr := io.ReadAll(resp.Body)
-		closeErr := resp.Body.Close()
-		if readErr != nil {
-			return nil, "", fmt.Errorf("kan OAS niet lezen: %w", readErr)
-		}
-		if closeErr != nil {
-			return nil, "", fmt.Errorf("kan OAS response body niet sluiten: %w", closeErr)
+			return nil, "", fmt.Errorf("%w: %w", ErrOASUnreachable, err)
 		}
+		body := resp.Body
 		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-			return nil, "", fmt.Errorf("kan OAS niet ophalen: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+			return nil, "", fmt.Errorf("%w: status %d: %s", ErrOASUnreachable, resp.StatusCode, strings.TrimSpace(string(body)))
 		}
 		contentType := resp.Header.Get("Content-Type")
 		originLabel := "zonder Origin"
@@ -222,5 +235,5 @@ func fetchRawOAS(ctx context.Context, input tools.OASInput, opts FetchOpts) ([]b
 		}
 		return body, contentType, nil
 	}
-	return nil, "", fmt.Errorf("kan OAS niet ophalen: geen geldige response")
+	return nil, "", fmt.Errorf("%w: geen geldige response", ErrOASUnreachable)
 }
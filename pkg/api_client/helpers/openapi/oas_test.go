@@ -2,7 +2,11 @@ package openapi
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	toolslint "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/tools"
@@ -169,3 +173,48 @@ components:
 		t.Fatalf("expected fallback to raw spec, got title %q", got)
 	}
 }
+
+func TestFetchParseValidateAndHash_RejectsBlockedURL(t *testing.T) {
+	input := toolslint.OASInput{OasUrl: "http://169.254.169.254/latest/meta-data"}
+	_, err := FetchParseValidateAndHash(context.Background(), input, FetchOpts{})
+	if err == nil {
+		t.Fatalf("expected blocked address to be rejected")
+	}
+	if !errors.Is(err, ErrOASUnreachable) {
+		t.Fatalf("expected ErrOASUnreachable, got %v", err)
+	}
+}
+
+func TestFetchParseValidateAndHash_UnsupportedVersionIsInvalid(t *testing.T) {
+	input := toolslint.OASInput{OasBody: `{"openapi": "2.0.0", "info": {"title": "Oud", "version": "1.0.0"}, "paths": {}}`}
+	_, err := FetchParseValidateAndHash(context.Background(), input, FetchOpts{})
+	if !errors.Is(err, ErrOASInvalid) {
+		t.Fatalf("expected ErrOASInvalid, got %v", err)
+	}
+	if errors.Is(err, ErrOASUnreachable) {
+		t.Fatalf("invalid document reported as unreachable: %v", err)
+	}
+}
+
+func TestFetchParseValidateAndHash_DisallowsFileReferences(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "secret.json")
+	if err := os.WriteFile(secret, []byte(`{"type": "object", "description": "geheim"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	spec := `{
+	  "openapi": "3.0.1",
+	  "info": {"title": "Refs", "version": "1.0.0"},
+	  "paths": {},
+	  "components": {"schemas": {"Secret": {"$ref": "` + secret + `"}}}
+	}`
+
+	input := toolslint.OASInput{OasBody: spec}
+	res, err := FetchParseValidateAndHash(context.Background(), input, FetchOpts{})
+	if err == nil {
+		rendered, _ := res.Spec.RenderJSON("  ")
+		t.Fatalf("expected file reference to be rejected, got %s", rendered)
+	}
+	if strings.Contains(err.Error(), "geheim") {
+		t.Fatalf("file contents leaked into error: %v", err)
+	}
+}
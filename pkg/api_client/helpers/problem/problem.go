@@ -17,10 +17,21 @@ type APIError struct {
 	Title  string        `json:"title"`
 	Status int           `json:"status"`
 	Errors []ErrorDetail `json:"errors,omitempty"`
+
+	cause error
 }
 
 func (e APIError) Error() string { return e.Title }
 
+// Unwrap geeft de oorzaak, zodat errors.Is en errors.As erdoorheen kijken.
+func (e APIError) Unwrap() error { return e.cause }
+
+// WithCause legt de oorspronkelijke fout vast zonder de response te wijzigen.
+func (e APIError) WithCause(err error) APIError {
+	e.cause = err
+	return e
+}
+
 func NewBadRequest(oasUri, detail string, params ...InvalidParam) APIError {
 	return APIError{
 		Title:  "Request validation failed",
@@ -45,6 +56,14 @@ func NewInternalServerError(detail string) APIError {
 	}
 }
 
+func NewUnauthorized(detail string) APIError {
+	return APIError{
+		Title:  "Unauthorized",
+		Status: 401,
+		Errors: toErrorDetails(nil, detail, "header", "Authorization", "unauthorized"),
+	}
+}
+
 func NewForbidden(oasUri, detail string) APIError {
 	return APIError{
 		Title:  "Forbidden",
@@ -53,6 +72,46 @@ func NewForbidden(oasUri, detail string) APIError {
 	}
 }
 
+func NewConflict(oasUri, detail string) APIError {
+	return APIError{
+		Title:  "Conflict",
+		Status: 409,
+		Errors: toErrorDetails(nil, detail, "path", oasUri, "conflict"),
+	}
+}
+
+func NewPreconditionFailed(location, detail string) APIError {
+	return APIError{
+		Title:  "Precondition Failed",
+		Status: 412,
+		Errors: toErrorDetails(nil, detail, "header", location, "precondition_failed"),
+	}
+}
+
+func NewPreconditionRequired(location, detail string) APIError {
+	return APIError{
+		Title:  "Precondition Required",
+		Status: 428,
+		Errors: toErrorDetails(nil, detail, "header", location, "precondition_required"),
+	}
+}
+
+func NewUnprocessableEntity(location, detail string) APIError {
+	return APIError{
+		Title:  "Unprocessable Entity",
+		Status: 422,
+		Errors: toErrorDetails(nil, detail, "header", location, "unprocessable_entity"),
+	}
+}
+
+func NewTooManyRequests(detail string) APIError {
+	return APIError{
+		Title:  "Too Many Requests",
+		Status: 429,
+		Errors: toErrorDetails(nil, detail, "", "", "too_many_requests"),
+	}
+}
+
 func toErrorDetails(params []InvalidParam, fallbackDetail, fallbackIn, fallbackLocation, fallbackCode string) []ErrorDetail {
 	if len(params) == 0 {
 		if fallbackDetail == "" {
@@ -2,6 +2,8 @@ package util
 
 import (
 	"fmt"
+	"net/url"
+	"slices"
 	"strings"
 	"time"
 
@@ -25,41 +27,165 @@ func ToApiSummary(api *models.Api) models.ApiSummary {
 			Deprecated: api.Deprecated,
 			Status:     api.LifecycleStatus(time.Now()),
 		},
-		AdrScore: api.AdrScore,
-		Organisation: models.OrganisationSummary{
-			Uri:   api.Organisation.Uri,
-			Label: api.Organisation.Label,
-			Links: &models.Links{
-				Apis: &models.Link{Href: fmt.Sprintf("/v1/apis?organisation=%s", api.Organisation.Uri)},
-			},
-		},
+		AdrScore:     api.AdrScore,
+		Organisation: apiOrganisation(api),
 
-		Links: &models.Links{
+		Links: relationLinks(api, &models.Links{
 			Self: &models.Link{Href: fmt.Sprintf("/v1/apis/%s", api.Id)},
+		}),
+		Review:          toReview(api),
+		CreatedAt:       api.CreatedAt,
+		UpdatedAt:       api.UpdatedAt,
+		LastOASChangeAt: api.LastOASChangeAt,
+		Revision:        api.Revision,
+	}
+}
+
+// toReview geeft de beoordeling van een nog niet goedgekeurde registratie.
+func toReview(api *models.Api) *models.Review {
+	if api.IsApproved() {
+		return nil
+	}
+	return &models.Review{
+		Status:     api.ReviewStatus,
+		Reason:     api.ReviewReason,
+		ReviewedBy: api.ReviewedBy,
+		ReviewedAt: api.ReviewedAt,
+	}
+}
+
+// relationLinks voegt links naar de voorganger en opvolger van api toe.
+func relationLinks(api *models.Api, links *models.Links) *models.Links {
+	if api.ReplacesID != nil && *api.ReplacesID != "" {
+		if links == nil {
+			links = &models.Links{}
+		}
+		links.Replaces = &models.Link{Href: fmt.Sprintf("/v1/apis/%s", *api.ReplacesID)}
+	}
+	if api.ReplacedByID != nil && *api.ReplacedByID != "" {
+		if links == nil {
+			links = &models.Links{}
+		}
+		links.ReplacedBy = &models.Link{Href: fmt.Sprintf("/v1/apis/%s", *api.ReplacedByID)}
+	}
+	return links
+}
+
+// apiOrganisation geeft de organisatie van api; is die niet geladen, dan
+// alleen de uri en links.
+func apiOrganisation(api *models.Api) models.OrganisationSummary {
+	if api.Organisation != nil {
+		return ToOrganisationSummary(api.Organisation)
+	}
+	if api.OrganisationID != nil {
+		return ToOrganisationSummary(&models.Organisation{Uri: *api.OrganisationID})
+	}
+	return models.OrganisationSummary{}
+}
+
+func ToOrganisationSummary(org *models.Organisation) models.OrganisationSummary {
+	return models.OrganisationSummary{
+		Uri:   org.Uri,
+		Label: org.Label,
+		Links: &models.Links{
+			Self: &models.Link{Href: "/v1/organisations/" + url.PathEscape(org.Uri)},
+			Apis: &models.Link{Href: fmt.Sprintf("/v1/apis?organisation=%s", org.Uri)},
 		},
 	}
 }
 
 func ToApiDetail(api *models.Api) *models.ApiDetail {
-	// Map servers to only url and description
-	servers := make([]models.ServerInfo, 0, len(api.Servers))
-	for _, srv := range api.Servers {
-		servers = append(servers, models.ServerInfo{
+	detail := &models.ApiDetail{
+		ApiSummary: ToApiSummary(api),
+		OasVersion: strings.TrimSpace(api.OAS.Version),
+	}
+	detail.DocsUrl = api.DocsUrl
+	detail.Servers = toServerInfos(api.Servers)
+	detail.Auth = toAuth(api)
+	// Detail toont de links naar voorganger, opvolger en downloads
+	detail.Links = artifactLinks(api, relationLinks(api, nil))
+	return detail
+}
+
+// artifactLinks voegt een downloadlink toe voor elk artifact in api.Artifacts.
+func artifactLinks(api *models.Api, links *models.Links) *models.Links {
+	if len(api.Artifacts) == 0 {
+		return links
+	}
+	if links == nil {
+		links = &models.Links{}
+	}
+	base := fmt.Sprintf("/v1/apis/%s", api.Id)
+	for _, art := range api.Artifacts {
+		switch art.Kind {
+		case "oas":
+			name := art.Version + "." + art.Format
+			if slices.ContainsFunc(links.Oas, func(l models.Link) bool { return l.Name == name }) {
+				continue
+			}
+			links.Oas = append(links.Oas, models.Link{Href: base + "/oas/" + name, Name: name, Type: art.ContentType})
+		case "postman":
+			links.Postman = &models.Link{Href: base + "/postman", Type: art.ContentType}
+		case "bruno":
+			links.Bruno = &models.Link{Href: base + "/bruno", Type: art.ContentType}
+		case "arazzo":
+			if slices.ContainsFunc(links.Arazzo, func(l models.Link) bool { return l.Name == art.Format }) {
+				continue
+			}
+			links.Arazzo = append(links.Arazzo, models.Link{Href: base + "/arazzo?format=" + art.Format, Name: art.Format, Type: art.ContentType})
+		case "arazzo_markdown":
+			links.ArazzoMarkdown = &models.Link{Href: base + "/arazzo/markdown", Type: art.ContentType}
+		case "arazzo_mermaid":
+			links.ArazzoMermaid = &models.Link{Href: base + "/arazzo/mermaid", Type: art.ContentType}
+		}
+	}
+	slices.SortFunc(links.Oas, func(a, b models.Link) int { return strings.Compare(a.Name, b.Name) })
+	slices.SortFunc(links.Arazzo, func(a, b models.Link) int { return strings.Compare(a.Name, b.Name) })
+	return links
+}
+
+// ToApiView geeft de lijstweergave van api in de gevraagde vorm. Velden die
+// alleen in de detailweergave staan komen mee als ze met fields zijn gekozen.
+func ToApiView(api *models.Api, view models.ApiView) models.ApiSummary {
+	summary := ToApiSummary(api)
+	if view.Fields != nil {
+		summary.DocsUrl = api.DocsUrl
+		summary.Auth = toAuth(api)
+	}
+	ExpandApiSummary(&summary, api, view)
+	return summary
+}
+
+// ExpandApiSummary sluit de met expand gevraagde gegevens van api in en
+// beperkt summary tot de gekozen velden.
+func ExpandApiSummary(summary *models.ApiSummary, api *models.Api, view models.ApiView) {
+	if view.Expand.Servers {
+		summary.Servers = toServerInfos(api.Servers)
+	}
+	if view.Expand.LintResults != "" {
+		summary.LintResults = api.LintResults
+	}
+	if view.Expand.Artifacts {
+		summary.Artifacts = api.Artifacts
+	}
+	summary.SelectFields(view.Fields)
+}
+
+// toServerInfos geeft alleen de url en omschrijving van servers.
+func toServerInfos(servers []models.Server) []models.ServerInfo {
+	infos := make([]models.ServerInfo, 0, len(servers))
+	for _, srv := range servers {
+		infos = append(infos, models.ServerInfo{
 			Url:         srv.Uri,
 			Description: srv.Description,
 		})
 	}
+	return infos
+}
 
-	detail := &models.ApiDetail{
-		ApiSummary: ToApiSummary(api),
-		DocsUrl:    api.DocsUrl,
-		Servers:    servers,
-		OasVersion: strings.TrimSpace(api.OAS.Version),
-	}
+func toAuth(api *models.Api) []string {
 	if auth := strings.TrimSpace(api.Auth); auth != "" {
-		detail.Auth = []string{auth}
+		return []string{auth}
 	}
-	// Remove Links from detail
-	detail.Links = nil
-	return detail
+	return nil
 }
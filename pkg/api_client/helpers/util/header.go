@@ -12,6 +12,13 @@ import (
 
 func SetPaginationHeaders(r *http.Request, setHeader func(key, val string), p models.Pagination) {
 	setHeader("Total-Count", strconv.Itoa(p.TotalRecords))
+	if p.Cursor {
+		setHeader("Per-Page", strconv.Itoa(p.RecordsPerPage))
+		if links := buildCursorLinkHeader(r, p); links != "" {
+			setHeader("Link", links)
+		}
+		return
+	}
 	setHeader("Total-Pages", strconv.Itoa(p.TotalPages))
 	setHeader("Per-Page", strconv.Itoa(p.RecordsPerPage))
 	setHeader("Current-Page", strconv.Itoa(p.CurrentPage))
@@ -27,19 +34,11 @@ func buildLinkHeader(r *http.Request, p models.Pagination) string {
 		return ""
 	}
 
-	scheme := "http"
-	if r.TLS != nil || strings.EqualFold(r.Header.Get("Forwarded-Proto"), "https") {
-		scheme = "https"
-	}
-	host := r.Host
-
 	makeURL := func(page int) string {
-		u := *r.URL
-		q := cloneValues(u.Query())
+		q := cloneValues(r.URL.Query())
 		q.Set("page", strconv.Itoa(page))
 		q.Set("perPage", strconv.Itoa(p.RecordsPerPage))
-		u.RawQuery = q.Encode()
-		return fmt.Sprintf("%s://%s%s", scheme, host, u.RequestURI())
+		return requestURL(r, q)
 	}
 
 	var parts []string
@@ -56,6 +55,39 @@ func buildLinkHeader(r *http.Request, p models.Pagination) string {
 	return strings.Join(parts, ", ")
 }
 
+// buildCursorLinkHeader geeft first en, als er meer is, next voor
+// cursor-paginering. Bij een cursor horen geen paginanummers, dus prev en last
+// ontbreken.
+func buildCursorLinkHeader(r *http.Request, p models.Pagination) string {
+	makeURL := func(cursor string) string {
+		q := cloneValues(r.URL.Query())
+		q.Del("page")
+		q.Del("perPage")
+		q.Del("cursor")
+		if cursor != "" {
+			q.Set("cursor", cursor)
+		}
+		q.Set("limit", strconv.Itoa(p.RecordsPerPage))
+		return requestURL(r, q)
+	}
+
+	parts := []string{fmt.Sprintf("<%s>; rel=\"first\"", makeURL(""))}
+	if p.NextCursor != "" {
+		parts = append(parts, fmt.Sprintf("<%s>; rel=\"next\"", makeURL(p.NextCursor)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func requestURL(r *http.Request, q url.Values) string {
+	scheme := "http"
+	if r.TLS != nil || strings.EqualFold(r.Header.Get("Forwarded-Proto"), "https") {
+		scheme = "https"
+	}
+	u := *r.URL
+	u.RawQuery = q.Encode()
+	return fmt.Sprintf("%s://%s%s", scheme, r.Host, u.RequestURI())
+}
+
 func cloneValues(v url.Values) url.Values {
 	out := make(url.Values, len(v))
 	for k, arr := range v {
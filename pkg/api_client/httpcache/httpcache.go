@@ -0,0 +1,136 @@
+package httpcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultListsCacheControl     = "no-cache"
+	defaultApisCacheControl      = "no-cache"
+	defaultArtifactsCacheControl = "max-age=300"
+
+	cacheControlKey = "httpcache.cacheControl"
+)
+
+// Config bepaalt de Cache-Control header per groep leesendpoints. Een lege
+// waarde laat de header weg.
+type Config struct {
+	// Lists geldt voor GET /apis.
+	Lists string
+	// Apis geldt voor GET /apis/{id}.
+	Apis string
+	// Artifacts geldt voor gedownloade documenten zoals OAS en Postman.
+	Artifacts string
+}
+
+// DefaultConfig laat clients lijsten en APIs altijd revalideren; artifacts
+// mogen vijf minuten uit de cache komen.
+func DefaultConfig() Config {
+	return Config{
+		Lists:     defaultListsCacheControl,
+		Apis:      defaultApisCacheControl,
+		Artifacts: defaultArtifactsCacheControl,
+	}
+}
+
+// ConfigFromEnv leest CACHE_CONTROL_LISTS, CACHE_CONTROL_APIS en
+// CACHE_CONTROL_ARTIFACTS (bijv. "public, max-age=60"). De waarde "off"
+// laat de header weg.
+func ConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig()
+	for env, target := range map[string]*string{
+		"CACHE_CONTROL_LISTS":     &cfg.Lists,
+		"CACHE_CONTROL_APIS":      &cfg.Apis,
+		"CACHE_CONTROL_ARTIFACTS": &cfg.Artifacts,
+	} {
+		raw := strings.TrimSpace(os.Getenv(env))
+		switch {
+		case raw == "":
+		case strings.EqualFold(raw, "off"):
+			*target = ""
+		case strings.ContainsAny(raw, "\r\n"):
+			return cfg, fmt.Errorf("ongeldige %s: %q", env, raw)
+		default:
+			*target = raw
+		}
+	}
+	return cfg, nil
+}
+
+// CacheControl legt de Cache-Control waarde voor de route vast. Check zet de
+// header pas op een geslaagde response, zodat fouten niet gecachet worden.
+func CacheControl(value string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(cacheControlKey, value)
+	}
+}
+
+// WeakETag maakt een zwakke ETag op basis van parts, voor representaties die
+// geen eigen revisie hebben, zoals een pagina uit een lijst.
+func WeakETag(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return `W/"` + hex.EncodeToString(h.Sum(nil))[:32] + `"`
+}
+
+// Check zet ETag, Last-Modified en Cache-Control op de response en beantwoordt
+// een conditionele GET. Geeft true als de client de actuele versie al heeft;
+// de handler is dan klaar en er is een 304 zonder body verstuurd.
+// If-None-Match gaat voor If-Modified-Since (RFC 9110, 13.2.2).
+func Check(c *gin.Context, etag string, lastModified time.Time) bool {
+	if value := c.GetString(cacheControlKey); value != "" {
+		c.Header("Cache-Control", value)
+	}
+	if etag != "" {
+		c.Header("ETag", etag)
+	}
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		return false
+	}
+	if !fresh(c.Request.Header, etag, lastModified) {
+		return false
+	}
+	c.AbortWithStatus(http.StatusNotModified)
+	return true
+}
+
+func fresh(header http.Header, etag string, lastModified time.Time) bool {
+	if inm := header.Get("If-None-Match"); inm != "" {
+		return etag != "" && matchesWeak(inm, etag)
+	}
+	ims := header.Get("If-Modified-Since")
+	if ims == "" || lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// matchesWeak vergelijkt een If-None-Match lijst zwak met etag.
+func matchesWeak(list, etag string) bool {
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
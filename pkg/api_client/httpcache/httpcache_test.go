@@ -0,0 +1,96 @@
+package httpcache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRouter(etag string, lastModified time.Time) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/doc", CacheControl("max-age=60"), func(c *gin.Context) {
+		if Check(c, etag, lastModified) {
+			return
+		}
+		c.String(http.StatusOK, "inhoud")
+	})
+	return r
+}
+
+func get(r http.Handler, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/doc", nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCheck_IfNoneMatch(t *testing.T) {
+	r := newTestRouter(`"abc"`, time.Time{})
+
+	w := get(r, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"abc"`, w.Header().Get("ETag"))
+	assert.Equal(t, "max-age=60", w.Header().Get("Cache-Control"))
+	assert.Empty(t, w.Header().Get("Last-Modified"))
+
+	w = get(r, map[string]string{"If-None-Match": `"xyz", W/"abc"`})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, `"abc"`, w.Header().Get("ETag"))
+
+	w = get(r, map[string]string{"If-None-Match": `"xyz"`})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "inhoud", w.Body.String())
+}
+
+func TestCheck_IfModifiedSince(t *testing.T) {
+	modified := time.Date(2026, 10, 17, 12, 0, 0, 500, time.UTC)
+	r := newTestRouter(`"abc"`, modified)
+
+	w := get(r, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Sat, 17 Oct 2026 12:00:00 GMT", w.Header().Get("Last-Modified"))
+
+	w = get(r, map[string]string{"If-Modified-Since": "Sat, 17 Oct 2026 12:00:00 GMT"})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	w = get(r, map[string]string{"If-Modified-Since": "Sat, 17 Oct 2026 11:59:59 GMT"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = get(r, map[string]string{
+		"If-None-Match":     `"ander"`,
+		"If-Modified-Since": "Sat, 17 Oct 2026 12:00:00 GMT",
+	})
+	assert.Equal(t, http.StatusOK, w.Code, "If-None-Match gaat voor If-Modified-Since")
+}
+
+func TestWeakETag(t *testing.T) {
+	a := WeakETag("1", "api-1:2")
+	assert.Regexp(t, `^W/"[0-9a-f]{32}"$`, a)
+	assert.Equal(t, a, WeakETag("1", "api-1:2"))
+	assert.NotEqual(t, a, WeakETag("1", "api-1:3"))
+	assert.NotEqual(t, WeakETag("ab", "c"), WeakETag("a", "bc"))
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("CACHE_CONTROL_LISTS", "public, max-age=60")
+	t.Setenv("CACHE_CONTROL_APIS", "off")
+	cfg, err := ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "public, max-age=60", cfg.Lists)
+	assert.Empty(t, cfg.Apis)
+	assert.Equal(t, defaultArtifactsCacheControl, cfg.Artifacts)
+
+	t.Setenv("CACHE_CONTROL_ARTIFACTS", "max-age=60\r\nX-Evil: 1")
+	_, err = ConfigFromEnv()
+	assert.Error(t, err)
+}
@@ -0,0 +1,284 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/auth"
+	problem "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/problem"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/models"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/repositories"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// Header is de request-header met de sleutel van de client.
+	Header = "Idempotency-Key"
+	// ReplayedHeader staat op een response die uit de opslag komt.
+	ReplayedHeader = "Idempotent-Replayed"
+
+	defaultTTL   = 24 * time.Hour
+	maxKeyLength = 255
+	// inFlightTimeout is de tijd waarna een sleutel zonder response als
+	// verlaten geldt, bijvoorbeeld na een herstart tijdens het request.
+	inFlightTimeout = 2 * time.Minute
+)
+
+// Config bepaalt hoe lang een sleutel en de bijbehorende response bewaard
+// blijven.
+type Config struct {
+	TTL time.Duration
+}
+
+// ConfigFromEnv leest IDEMPOTENCY_TTL (bijv. 24h).
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{TTL: defaultTTL}
+	if raw := strings.TrimSpace(os.Getenv("IDEMPOTENCY_TTL")); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < time.Minute {
+			return cfg, fmt.Errorf("ongeldige IDEMPOTENCY_TTL: %q", raw)
+		}
+		cfg.TTL = d
+	}
+	return cfg, nil
+}
+
+// Store bewaart sleutels en responses per aanroeper.
+type Store struct {
+	repo repositories.IdempotencyRepository
+	cfg  Config
+	now  func() time.Time
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+// New maakt een Store op basis van repo en cfg.
+func New(repo repositories.IdempotencyRepository, cfg Config) *Store {
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultTTL
+	}
+	return &Store{repo: repo, cfg: cfg, now: time.Now}
+}
+
+// Middleware maakt een request met een Idempotency-Key herhaalbaar. De eerste
+// geslaagde (2xx) response wordt bewaard en bij een identieke herhaling binnen
+// de TTL opnieuw verstuurd, zonder de handler uit te voeren. Een herhaling met
+// een andere body geeft een 422; een herhaling terwijl het eerste request nog
+// loopt een 409. Mislukte requests worden niet bewaard, zodat de client het
+// opnieuw kan proberen. Draai de middleware na de authenticatie: sleutels
+// gelden per aanroeper.
+func (s *Store) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimSpace(c.GetHeader(Header))
+		if key == "" {
+			return
+		}
+		if len(key) > maxKeyLength {
+			abort(c, problem.NewBadRequest(Header, fmt.Sprintf("%s mag maximaal %d tekens lang zijn", Header, maxKeyLength),
+				problem.InvalidParam{Name: Header, Reason: fmt.Sprintf("Maximaal %d tekens", maxKeyLength)}))
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			abort(c, problem.NewBadRequest("body", "kan body niet lezen: "+err.Error()))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		id := recordID(clientKey(c), c.Request.Method, c.Request.URL.Path, key)
+		fingerprint := requestFingerprint(c.Request.Method, c.Request.URL.Path, body)
+		s.sweep(ctx)
+
+		rec, err := s.repo.FindIdempotencyRecord(ctx, id)
+		if err != nil {
+			abort(c, problem.NewInternalServerError("kan Idempotency-Key niet controleren: "+err.Error()))
+			return
+		}
+		if rec != nil && s.stale(rec) {
+			if err := s.repo.DeleteIdempotencyRecord(ctx, id); err != nil {
+				abort(c, problem.NewInternalServerError("kan verlopen Idempotency-Key niet opruimen: "+err.Error()))
+				return
+			}
+			rec = nil
+		}
+		if rec == nil {
+			reserved, err := s.repo.ReserveIdempotencyRecord(ctx, &models.IdempotencyRecord{
+				ID:          id,
+				Fingerprint: fingerprint,
+				CreatedAt:   s.now().UTC(),
+			})
+			if err != nil {
+				abort(c, problem.NewInternalServerError("kan Idempotency-Key niet opslaan: "+err.Error()))
+				return
+			}
+			if reserved {
+				s.process(c, id)
+				return
+			}
+			// Een gelijktijdig request met dezelfde sleutel was net eerder.
+			if rec, err = s.repo.FindIdempotencyRecord(ctx, id); err != nil || rec == nil {
+				abort(c, problem.NewConflict(Header, "Een request met deze Idempotency-Key wordt nog verwerkt"))
+				return
+			}
+		}
+
+		if rec.Fingerprint != fingerprint {
+			abort(c, problem.NewUnprocessableEntity(Header, "Deze Idempotency-Key is al gebruikt voor een ander request"))
+			return
+		}
+		if !rec.Completed() {
+			abort(c, problem.NewConflict(Header, "Een request met deze Idempotency-Key wordt nog verwerkt"))
+			return
+		}
+		// Headers die de middleware ervoor al opnieuw heeft gezet, zoals CORS en
+		// rate limiting, gaan voor.
+		header := c.Writer.Header()
+		for name, values := range rec.Headers {
+			if _, ok := header[name]; !ok {
+				header[name] = values
+			}
+		}
+		c.Header(ReplayedHeader, "true")
+		c.Data(rec.Status, rec.ContentType, rec.Body)
+		c.Abort()
+	}
+}
+
+// process voert de handler uit en bewaart een geslaagde response. Bij een
+// fout of panic wordt de sleutel vrijgegeven.
+func (s *Store) process(c *gin.Context, id string) {
+	// De request-context kan na de response al zijn afgebroken.
+	ctx := context.WithoutCancel(c.Request.Context())
+	stored := false
+	defer func() {
+		if stored {
+			return
+		}
+		if err := s.repo.DeleteIdempotencyRecord(ctx, id); err != nil {
+			log.Printf("[idempotency] kan sleutel niet vrijgeven: %v", err)
+		}
+	}()
+
+	rec := &recorder{ResponseWriter: c.Writer}
+	c.Writer = rec
+	c.Next()
+
+	status := rec.Status()
+	if status < http.StatusOK || status >= http.StatusMultipleChoices {
+		return
+	}
+	if err := s.repo.CompleteIdempotencyRecord(ctx, &models.IdempotencyRecord{
+		ID:          id,
+		Status:      status,
+		ContentType: rec.Header().Get("Content-Type"),
+		Headers:     replayableHeaders(rec.Header()),
+		Body:        rec.body.Bytes(),
+	}); err != nil {
+		log.Printf("[idempotency] kan response niet opslaan: %v", err)
+		return
+	}
+	stored = true
+}
+
+// skippedHeaders horen bij één verzending van de response en worden bij een
+// herhaling niet teruggezet. Content-Type staat apart in het record.
+var skippedHeaders = map[string]bool{
+	"Content-Type":   true,
+	"Content-Length": true,
+	"Date":           true,
+	"Set-Cookie":     true,
+}
+
+// replayableHeaders geeft de headers van h die bij een herhaling terugkomen.
+func replayableHeaders(h http.Header) map[string][]string {
+	out := make(map[string][]string, len(h))
+	for name, values := range h {
+		if skippedHeaders[http.CanonicalHeaderKey(name)] {
+			continue
+		}
+		out[name] = append([]string(nil), values...)
+	}
+	return out
+}
+
+// stale meldt of rec verlopen is, of zonder response is blijven hangen.
+func (s *Store) stale(rec *models.IdempotencyRecord) bool {
+	age := s.now().Sub(rec.CreatedAt)
+	return age >= s.cfg.TTL || (!rec.Completed() && age >= inFlightTimeout)
+}
+
+// sweep ruimt verlopen sleutels op, hooguit eens per uur.
+func (s *Store) sweep(ctx context.Context) {
+	now := s.now()
+	s.mu.Lock()
+	if now.Sub(s.lastSweep) < time.Hour {
+		s.mu.Unlock()
+		return
+	}
+	s.lastSweep = now
+	s.mu.Unlock()
+	if err := s.repo.DeleteIdempotencyRecordsBefore(ctx, now.Add(-s.cfg.TTL).UTC()); err != nil {
+		log.Printf("[idempotency] opruimen mislukt: %v", err)
+	}
+}
+
+type recorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *recorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
+
+// clientKey identificeert de aanroeper: de API key of het token-subject, anders
+// het IP-adres.
+func clientKey(c *gin.Context) string {
+	if id := auth.PrincipalFromContext(c.Request.Context()).ID(); id != "" {
+		return "principal:" + id
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func recordID(client, method, path, key string) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{client, method, path, key}, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// requestFingerprint hasht methode, pad en body. Witruimte in een JSON-body
+// telt niet mee.
+func requestFingerprint(method, path string, body []byte) string {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, body); err == nil {
+		body = compact.Bytes()
+	}
+	h := sha256.New()
+	h.Write([]byte(method + "\n" + path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func abort(c *gin.Context, apiErr problem.APIError) {
+	c.Header("Content-Type", "application/problem+json")
+	c.AbortWithStatusJSON(apiErr.Status, apiErr)
+}
@@ -0,0 +1,82 @@
+package idempotency
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/models"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/repositories"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestRequestFingerprint_IgnoresJSONWhitespace(t *testing.T) {
+	a := requestFingerprint("POST", "/v1/apis", []byte(`{"oasUrl": "https://example.com/oas.json"}`))
+	b := requestFingerprint("POST", "/v1/apis", []byte("{\n  \"oasUrl\":\"https://example.com/oas.json\"\n}"))
+	c := requestFingerprint("POST", "/v1/apis", []byte(`{"oasUrl": "https://example.com/other.json"}`))
+	d := requestFingerprint("POST", "/v1/organisations", []byte(`{"oasUrl": "https://example.com/oas.json"}`))
+
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, c)
+	assert.NotEqual(t, a, d)
+}
+
+func TestStore_Stale(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	s := New(nil, Config{TTL: time.Hour})
+	s.now = func() time.Time { return now }
+
+	completed := &models.IdempotencyRecord{Status: 201, CreatedAt: now.Add(-30 * time.Minute)}
+	assert.False(t, s.stale(completed))
+	completed.CreatedAt = now.Add(-time.Hour)
+	assert.True(t, s.stale(completed), "expired after the TTL")
+
+	inFlight := &models.IdempotencyRecord{CreatedAt: now.Add(-time.Minute)}
+	assert.False(t, s.stale(inFlight))
+	inFlight.CreatedAt = now.Add(-inFlightTimeout)
+	assert.True(t, s.stale(inFlight), "abandoned without a response")
+}
+
+func TestMiddleware_ReplaysResponseHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.IdempotencyRecord{}))
+	store := New(repositories.NewIdempotencyRepository(db), Config{TTL: time.Hour})
+
+	calls := 0
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Header("API-Version", "2.0.0") })
+	r.POST("/v1/apis", store.Middleware(), func(c *gin.Context) {
+		calls++
+		c.Header("ETag", `"1"`)
+		c.Header("Location", "/v1/apis/api-1")
+		c.Header("API-Version", "verouderd")
+		c.JSON(http.StatusCreated, gin.H{"id": "api-1"})
+	})
+	post := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/apis", strings.NewReader(`{"oasUrl":"https://example.com/oas.json"}`))
+		req.Header.Set(Header, "sleutel-1")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	first := post()
+	require.Equal(t, http.StatusCreated, first.Code)
+	replay := post()
+	require.Equal(t, http.StatusCreated, replay.Code)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "true", replay.Header().Get(ReplayedHeader))
+	assert.Equal(t, `"1"`, replay.Header().Get("ETag"))
+	assert.Equal(t, "/v1/apis/api-1", replay.Header().Get("Location"))
+	assert.Equal(t, first.Header().Get("Content-Type"), replay.Header().Get("Content-Type"))
+	assert.Equal(t, "2.0.0", replay.Header().Get("API-Version"), "een header van de middleware ervoor gaat voor")
+	assert.JSONEq(t, first.Body.String(), replay.Body.String())
+}
@@ -3,22 +3,33 @@ package api_client_test
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	api_client "github.com/developer-overheid-nl/don-api-register/pkg/api_client"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/auth"
 	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/handler"
 	problem "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/problem"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/httpcache"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/idempotency"
 	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/models"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/ratelimit"
 	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/repositories"
 	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/services"
 	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/testutil"
@@ -111,7 +122,7 @@ type integrationEnv struct {
 	client  *http.Client
 }
 
-func newIntegrationEnv(t *testing.T) *integrationEnv {
+func newIntegrationEnv(t *testing.T, opts ...api_client.RouterOption) *integrationEnv {
 	t.Helper()
 
 	gin.SetMode(gin.TestMode)
@@ -130,12 +141,20 @@ func newIntegrationEnv(t *testing.T) *integrationEnv {
 		&models.LintMessage{},
 		&models.LintMessageInfo{},
 		&models.ApiArtifact{},
+		&models.ApiKey{},
+		&models.AuditEntry{},
+		&models.ApiTransfer{},
+		&models.IdempotencyRecord{},
+		&models.ApiChange{},
 	))
 
 	repo := repositories.NewApiRepository(db)
 	svc := services.NewAPIsAPIService(repo)
 	controller := handler.NewAPIsAPIController(svc)
-	router := api_client.NewRouter("test-version", controller)
+	keys := handler.NewApiKeysController(services.NewApiKeyService(repositories.NewApiKeyRepository(db)))
+	idem := idempotency.New(repositories.NewIdempotencyRepository(db), idempotency.Config{TTL: time.Hour})
+	opts = append([]api_client.RouterOption{api_client.WithApiKeys(keys), api_client.WithIdempotency(idem)}, opts...)
+	router := api_client.NewRouter("test-version", controller, opts...)
 
 	server := httptest.NewServer(router)
 	t.Cleanup(func() { server.Close() })
@@ -175,6 +194,11 @@ func (e *integrationEnv) doRequestWithHeaders(t *testing.T, method, path string,
 
 func (e *integrationEnv) doJSONRequest(t *testing.T, method, path string, payload any) *http.Response {
 	t.Helper()
+	return e.doJSONRequestWithHeaders(t, method, path, payload, nil)
+}
+
+func (e *integrationEnv) doJSONRequestWithHeaders(t *testing.T, method, path string, payload any, headers map[string]string) *http.Response {
+	t.Helper()
 
 	var buf bytes.Buffer
 	if payload != nil {
@@ -184,12 +208,30 @@ func (e *integrationEnv) doJSONRequest(t *testing.T, method, path string, payloa
 	req, err := http.NewRequest(method, e.server.URL+path, &buf)
 	require.NoError(t, err)
 	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
 
 	resp, err := e.client.Do(req)
 	require.NoError(t, err)
 	return resp
 }
 
+// ifMatch voegt de If-Match header met de huidige revisie van een API toe aan
+// headers.
+func (e *integrationEnv) ifMatch(t *testing.T, apiID string, headers map[string]string) map[string]string {
+	t.Helper()
+
+	api, err := e.repo.GetApiByID(context.Background(), apiID)
+	require.NoError(t, err)
+	require.NotNil(t, api)
+	out := map[string]string{"If-Match": models.RevisionETag(api.Revision)}
+	for key, value := range headers {
+		out[key] = value
+	}
+	return out
+}
+
 func decodeBody[T any](t *testing.T, resp *http.Response) T {
 	t.Helper()
 	defer func() {
@@ -443,11 +485,11 @@ func TestUpdateApi_LifecycleOnlyWithoutOAS(t *testing.T) {
 	require.NoError(t, env.repo.Save(api))
 
 	t.Run("set lifecycle fields without oas", func(t *testing.T) {
-		resp := env.doJSONRequest(t, http.MethodPut, "/v1/apis/"+apiID, map[string]string{
+		resp := env.doJSONRequestWithHeaders(t, http.MethodPut, "/v1/apis/"+apiID, map[string]string{
 			"organisationUri": org.Uri,
 			"sunset":          "2028-02-02",
 			"deprecated":      "2027-02-02",
-		})
+		}, env.ifMatch(t, apiID, nil))
 		require.Equal(t, http.StatusOK, resp.StatusCode)
 
 		summary := decodeBody[models.ApiSummary](t, resp)
@@ -462,10 +504,10 @@ func TestUpdateApi_LifecycleOnlyWithoutOAS(t *testing.T) {
 	})
 
 	t.Run("clear sunset without oas", func(t *testing.T) {
-		resp := env.doJSONRequest(t, http.MethodPut, "/v1/apis/"+apiID, map[string]any{
+		resp := env.doJSONRequestWithHeaders(t, http.MethodPut, "/v1/apis/"+apiID, map[string]any{
 			"organisationUri": org.Uri,
 			"sunset":          nil,
-		})
+		}, env.ifMatch(t, apiID, nil))
 		require.Equal(t, http.StatusOK, resp.StatusCode)
 
 		summary := decodeBody[models.ApiSummary](t, resp)
@@ -650,6 +692,68 @@ func TestPostmanEndpoint_Success(t *testing.T) {
 	require.JSONEq(t, `{"info":{"name":"Postman API"}}`, string(readRawBody(t, resp)))
 }
 
+func TestBrunoEndpointAndArtifactLinks(t *testing.T) {
+	env := newIntegrationEnv(t)
+	ctx := context.Background()
+
+	orgURI := "https://voorbeelden.example.com/organisaties/" + uuid.NewString()
+	apiID := uuid.NewString()
+	require.NoError(t, env.repo.Save(&models.Api{
+		Id:             apiID,
+		OasUri:         "https://voorbeelden.example.com/apis/" + apiID + "/openapi.json",
+		Title:          "Bruno API",
+		OrganisationID: &orgURI,
+		Organisation:   &models.Organisation{Uri: orgURI, Label: "Bruno Org"},
+	}))
+
+	resp := env.doRequest(t, http.MethodGet, "/v1/apis/"+apiID+"/bruno")
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	prob := decodeBody[problem.APIError](t, resp)
+	require.Contains(t, prob.Errors[0].Detail, "Bruno artifact not found")
+
+	resp = env.doRequest(t, http.MethodGet, "/v1/apis/"+apiID)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Nil(t, decodeBody[models.ApiDetail](t, resp).Links)
+
+	for _, art := range []models.ApiArtifact{
+		{Kind: "bruno", Filename: "bruno.zip", ContentType: "application/zip", Data: []byte("PK")},
+		{Kind: "postman", Filename: "postman.json", ContentType: "application/json", Data: []byte(`{}`)},
+		{Kind: "oas", Version: "3.1", Format: "json", Source: "converted", ContentType: "application/json", Data: []byte(`{}`)},
+		{Kind: "oas", Version: "3.0", Format: "yaml", Source: "original", ContentType: "application/yaml", Data: []byte(`{}`)},
+		{Kind: "oas", Version: "3.0", Format: "yaml", Source: "converted", ContentType: "application/yaml", Data: []byte(`{}`)},
+	} {
+		art.ID = uuid.NewString()
+		art.ApiID = apiID
+		art.CreatedAt = time.Now()
+		require.NoError(t, env.repo.SaveArtifact(ctx, &art))
+	}
+
+	resp = env.doRequest(t, http.MethodGet, "/v1/apis/"+apiID+"/bruno")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/zip", resp.Header.Get("Content-Type"))
+	require.Contains(t, resp.Header.Get("Content-Disposition"), `filename="bruno.zip"`)
+	require.Equal(t, "PK", string(readRawBody(t, resp)))
+
+	resp = env.doRequest(t, http.MethodGet, "/v1/apis/"+apiID)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	detail := decodeBody[models.ApiDetail](t, resp)
+	require.NotNil(t, detail.Links)
+	base := "/v1/apis/" + apiID
+	require.Equal(t, &models.Link{Href: base + "/bruno", Type: "application/zip"}, detail.Links.Bruno)
+	require.Equal(t, &models.Link{Href: base + "/postman", Type: "application/json"}, detail.Links.Postman)
+	require.Equal(t, []models.Link{
+		{Href: base + "/oas/3.0.yaml", Name: "3.0.yaml", Type: "application/yaml"},
+		{Href: base + "/oas/3.1.json", Name: "3.1.json", Type: "application/json"},
+	}, detail.Links.Oas)
+	require.Empty(t, detail.Artifacts)
+
+	for _, link := range append(detail.Links.Oas, *detail.Links.Bruno, *detail.Links.Postman) {
+		resp := env.doRequest(t, http.MethodGet, link.Href)
+		require.Equal(t, http.StatusOK, resp.StatusCode, link.Href)
+		require.NoError(t, resp.Body.Close())
+	}
+}
+
 func TestOASEndpoint_SuccessAndErrors(t *testing.T) {
 	env := newIntegrationEnv(t)
 	ctx := context.Background()
@@ -726,6 +830,493 @@ func TestCreateOrganisationEndpoint_Success(t *testing.T) {
 	require.Equal(t, "Nieuwe Org", org.Label)
 }
 
+func TestPrivateEndpoints_RequireBearerTokenWithScope(t *testing.T) {
+	issuer := testutil.NewTokenIssuer(t, "https://auth.example.com/realms/don", "api-register")
+	authenticator, err := auth.NewAuthenticator(auth.Config{
+		Issuer:   issuer.Issuer,
+		Audience: issuer.Audience,
+		JWKSFile: issuer.JWKSFile,
+	})
+	require.NoError(t, err)
+	env := newIntegrationEnv(t, api_client.WithAuthenticator(authenticator))
+
+	body := map[string]string{
+		"uri":   "https://voorbeelden.example.com/organisaties/auth",
+		"label": "Auth Org",
+	}
+
+	t.Run("no token", func(t *testing.T) {
+		resp := env.doJSONRequest(t, http.MethodPost, "/v1/organisations", body)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		require.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+		prob := decodeBody[problem.APIError](t, resp)
+		require.Equal(t, 401, prob.Status)
+	})
+
+	t.Run("wrong scope", func(t *testing.T) {
+		resp := env.doJSONRequestWithHeaders(t, http.MethodPost, "/v1/organisations", body, map[string]string{
+			"Authorization": "Bearer " + issuer.Token(t, "client", "apis:write"),
+		})
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		prob := decodeBody[problem.APIError](t, resp)
+		require.Equal(t, 403, prob.Status)
+	})
+
+	t.Run("lint results need apis:read", func(t *testing.T) {
+		resp := env.doRequest(t, http.MethodGet, "/v1/lint-results")
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		resp = env.doRequestWithHeaders(t, http.MethodGet, "/v1/lint-results", map[string]string{
+			"Authorization": "Bearer " + issuer.Token(t, "client", "apis:read"),
+		})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+	})
+
+	t.Run("update api without token", func(t *testing.T) {
+		resp := env.doJSONRequest(t, http.MethodPut, "/v1/apis/some-id", map[string]string{
+			"organisationUri": body["uri"],
+			"sunset":          "2030-01-01",
+		})
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+	})
+
+	t.Run("public endpoints stay open", func(t *testing.T) {
+		resp := env.doRequest(t, http.MethodGet, "/v1/apis")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+	})
+
+	t.Run("valid token", func(t *testing.T) {
+		resp := env.doJSONRequestWithHeaders(t, http.MethodPost, "/v1/organisations", body, map[string]string{
+			"Authorization": "Bearer " + issuer.OrganisationToken(t, "client", []string{body["uri"]}, "organisations:write"),
+		})
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		org := decodeBody[models.Organisation](t, resp)
+		require.Equal(t, body["uri"], org.Uri)
+	})
+}
+
+func TestWriteAuthorization_BoundToCallerOrganisation(t *testing.T) {
+	issuer := testutil.NewTokenIssuer(t, "https://auth.example.com/realms/don", "api-register")
+	authenticator, err := auth.NewAuthenticator(auth.Config{
+		Issuer:   issuer.Issuer,
+		Audience: issuer.Audience,
+		JWKSFile: issuer.JWKSFile,
+	})
+	require.NoError(t, err)
+	env := newIntegrationEnv(t, api_client.WithAuthenticator(authenticator))
+
+	owner := &models.Organisation{Uri: "https://voorbeelden.example.com/organisaties/eigenaar", Label: "Eigenaar"}
+	other := "https://voorbeelden.example.com/organisaties/ander"
+	require.NoError(t, env.repo.SaveOrganisatie(owner))
+
+	apiID := uuid.NewString()
+	require.NoError(t, env.repo.Save(&models.Api{
+		Id:             apiID,
+		Title:          "Eigenaar API",
+		OasUri:         "https://voorbeelden.example.com/apis/eigenaar/openapi.json",
+		OrganisationID: &owner.Uri,
+		Organisation:   owner,
+		Version:        "1.0.0",
+	}))
+
+	update := map[string]string{"organisationUri": owner.Uri, "sunset": "2030-01-01"}
+	put := func(token string) *http.Response {
+		return env.doJSONRequestWithHeaders(t, http.MethodPut, "/v1/apis/"+apiID, update, env.ifMatch(t, apiID, map[string]string{
+			"Authorization": "Bearer " + token,
+		}))
+	}
+
+	t.Run("body uri alone is not enough", func(t *testing.T) {
+		resp := put(issuer.OrganisationToken(t, "ander", []string{other}, "apis:write"))
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		prob := decodeBody[problem.APIError](t, resp)
+		require.Equal(t, 403, prob.Status)
+	})
+
+	t.Run("token without organisations", func(t *testing.T) {
+		resp := put(issuer.Token(t, "client", "apis:write"))
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+	})
+
+	t.Run("mapped organisation", func(t *testing.T) {
+		resp := put(issuer.OrganisationToken(t, "eigenaar", []string{other, owner.Uri}, "apis:write"))
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+	})
+
+	t.Run("admin acts across organisations", func(t *testing.T) {
+		resp := put(issuer.Token(t, "beheer", "apis:write", "admin"))
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+	})
+
+	t.Run("create organisation for another organisation", func(t *testing.T) {
+		resp := env.doJSONRequestWithHeaders(t, http.MethodPost, "/v1/organisations", map[string]string{
+			"uri":   other,
+			"label": "Ander",
+		}, map[string]string{
+			"Authorization": "Bearer " + issuer.OrganisationToken(t, "eigenaar", []string{owner.Uri}, "organisations:write"),
+		})
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+	})
+
+	t.Run("register api for another organisation", func(t *testing.T) {
+		resp := env.doJSONRequestWithHeaders(t, http.MethodPost, "/v1/apis", map[string]string{
+			"oasUrl":          "https://voorbeelden.example.com/apis/ander/openapi.json",
+			"organisationUri": other,
+		}, map[string]string{
+			"Authorization": "Bearer " + issuer.OrganisationToken(t, "eigenaar", []string{owner.Uri}, "apis:write"),
+		})
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+	})
+}
+
+func TestAuditEndpoints_RecordMutations(t *testing.T) {
+	issuer := testutil.NewTokenIssuer(t, "https://auth.example.com/realms/don", "api-register")
+	authenticator, err := auth.NewAuthenticator(auth.Config{
+		Issuer:   issuer.Issuer,
+		Audience: issuer.Audience,
+		JWKSFile: issuer.JWKSFile,
+	})
+	require.NoError(t, err)
+	env := newIntegrationEnv(t, api_client.WithAuthenticator(authenticator))
+
+	orgURI := "https://voorbeelden.example.com/organisaties/audit"
+	writer := map[string]string{
+		"Authorization": "Bearer " + issuer.OrganisationToken(t, "audit-client", []string{orgURI}, "apis:write", "apis:read", "organisations:write"),
+	}
+	admin := map[string]string{"Authorization": "Bearer " + issuer.Token(t, "beheer", "admin")}
+
+	resp := env.doJSONRequestWithHeaders(t, http.MethodPost, "/v1/organisations", map[string]string{
+		"uri":   orgURI,
+		"label": "Audit Org",
+	}, writer)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	apiID := uuid.NewString()
+	require.NoError(t, env.repo.Save(&models.Api{
+		Id:             apiID,
+		Title:          "Audit API",
+		OasUri:         "https://voorbeelden.example.com/apis/audit/openapi.json",
+		OrganisationID: &orgURI,
+		Version:        "1.0.0",
+		Sunset:         "2030-01-01",
+	}))
+
+	resp = env.doJSONRequestWithHeaders(t, http.MethodPut, "/v1/apis/"+apiID, map[string]string{
+		"organisationUri": orgURI,
+		"sunset":          "2031-06-30",
+		"deprecated":      "2030-06-30",
+	}, env.ifMatch(t, apiID, writer))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	t.Run("api audit trail", func(t *testing.T) {
+		resp := env.doRequestWithHeaders(t, http.MethodGet, "/v1/apis/"+apiID+"/audit", writer)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "1", resp.Header.Get("Total-Count"))
+		entries := decodeBody[[]models.AuditEntry](t, resp)
+		require.Len(t, entries, 1)
+		entry := entries[0]
+		require.Equal(t, models.AuditActionApiLifecycleChanged, entry.Action)
+		require.Equal(t, "audit-client", entry.Actor)
+		require.Equal(t, orgURI, entry.OrganisationUri)
+		require.ElementsMatch(t, []models.FieldChange{
+			{Field: "sunset", Before: "2030-01-01", After: "2031-06-30"},
+			{Field: "deprecated", Before: "", After: "2030-06-30"},
+		}, entry.Changes)
+	})
+
+	t.Run("unknown api", func(t *testing.T) {
+		resp := env.doRequestWithHeaders(t, http.MethodGet, "/v1/apis/onbekend/audit", writer)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+	})
+
+	t.Run("global audit requires admin", func(t *testing.T) {
+		resp := env.doRequestWithHeaders(t, http.MethodGet, "/v1/audit", writer)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+	})
+
+	t.Run("global audit with filters", func(t *testing.T) {
+		resp := env.doRequestWithHeaders(t, http.MethodGet, "/v1/audit?organisation="+url.QueryEscape(orgURI), admin)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		entries := decodeBody[[]models.AuditEntry](t, resp)
+		require.Len(t, entries, 2)
+		require.Equal(t, models.AuditActionApiLifecycleChanged, entries[0].Action)
+		require.Equal(t, models.AuditActionOrganisationCreated, entries[1].Action)
+
+		resp = env.doRequestWithHeaders(t, http.MethodGet, "/v1/audit?action=organisation.created&actor=audit-client", admin)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		entries = decodeBody[[]models.AuditEntry](t, resp)
+		require.Len(t, entries, 1)
+
+		resp = env.doRequestWithHeaders(t, http.MethodGet, "/v1/audit?since=gisteren", admin)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+	})
+}
+
+func TestDeleteApi_SoftDeleteAndAdminRestore(t *testing.T) {
+	issuer := testutil.NewTokenIssuer(t, "https://auth.example.com/realms/don", "api-register")
+	authenticator, err := auth.NewAuthenticator(auth.Config{
+		Issuer:   issuer.Issuer,
+		Audience: issuer.Audience,
+		JWKSFile: issuer.JWKSFile,
+	})
+	require.NoError(t, err)
+	env := newIntegrationEnv(t, api_client.WithAuthenticator(authenticator))
+
+	orgURI := "https://voorbeelden.example.com/organisaties/verwijderen"
+	owner := map[string]string{
+		"Authorization": "Bearer " + issuer.OrganisationToken(t, "eigenaar", []string{orgURI}, "apis:write", "apis:read"),
+	}
+	stranger := map[string]string{
+		"Authorization": "Bearer " + issuer.OrganisationToken(t, "ander", []string{"https://example.org/ander"}, "apis:write"),
+	}
+	admin := map[string]string{"Authorization": "Bearer " + issuer.Token(t, "beheer", "admin")}
+
+	apiID := uuid.NewString()
+	title := "Verwijderbare API " + apiID
+	require.NoError(t, env.repo.Save(&models.Api{
+		Id:             apiID,
+		Title:          title,
+		OasUri:         "https://voorbeelden.example.com/apis/" + apiID + "/openapi.json",
+		Organisation:   &models.Organisation{Uri: orgURI, Label: "Verwijder Org"},
+		OrganisationID: &orgURI,
+		Version:        "1.0.0",
+	}))
+	for _, art := range []models.ApiArtifact{
+		{Kind: "oas", Version: "3.0", Format: "json", Source: "original", Filename: "openapi.json", ContentType: "application/json", Data: []byte(`{}`)},
+		{Kind: "postman", Filename: "postman.json", ContentType: "application/json", Data: []byte(`{}`)},
+	} {
+		art.ID = uuid.NewString()
+		art.ApiID = apiID
+		art.CreatedAt = time.Now()
+		require.NoError(t, env.repo.SaveArtifact(context.Background(), &art))
+	}
+	downloads := []string{"/v1/apis/" + apiID + "/oas/3.0.json", "/v1/apis/" + apiID + "/postman"}
+	for _, path := range downloads {
+		resp := env.doRequest(t, http.MethodGet, path)
+		require.Equal(t, http.StatusOK, resp.StatusCode, path)
+		require.NoError(t, resp.Body.Close())
+	}
+	searchPath := "/v1/apis/_search?q=" + url.QueryEscape(apiID)
+	listPath := "/v1/apis?ids=" + apiID
+
+	resp := env.doRequestWithHeaders(t, http.MethodDelete, "/v1/apis/"+apiID, stranger)
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	resp = env.doRequestWithHeaders(t, http.MethodDelete, "/v1/apis/"+apiID, owner)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	resp = env.doRequest(t, http.MethodGet, "/v1/apis/"+apiID)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+	for _, path := range downloads {
+		resp := env.doRequest(t, http.MethodGet, path)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode, path)
+		require.NoError(t, resp.Body.Close())
+	}
+	resp = env.doRequest(t, http.MethodGet, listPath)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Empty(t, decodeBody[[]models.ApiSummary](t, resp))
+	resp = env.doRequest(t, http.MethodGet, searchPath)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Empty(t, decodeBody[[]models.ApiSummary](t, resp))
+
+	resp = env.doRequestWithHeaders(t, http.MethodDelete, "/v1/apis/"+apiID, owner)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	resp = env.doRequestWithHeaders(t, http.MethodGet, "/v1/audit?apiId="+apiID, admin)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	entries := decodeBody[[]models.AuditEntry](t, resp)
+	require.Len(t, entries, 1)
+	require.Equal(t, models.AuditActionApiDeleted, entries[0].Action)
+	require.Equal(t, "eigenaar", entries[0].Actor)
+	require.Len(t, entries[0].Changes, 1)
+	require.Equal(t, "deletedAt", entries[0].Changes[0].Field)
+
+	resp = env.doRequestWithHeaders(t, http.MethodPost, "/v1/apis/"+apiID+"/restore", owner)
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	resp = env.doRequestWithHeaders(t, http.MethodPost, "/v1/apis/"+apiID+"/restore", admin)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	restored := decodeBody[models.ApiSummary](t, resp)
+	require.Equal(t, apiID, restored.Id)
+
+	resp = env.doRequest(t, http.MethodGet, listPath)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, decodeBody[[]models.ApiSummary](t, resp), 1)
+	resp = env.doRequest(t, http.MethodGet, searchPath)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, decodeBody[[]models.ApiSummary](t, resp), 1)
+
+	resp = env.doRequestWithHeaders(t, http.MethodPost, "/v1/apis/"+apiID+"/restore", admin)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	t.Run("restore conflicts with re-registered oasUrl", func(t *testing.T) {
+		resp := env.doRequestWithHeaders(t, http.MethodDelete, "/v1/apis/"+apiID, owner)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+		require.NoError(t, env.repo.Save(&models.Api{
+			Id:             uuid.NewString(),
+			Title:          title,
+			OasUri:         "https://voorbeelden.example.com/apis/" + apiID + "/openapi.json",
+			OrganisationID: &orgURI,
+		}))
+
+		resp = env.doRequestWithHeaders(t, http.MethodPost, "/v1/apis/"+apiID+"/restore", admin)
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+		require.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+		require.NoError(t, resp.Body.Close())
+	})
+}
+
+func TestApiKeys_IssueUseAndRevoke(t *testing.T) {
+	issuer := testutil.NewTokenIssuer(t, "https://auth.example.com/realms/don", "api-register")
+	authenticator, err := auth.NewAuthenticator(auth.Config{
+		Issuer:   issuer.Issuer,
+		Audience: issuer.Audience,
+		JWKSFile: issuer.JWKSFile,
+	})
+	require.NoError(t, err)
+	env := newIntegrationEnv(t, api_client.WithAuthenticator(authenticator))
+	admin := map[string]string{"Authorization": "Bearer " + issuer.Token(t, "beheer", "admin")}
+
+	resp := env.doJSONRequestWithHeaders(t, http.MethodPost, "/v1/api-keys", map[string]string{
+		"consumerName": "Portaal",
+	}, map[string]string{"Authorization": "Bearer " + issuer.Token(t, "client", "apis:write")})
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	resp = env.doJSONRequestWithHeaders(t, http.MethodPost, "/v1/api-keys", map[string]string{
+		"consumerName": "Portaal",
+		"contactName":  "Team Portaal",
+		"contactEmail": "portaal@example.com",
+	}, admin)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	created := decodeBody[models.ApiKeyCreated](t, resp)
+	require.True(t, strings.HasPrefix(created.Key, "don_"))
+	require.True(t, strings.HasPrefix(created.Key, created.Prefix))
+	require.Equal(t, "Portaal", created.ConsumerName)
+
+	withKey := map[string]string{auth.APIKeyHeader: created.Key}
+	for i := 0; i < 2; i++ {
+		resp = env.doRequestWithHeaders(t, http.MethodGet, "/v1/apis", withKey)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+	}
+	resp = env.doRequestWithHeaders(t, http.MethodGet, "/v1/organisations", withKey)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	resp = env.doRequestWithHeaders(t, http.MethodGet, "/v1/apis", map[string]string{auth.APIKeyHeader: "don_onbekend"})
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	require.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+	require.NoError(t, resp.Body.Close())
+
+	resp = env.doRequestWithHeaders(t, http.MethodGet, "/v1/api-keys", admin)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	keys := decodeBody[[]map[string]any](t, resp)
+	require.Len(t, keys, 1)
+	require.EqualValues(t, 3, keys[0]["usageCount"])
+	require.NotContains(t, keys[0], "key")
+	require.NotContains(t, keys[0], "hash")
+
+	resp = env.doRequestWithHeaders(t, http.MethodDelete, "/v1/api-keys/"+created.ID, admin)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	resp = env.doRequestWithHeaders(t, http.MethodGet, "/v1/apis", withKey)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	resp = env.doRequestWithHeaders(t, http.MethodDelete, "/v1/api-keys/onbekend", admin)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+}
+
+func TestRateLimit_PerClientBudgets(t *testing.T) {
+	issuer := testutil.NewTokenIssuer(t, "https://auth.example.com/realms/don", "api-register")
+	authenticator, err := auth.NewAuthenticator(auth.Config{
+		Issuer:   issuer.Issuer,
+		Audience: issuer.Audience,
+		JWKSFile: issuer.JWKSFile,
+	})
+	require.NoError(t, err)
+	limiter := ratelimit.New(ratelimit.Config{ReadLimit: 2, WriteLimit: 1, Window: time.Minute})
+	env := newIntegrationEnv(t, api_client.WithAuthenticator(authenticator), api_client.WithRateLimiter(limiter))
+
+	reader := map[string]string{"Authorization": "Bearer " + issuer.Token(t, "reader", "apis:read")}
+	for i := 0; i < 2; i++ {
+		resp := env.doRequestWithHeaders(t, http.MethodGet, "/v1/apis", reader)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "2", resp.Header.Get("RateLimit-Limit"))
+		require.Equal(t, strconv.Itoa(1-i), resp.Header.Get("RateLimit-Remaining"))
+		require.NoError(t, resp.Body.Close())
+	}
+	resp := env.doRequestWithHeaders(t, http.MethodGet, "/v1/apis", reader)
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	require.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+	require.NotEmpty(t, resp.Header.Get("Retry-After"))
+	require.NoError(t, resp.Body.Close())
+
+	// Een andere client en het anonieme IP-budget zijn niet geraakt.
+	other := map[string]string{"Authorization": "Bearer " + issuer.Token(t, "other", "apis:read")}
+	resp = env.doRequestWithHeaders(t, http.MethodGet, "/v1/apis", other)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+	resp = env.doRequest(t, http.MethodGet, "/v1/apis")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	// Schrijfacties hebben een eigen budget.
+	writer := map[string]string{"Authorization": "Bearer " + issuer.OrganisationToken(t, "reader", []string{"https://example.org"}, "organisations:write")}
+	org := map[string]string{"uri": "https://example.org", "label": "Example"}
+	resp = env.doJSONRequestWithHeaders(t, http.MethodPost, "/v1/organisations", org, writer)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	require.Equal(t, "1", resp.Header.Get("RateLimit-Limit"))
+	require.NoError(t, resp.Body.Close())
+	resp = env.doJSONRequestWithHeaders(t, http.MethodPost, "/v1/organisations", org, writer)
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+}
+
+func TestRateLimit_ForwardedForOnlyFromTrustedProxy(t *testing.T) {
+	do := func(env *integrationEnv, forwardedFor string) int {
+		resp := env.doRequestWithHeaders(t, http.MethodGet, "/v1/apis", map[string]string{"X-Forwarded-For": forwardedFor})
+		require.NoError(t, resp.Body.Close())
+		return resp.StatusCode
+	}
+
+	// Zonder vertrouwde proxy telt het adres van de verbinding.
+	env := newIntegrationEnv(t, api_client.WithRateLimiter(ratelimit.New(ratelimit.Config{ReadLimit: 1, Window: time.Minute})))
+	require.Equal(t, http.StatusOK, do(env, "203.0.113.1"))
+	require.Equal(t, http.StatusTooManyRequests, do(env, "203.0.113.2"), "een vervalst X-Forwarded-For geeft geen nieuw budget")
+
+	env = newIntegrationEnv(t,
+		api_client.WithRateLimiter(ratelimit.New(ratelimit.Config{ReadLimit: 1, Window: time.Minute})),
+		api_client.WithTrustedProxies([]string{"127.0.0.1", "::1"}),
+	)
+	require.Equal(t, http.StatusOK, do(env, "203.0.113.1"))
+	require.Equal(t, http.StatusOK, do(env, "203.0.113.2"))
+	require.Equal(t, http.StatusTooManyRequests, do(env, "203.0.113.1"))
+}
+
 func TestCreateApiEndpoint_SuccessAndErrors(t *testing.T) {
 	env := newIntegrationEnv(t)
 	ctx := context.Background()
@@ -860,10 +1451,10 @@ func TestUpdateApiEndpoint_OASSuccessAndErrors(t *testing.T) {
 	}))
 
 	t.Run("success", func(t *testing.T) {
-		resp := env.doJSONRequest(t, http.MethodPut, "/v1/apis/"+apiID, map[string]any{
+		resp := env.doJSONRequestWithHeaders(t, http.MethodPut, "/v1/apis/"+apiID, map[string]any{
 			"oasUrl":          oasSrv.URL,
 			"organisationUri": org.Uri,
-		})
+		}, env.ifMatch(t, apiID, nil))
 		require.Equal(t, http.StatusOK, resp.StatusCode)
 		require.Equal(t, "test-version", resp.Header.Get("API-Version"))
 
@@ -881,10 +1472,10 @@ func TestUpdateApiEndpoint_OASSuccessAndErrors(t *testing.T) {
 	})
 
 	t.Run("invalid lifecycle date", func(t *testing.T) {
-		resp := env.doJSONRequest(t, http.MethodPut, "/v1/apis/"+apiID, map[string]any{
+		resp := env.doJSONRequestWithHeaders(t, http.MethodPut, "/v1/apis/"+apiID, map[string]any{
 			"organisationUri": org.Uri,
 			"sunset":          "morgen",
-		})
+		}, env.ifMatch(t, apiID, nil))
 		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
 		prob := decodeBody[problem.APIError](t, resp)
 		require.Equal(t, 400, prob.Status)
@@ -918,3 +1509,1448 @@ func TestListAndSearchEndpoints_InvalidPagination(t *testing.T) {
 		require.Equal(t, 400, prob.Status)
 	})
 }
+
+func TestPatchApi_MergePatchOverrides(t *testing.T) {
+	issuer := testutil.NewTokenIssuer(t, "https://auth.example.com/realms/don", "api-register")
+	authenticator, err := auth.NewAuthenticator(auth.Config{
+		Issuer:   issuer.Issuer,
+		Audience: issuer.Audience,
+		JWKSFile: issuer.JWKSFile,
+	})
+	require.NoError(t, err)
+	env := newIntegrationEnv(t, api_client.WithAuthenticator(authenticator))
+
+	orgURI := "https://voorbeelden.example.com/organisaties/patch"
+	owner := map[string]string{
+		"Authorization": "Bearer " + issuer.OrganisationToken(t, "eigenaar", []string{orgURI}, "apis:write"),
+		"Content-Type":  models.MergePatchContentType,
+	}
+	stranger := map[string]string{
+		"Authorization": "Bearer " + issuer.OrganisationToken(t, "ander", []string{"https://example.org/ander"}, "apis:write"),
+		"Content-Type":  models.MergePatchContentType,
+	}
+
+	apiID := uuid.NewString()
+	require.NoError(t, env.repo.Save(&models.Api{
+		Id:             apiID,
+		Title:          "Patchbare API",
+		OasUri:         "https://voorbeelden.example.com/apis/" + apiID + "/openapi.json",
+		OasHash:        "hash",
+		DocsUrl:        "https://spec.example.com/docs",
+		ContactName:    "Spec Contact",
+		Organisation:   &models.Organisation{Uri: orgURI, Label: "Patch Org"},
+		OrganisationID: &orgURI,
+		Version:        "1.0.0",
+	}))
+	path := "/v1/apis/" + apiID
+
+	resp := env.doJSONRequestWithHeaders(t, http.MethodPatch, path, map[string]any{"docsUrl": "https://register.example.com/docs"}, stranger)
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	resp = env.doJSONRequestWithHeaders(t, http.MethodPatch, path, map[string]any{
+		"contact":       map[string]any{"name": "Beheerder", "email": "beheer@example.com"},
+		"docsUrl":       "https://register.example.com/docs",
+		"repositoryUri": "https://github.com/voorbeeld/api",
+		"sunset":        "2031-06-30",
+	}, env.ifMatch(t, apiID, owner))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	summary := decodeBody[models.ApiSummary](t, resp)
+	require.Equal(t, "Beheerder", summary.Contact.Name)
+
+	stored, err := env.repo.GetApiByID(context.Background(), apiID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.Overrides.DocsUrl)
+	require.Equal(t, "https://register.example.com/docs", *stored.Overrides.DocsUrl)
+	require.NotNil(t, stored.Overrides.RepositoryUri)
+	require.NotNil(t, stored.Overrides.Sunset)
+	require.Equal(t, "hash", stored.OasHash)
+
+	resp = env.doJSONRequestWithHeaders(t, http.MethodPatch, path, map[string]any{"contact": nil}, env.ifMatch(t, apiID, owner))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+	stored, err = env.repo.GetApiByID(context.Background(), apiID)
+	require.NoError(t, err)
+	require.Nil(t, stored.Overrides.ContactName)
+	require.Nil(t, stored.Overrides.ContactEmail)
+	require.NotNil(t, stored.Overrides.DocsUrl)
+	require.Empty(t, stored.ContactName)
+	require.Empty(t, stored.OasHash, "volgende refresh haalt het contact weer uit de OAS")
+
+	resp = env.doJSONRequestWithHeaders(t, http.MethodPatch, path, map[string]any{"title": "Niet van het register"}, env.ifMatch(t, apiID, owner))
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+	require.NoError(t, resp.Body.Close())
+
+	resp = env.doJSONRequestWithHeaders(t, http.MethodPatch, "/v1/apis/onbekend", map[string]any{"docsUrl": nil}, owner)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	resp = env.doRequestWithHeaders(t, http.MethodGet, "/v1/apis/"+apiID+"/audit", map[string]string{
+		"Authorization": "Bearer " + issuer.OrganisationToken(t, "lezer", []string{orgURI}, "apis:read"),
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	entries := decodeBody[[]models.AuditEntry](t, resp)
+	require.Len(t, entries, 2)
+	require.Equal(t, models.AuditActionApiPatched, entries[0].Action)
+}
+
+func TestOrganisations_DetailUpdateDeleteAndMerge(t *testing.T) {
+	issuer := testutil.NewTokenIssuer(t, "https://auth.example.com/realms/don", "api-register")
+	authenticator, err := auth.NewAuthenticator(auth.Config{
+		Issuer:   issuer.Issuer,
+		Audience: issuer.Audience,
+		JWKSFile: issuer.JWKSFile,
+	})
+	require.NoError(t, err)
+	env := newIntegrationEnv(t, api_client.WithAuthenticator(authenticator))
+
+	suffix := uuid.NewString()
+	canonURI := "https://voorbeelden.example.com/organisaties/canoniek-" + suffix
+	dupURI := "https://voorbeelden.example.com/organisaties/dubbel-" + suffix
+	owner := map[string]string{
+		"Authorization": "Bearer " + issuer.OrganisationToken(t, "eigenaar", []string{canonURI, dupURI}, "organisations:write"),
+	}
+	stranger := map[string]string{
+		"Authorization": "Bearer " + issuer.OrganisationToken(t, "ander", []string{"https://example.org/ander"}, "organisations:write"),
+	}
+	admin := map[string]string{"Authorization": "Bearer " + issuer.Token(t, "beheer", "admin")}
+
+	for _, org := range []models.Organisation{{Uri: canonURI, Label: "Canoniek"}, {Uri: dupURI, Label: "Dubbel"}} {
+		resp := env.doJSONRequestWithHeaders(t, http.MethodPost, "/v1/organisations", org, owner)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+	}
+	apiID := uuid.NewString()
+	require.NoError(t, env.repo.Save(&models.Api{
+		Id:             apiID,
+		Title:          "Organisatie API",
+		OasUri:         "https://voorbeelden.example.com/apis/" + apiID + "/openapi.json",
+		OrganisationID: &dupURI,
+		Sunset:         "2999-01-01",
+	}))
+
+	t.Run("detail by escaped and literal uri", func(t *testing.T) {
+		for _, path := range []string{"/v1/organisations/" + url.PathEscape(dupURI), "/v1/organisations/" + dupURI} {
+			resp := env.doRequest(t, http.MethodGet, path)
+			require.Equal(t, http.StatusOK, resp.StatusCode, path)
+			detail := decodeBody[models.OrganisationDetail](t, resp)
+			require.Equal(t, dupURI, detail.Uri)
+			require.Equal(t, models.OrganisationApiCounts{Total: 1, Sunset: 1}, detail.ApiCounts)
+			require.Equal(t, "/v1/organisations/"+url.PathEscape(dupURI), detail.Links.Self.Href)
+			require.Equal(t, "/v1/apis?organisation="+dupURI, detail.Links.Apis.Href)
+		}
+
+		resp := env.doRequest(t, http.MethodGet, "/v1/organisations/"+url.PathEscape("https://example.org/onbekend"))
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+	})
+
+	t.Run("update label", func(t *testing.T) {
+		path := "/v1/organisations/" + url.PathEscape(canonURI)
+		resp := env.doJSONRequestWithHeaders(t, http.MethodPut, path, map[string]string{"label": "Elders"}, stranger)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+
+		resp = env.doJSONRequestWithHeaders(t, http.MethodPut, path, map[string]string{"label": "Canonieke Organisatie"}, owner)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "Canonieke Organisatie", decodeBody[models.OrganisationDetail](t, resp).Label)
+	})
+
+	t.Run("delete refused while apis reference organisation", func(t *testing.T) {
+		resp := env.doRequestWithHeaders(t, http.MethodDelete, "/v1/organisations/"+url.PathEscape(dupURI), owner)
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+	})
+
+	t.Run("merge requires admin", func(t *testing.T) {
+		body := map[string]string{"duplicateUri": dupURI, "canonicalUri": canonURI}
+		resp := env.doJSONRequestWithHeaders(t, http.MethodPost, "/v1/organisations/_merge", body, owner)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+
+		resp = env.doJSONRequestWithHeaders(t, http.MethodPost, "/v1/organisations/_merge", body, admin)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		detail := decodeBody[models.OrganisationDetail](t, resp)
+		require.Equal(t, canonURI, detail.Uri)
+		require.Equal(t, 1, detail.ApiCounts.Total)
+
+		resp = env.doRequest(t, http.MethodGet, "/v1/organisations/"+url.PathEscape(dupURI))
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+		moved, err := env.repo.GetApiByID(context.Background(), apiID)
+		require.NoError(t, err)
+		require.Equal(t, canonURI, *moved.OrganisationID)
+	})
+
+	t.Run("delete empty organisation", func(t *testing.T) {
+		emptyURI := "https://voorbeelden.example.com/organisaties/leeg-" + suffix
+		require.NoError(t, env.repo.SaveOrganisatie(&models.Organisation{Uri: emptyURI, Label: "Leeg"}))
+		resp := env.doRequestWithHeaders(t, http.MethodDelete, "/v1/organisations/"+url.PathEscape(emptyURI), map[string]string{
+			"Authorization": "Bearer " + issuer.Token(t, "beheer", "admin", "organisations:write"),
+		})
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+	})
+}
+
+func TestTransferApi_RequiresConsentFromBothOrganisations(t *testing.T) {
+	issuer := testutil.NewTokenIssuer(t, "https://auth.example.com/realms/don", "api-register")
+	authenticator, err := auth.NewAuthenticator(auth.Config{
+		Issuer:   issuer.Issuer,
+		Audience: issuer.Audience,
+		JWKSFile: issuer.JWKSFile,
+	})
+	require.NoError(t, err)
+	env := newIntegrationEnv(t, api_client.WithAuthenticator(authenticator))
+
+	suffix := uuid.NewString()
+	fromURI := "https://voorbeelden.example.com/organisaties/oud-" + suffix
+	toURI := "https://voorbeelden.example.com/organisaties/nieuw-" + suffix
+	for _, org := range []models.Organisation{{Uri: fromURI, Label: "Oud ministerie"}, {Uri: toURI, Label: "Nieuw ministerie"}} {
+		require.NoError(t, env.repo.SaveOrganisatie(&org))
+	}
+	source := map[string]string{
+		"Authorization": "Bearer " + issuer.OrganisationToken(t, "bron", []string{fromURI}, "apis:write", "apis:read"),
+	}
+	target := map[string]string{
+		"Authorization": "Bearer " + issuer.OrganisationToken(t, "doel", []string{toURI}, "apis:write"),
+	}
+	stranger := map[string]string{
+		"Authorization": "Bearer " + issuer.OrganisationToken(t, "ander", []string{"https://example.org/ander"}, "apis:write"),
+	}
+	admin := map[string]string{"Authorization": "Bearer " + issuer.Token(t, "beheer", "admin", "apis:write")}
+
+	newApi := func(t *testing.T) string {
+		id := uuid.NewString()
+		require.NoError(t, env.repo.Save(&models.Api{
+			Id:             id,
+			Title:          "Over te dragen API",
+			OasUri:         "https://voorbeelden.example.com/apis/" + id + "/openapi.json",
+			OrganisationID: &fromURI,
+		}))
+		return id
+	}
+	transfer := func(t *testing.T, apiID, to string, headers map[string]string) *http.Response {
+		return env.doJSONRequestWithHeaders(t, http.MethodPost, "/v1/apis/"+apiID+"/transfer",
+			map[string]string{"organisationUri": to}, headers)
+	}
+
+	t.Run("source and target consent", func(t *testing.T) {
+		apiID := newApi(t)
+
+		resp := transfer(t, apiID, toURI, stranger)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+
+		resp = transfer(t, apiID, toURI, source)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		pending := decodeBody[models.ApiTransfer](t, resp)
+		require.Equal(t, models.ApiTransferPending, pending.Status)
+		require.Equal(t, "bron", pending.ApprovedByFrom)
+		require.Empty(t, pending.ApprovedByTo)
+		api, err := env.repo.GetApiByID(context.Background(), apiID)
+		require.NoError(t, err)
+		require.Equal(t, fromURI, *api.OrganisationID)
+		revision := api.Revision
+
+		resp = transfer(t, apiID, toURI, target)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		completed := decodeBody[models.ApiTransfer](t, resp)
+		require.Equal(t, pending.ID, completed.ID)
+		require.Equal(t, models.ApiTransferCompleted, completed.Status)
+		require.Equal(t, "doel", completed.ApprovedByTo)
+		require.NotNil(t, completed.CompletedAt)
+
+		api, err = env.repo.GetApiByID(context.Background(), apiID)
+		require.NoError(t, err)
+		require.Equal(t, toURI, *api.OrganisationID)
+		require.Equal(t, revision+1, api.Revision)
+		require.NotNil(t, api.UpdatedAt)
+		require.WithinDuration(t, *completed.CompletedAt, *api.UpdatedAt, time.Millisecond)
+
+		resp = env.doRequestWithHeaders(t, http.MethodGet, "/v1/apis/"+apiID+"/audit", source)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		entries := decodeBody[[]models.AuditEntry](t, resp)
+		require.Len(t, entries, 1)
+		require.Equal(t, models.AuditActionApiTransferred, entries[0].Action)
+		require.Equal(t, "doel", entries[0].Actor)
+		require.Contains(t, entries[0].Changes, models.FieldChange{Field: "organisationId", Before: fromURI, After: toURI})
+	})
+
+	t.Run("old organisation filter keeps finding the api", func(t *testing.T) {
+		for _, org := range []string{fromURI, toURI} {
+			resp := env.doRequest(t, http.MethodGet, "/v1/apis?organisation="+url.QueryEscape(org))
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			apis := decodeBody[[]models.ApiSummary](t, resp)
+			require.Len(t, apis, 1, org)
+			require.Equal(t, toURI, apis[0].Organisation.Uri)
+		}
+	})
+
+	t.Run("admin transfers at once", func(t *testing.T) {
+		apiID := newApi(t)
+		resp := transfer(t, apiID, toURI, admin)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, models.ApiTransferCompleted, decodeBody[models.ApiTransfer](t, resp).Status)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		apiID := newApi(t)
+		resp := transfer(t, apiID, fromURI, source)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+
+		resp = transfer(t, apiID, "https://example.org/onbekend", source)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+
+		resp = transfer(t, uuid.NewString(), toURI, source)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+	})
+}
+
+func TestReviewWorkflow_PendingUntilApproved(t *testing.T) {
+	issuer := testutil.NewTokenIssuer(t, "https://auth.example.com/realms/don", "api-register")
+	authenticator, err := auth.NewAuthenticator(auth.Config{
+		Issuer:   issuer.Issuer,
+		Audience: issuer.Audience,
+		JWKSFile: issuer.JWKSFile,
+	})
+	require.NoError(t, err)
+	env := newIntegrationEnv(t, api_client.WithAuthenticator(authenticator))
+
+	orgURI := "https://voorbeelden.example.com/organisaties/review-" + uuid.NewString()
+	require.NoError(t, env.repo.SaveOrganisatie(&models.Organisation{Uri: orgURI, Label: "Review Org"}))
+	submitter := map[string]string{
+		"Authorization": "Bearer " + issuer.OrganisationToken(t, "indiener", []string{orgURI}, "apis:write"),
+	}
+	reviewer := map[string]string{"Authorization": "Bearer " + issuer.Token(t, "curator", auth.ReviewScope)}
+
+	oasSrv := testutil.NewTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+  "openapi": "3.0.0",
+  "info": { "title": "Review API", "version": "1.0.0", "contact": { "name": "Team", "email": "team@example.com", "url": "https://example.com" } },
+  "paths": { "/ping": { "get": { "responses": { "200": { "description": "pong" } } } } }
+}`))
+	}))
+
+	resp := env.doJSONRequestWithHeaders(t, http.MethodPost, "/v1/apis", map[string]any{
+		"oasUrl":          oasSrv.URL,
+		"organisationUri": orgURI,
+	}, submitter)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	created := decodeBody[models.ApiSummary](t, resp)
+	require.NotNil(t, created.Review)
+	require.Equal(t, models.ReviewStatusPending, created.Review.Status)
+	apiPath := "/v1/apis/" + created.Id
+
+	listed := func(t *testing.T) []models.ApiSummary {
+		resp := env.doRequest(t, http.MethodGet, "/v1/apis?organisation="+url.QueryEscape(orgURI))
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		return decodeBody[[]models.ApiSummary](t, resp)
+	}
+
+	t.Run("pending registration is hidden from the public", func(t *testing.T) {
+		require.Empty(t, listed(t))
+
+		resp := env.doRequest(t, http.MethodGet, apiPath)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+
+		resp = env.doRequestWithHeaders(t, http.MethodGet, apiPath, submitter)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, models.ReviewStatusPending, decodeBody[models.ApiDetail](t, resp).Review.Status)
+
+		headers := map[string]string{"Accept": "application/ld+json", "Authorization": submitter["Authorization"]}
+		resp = env.doRequestWithHeaders(t, http.MethodGet, apiPath, headers)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+	})
+
+	t.Run("review queue requires review scope", func(t *testing.T) {
+		resp := env.doRequestWithHeaders(t, http.MethodGet, "/v1/reviews", submitter)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+
+		resp = env.doRequestWithHeaders(t, http.MethodGet, "/v1/reviews?perPage=100", reviewer)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		items := decodeBody[[]models.ApiReviewItem](t, resp)
+		ids := make([]string, 0, len(items))
+		for _, item := range items {
+			ids = append(ids, item.Id)
+			require.NotNil(t, item.Artifacts)
+		}
+		require.Contains(t, ids, created.Id)
+	})
+
+	t.Run("reject with reason and resubmit", func(t *testing.T) {
+		resp := env.doJSONRequestWithHeaders(t, http.MethodPost, apiPath+"/reject", map[string]string{"reason": " "}, reviewer)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+
+		resp = env.doJSONRequestWithHeaders(t, http.MethodPost, apiPath+"/reject", map[string]string{"reason": "Contactgegevens ontbreken"}, reviewer)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		rejected := decodeBody[models.ApiSummary](t, resp)
+		require.Equal(t, models.ReviewStatusRejected, rejected.Review.Status)
+		require.Equal(t, "Contactgegevens ontbreken", rejected.Review.Reason)
+		require.Equal(t, "curator", rejected.Review.ReviewedBy)
+
+		resp = env.doJSONRequestWithHeaders(t, http.MethodPost, apiPath+"/approve", nil, reviewer)
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+
+		resp = env.doJSONRequestWithHeaders(t, http.MethodPut, apiPath, map[string]string{
+			"organisationUri": orgURI,
+			"sunset":          "2031-06-30",
+		}, env.ifMatch(t, created.Id, submitter))
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, models.ReviewStatusPending, decodeBody[models.ApiSummary](t, resp).Review.Status)
+	})
+
+	t.Run("approve publishes the api", func(t *testing.T) {
+		resp := env.doJSONRequestWithHeaders(t, http.MethodPost, apiPath+"/approve", nil, reviewer)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Nil(t, decodeBody[models.ApiSummary](t, resp).Review)
+
+		apis := listed(t)
+		require.Len(t, apis, 1)
+		require.Equal(t, created.Id, apis[0].Id)
+
+		resp = env.doRequest(t, http.MethodGet, apiPath)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+
+		resp = env.doRequestWithHeaders(t, http.MethodGet, apiPath+"/audit", map[string]string{
+			"Authorization": "Bearer " + issuer.OrganisationToken(t, "lezer", []string{orgURI}, "apis:read"),
+		})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		actions := []string{}
+		for _, entry := range decodeBody[[]models.AuditEntry](t, resp) {
+			actions = append(actions, entry.Action)
+		}
+		require.Contains(t, actions, models.AuditActionApiRejected)
+		require.Contains(t, actions, models.AuditActionApiApproved)
+	})
+}
+
+func TestBulkCreateApis_ReportsPerItemResults(t *testing.T) {
+	env := newIntegrationEnv(t)
+
+	orgURI := "https://voorbeelden.example.com/organisaties/bulk-" + uuid.NewString()
+	require.NoError(t, env.repo.SaveOrganisatie(&models.Organisation{Uri: orgURI, Label: "Bulk Org"}))
+
+	oasSrv := testutil.NewTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{
+  "openapi": "3.0.0",
+  "info": { "title": "Bulk API %s", "version": "1.0.0", "contact": { "name": "Team", "email": "team@example.com", "url": "https://example.com" } },
+  "paths": { "/ping": { "get": { "responses": { "200": { "description": "pong" } } } } }
+}`, r.URL.Path)
+	}))
+	createdURL := oasSrv.URL + "/nieuw-" + uuid.NewString() + ".json"
+	existingURL := oasSrv.URL + "/bestaand-" + uuid.NewString() + ".json"
+
+	resp := env.doJSONRequest(t, http.MethodPost, "/v1/apis", map[string]any{
+		"oasUrl":          existingURL,
+		"organisationUri": orgURI,
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	existing := decodeBody[models.ApiSummary](t, resp)
+
+	resp = env.doJSONRequest(t, http.MethodPost, "/v1/apis/_bulk", []map[string]any{
+		{"oasUrl": createdURL, "organisationUri": orgURI},
+		{"oasUrl": existingURL, "organisationUri": orgURI},
+		{"oasUrl": oasSrv.URL + "/zonder-organisatie.json"},
+		{"oasUrl": oasSrv.URL + "/missing.json", "organisationUri": orgURI},
+	})
+	require.Equal(t, http.StatusMultiStatus, resp.StatusCode)
+	report := decodeBody[models.ApiBulkReport](t, resp)
+
+	require.Len(t, report.Results, 4)
+	require.Equal(t, models.ApiBulkSummary{Total: 4, Created: 1, Exists: 1, Invalid: 1, FetchFailed: 1}, report.Summary)
+
+	require.Equal(t, models.BulkStatusCreated, report.Results[0].Status)
+	require.Equal(t, http.StatusCreated, report.Results[0].HttpStatus)
+	require.NotNil(t, report.Results[0].Api)
+
+	// De bestaande registratie wacht op review; een anonieme aanroeper krijgt
+	// hem daarom niet te zien.
+	require.Equal(t, models.BulkStatusExists, report.Results[1].Status)
+	require.Nil(t, report.Results[1].Api)
+	require.Contains(t, report.Results[1].Detail, existingURL)
+
+	require.Equal(t, models.BulkStatusInvalid, report.Results[2].Status)
+	require.Equal(t, http.StatusBadRequest, report.Results[2].HttpStatus)
+	require.Contains(t, report.Results[2].InvalidParams, problem.InvalidParam{Name: "organisationUri", Reason: "is verplicht"})
+
+	require.Equal(t, models.BulkStatusFetchFailed, report.Results[3].Status)
+	require.Equal(t, 3, report.Results[3].Index)
+	require.NotEmpty(t, report.Results[3].Detail)
+
+	t.Run("approved registration is returned", func(t *testing.T) {
+		_, err := env.service.ApproveApi(context.Background(), existing.Id)
+		require.NoError(t, err)
+		resp := env.doJSONRequest(t, http.MethodPost, "/v1/apis/_bulk", []map[string]any{
+			{"oasUrl": existingURL, "organisationUri": orgURI},
+		})
+		require.Equal(t, http.StatusMultiStatus, resp.StatusCode)
+		report := decodeBody[models.ApiBulkReport](t, resp)
+		require.Equal(t, models.BulkStatusExists, report.Results[0].Status)
+		require.NotNil(t, report.Results[0].Api)
+		require.Equal(t, existing.Id, report.Results[0].Api.Id)
+	})
+
+	t.Run("empty batch is rejected", func(t *testing.T) {
+		resp := env.doJSONRequest(t, http.MethodPost, "/v1/apis/_bulk", []map[string]any{})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+	})
+}
+
+func TestValidateApi_DryRunPersistsNothing(t *testing.T) {
+	env := newIntegrationEnv(t)
+
+	orgURI := "https://voorbeelden.example.com/organisaties/validate-" + uuid.NewString()
+	require.NoError(t, env.repo.SaveOrganisatie(&models.Organisation{Uri: orgURI, Label: "Validate Org"}))
+
+	oasSrv := testutil.NewTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/zonder-contact.json" {
+			_, _ = w.Write([]byte(`{
+  "openapi": "3.0.0",
+  "info": { "title": "Validate API", "version": "1.0.0" },
+  "paths": { "/ping": { "get": { "responses": { "200": { "description": "pong" } } } } }
+}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{
+  "openapi": "3.0.0",
+  "info": { "title": "Validate API", "version": "1.0.0", "contact": { "name": "Team", "email": "team@example.com", "url": "https://example.com" } },
+  "components": { "securitySchemes": { "key": { "type": "apiKey", "in": "header", "name": "X-Api-Key" } } },
+  "security": [ { "key": [] } ],
+  "paths": { "/ping": { "get": { "responses": { "200": { "description": "pong" } } } } }
+}`))
+	}))
+	oasURL := oasSrv.URL + "/validate-" + uuid.NewString() + ".json"
+	body := map[string]any{"oasUrl": oasURL, "organisationUri": orgURI}
+
+	resp := env.doJSONRequest(t, http.MethodPost, "/v1/apis/_validate", body)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	result := decodeBody[models.ApiValidation](t, resp)
+	require.True(t, result.Valid)
+	require.Empty(t, result.InvalidParams)
+	require.Nil(t, result.Conflict)
+	require.NotNil(t, result.Api)
+	require.Empty(t, result.Api.Id)
+	require.Equal(t, "Validate API", result.Api.Title)
+	require.Equal(t, orgURI, result.Api.Organisation.Uri)
+	require.Equal(t, "api_key", result.Auth)
+	require.NotEmpty(t, result.LifecycleStatus)
+	require.True(t, result.Lint != nil || result.LintError != "")
+
+	stored, err := env.repo.FindByOasUrl(context.Background(), oasURL)
+	require.NoError(t, err)
+	require.Nil(t, stored)
+
+	t.Run("invalid document is reported, not rejected", func(t *testing.T) {
+		resp := env.doJSONRequest(t, http.MethodPost, "/v1/apis/_validate", map[string]any{
+			"oasUrl":          oasSrv.URL + "/zonder-contact.json",
+			"organisationUri": orgURI,
+		})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		result := decodeBody[models.ApiValidation](t, resp)
+		require.False(t, result.Valid)
+		require.NotEmpty(t, result.InvalidParams)
+	})
+
+	t.Run("existing registration is reported as conflict", func(t *testing.T) {
+		resp := env.doJSONRequest(t, http.MethodPost, "/v1/apis", body)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		created := decodeBody[models.ApiSummary](t, resp)
+
+		resp = env.doJSONRequest(t, http.MethodPost, "/v1/apis/_validate", body)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		result := decodeBody[models.ApiValidation](t, resp)
+		require.False(t, result.Valid)
+		require.NotNil(t, result.Conflict)
+		require.Nil(t, result.Conflict.Api, "pending registration must stay hidden")
+
+		_, err := env.service.ApproveApi(context.Background(), created.Id)
+		require.NoError(t, err)
+
+		resp = env.doJSONRequest(t, http.MethodPost, "/v1/apis/_validate", body)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		result = decodeBody[models.ApiValidation](t, resp)
+		require.NotNil(t, result.Conflict)
+		require.NotNil(t, result.Conflict.Api)
+		require.Equal(t, created.Id, result.Conflict.Api.Id)
+	})
+}
+
+func TestIdempotencyKey_ReplaysCreatedResponse(t *testing.T) {
+	env := newIntegrationEnv(t)
+
+	t.Run("organisation retry gets the original response", func(t *testing.T) {
+		orgURI := "https://voorbeelden.example.com/organisaties/idem-" + uuid.NewString()
+		headers := map[string]string{idempotency.Header: uuid.NewString()}
+		body := map[string]any{"uri": orgURI, "label": "Idempotente Org"}
+
+		resp := env.doJSONRequestWithHeaders(t, http.MethodPost, "/v1/organisations", body, headers)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		require.Empty(t, resp.Header.Get(idempotency.ReplayedHeader))
+		first, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+
+		resp = env.doJSONRequestWithHeaders(t, http.MethodPost, "/v1/organisations", body, headers)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		require.Equal(t, "true", resp.Header.Get(idempotency.ReplayedHeader))
+		replayed, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.JSONEq(t, string(first), string(replayed))
+
+		resp = env.doJSONRequestWithHeaders(t, http.MethodPost, "/v1/organisations",
+			map[string]any{"uri": orgURI, "label": "Andere Org"}, headers)
+		require.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+	})
+
+	t.Run("api retry does not fail with api bestaat al", func(t *testing.T) {
+		orgURI := "https://voorbeelden.example.com/organisaties/idem-" + uuid.NewString()
+		require.NoError(t, env.repo.SaveOrganisatie(&models.Organisation{Uri: orgURI, Label: "Idem Org"}))
+		oasSrv := testutil.NewTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{
+  "openapi": "3.0.0",
+  "info": { "title": "Idem API", "version": "1.0.0", "contact": { "name": "Team", "email": "team@example.com", "url": "https://example.com" } },
+  "paths": { "/ping": { "get": { "responses": { "200": { "description": "pong" } } } } }
+}`))
+		}))
+		headers := map[string]string{idempotency.Header: uuid.NewString()}
+		body := map[string]any{"oasUrl": oasSrv.URL + "/idem.json", "organisationUri": orgURI}
+
+		resp := env.doJSONRequestWithHeaders(t, http.MethodPost, "/v1/apis", body, headers)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		created := decodeBody[models.ApiSummary](t, resp)
+
+		resp = env.doJSONRequestWithHeaders(t, http.MethodPost, "/v1/apis", body, headers)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		require.Equal(t, created.Id, decodeBody[models.ApiSummary](t, resp).Id)
+
+		resp = env.doJSONRequest(t, http.MethodPost, "/v1/apis", body)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode, "without a key the retry is a new request")
+		require.NoError(t, resp.Body.Close())
+	})
+
+	t.Run("failed request releases the key", func(t *testing.T) {
+		headers := map[string]string{idempotency.Header: uuid.NewString()}
+		resp := env.doJSONRequestWithHeaders(t, http.MethodPost, "/v1/organisations", map[string]any{"label": "Zonder URI"}, headers)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+
+		orgURI := "https://voorbeelden.example.com/organisaties/idem-" + uuid.NewString()
+		resp = env.doJSONRequestWithHeaders(t, http.MethodPost, "/v1/organisations", map[string]any{"uri": orgURI, "label": "Met URI"}, headers)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+	})
+}
+
+func TestUpdateApi_RequiresMatchingETag(t *testing.T) {
+	env := newIntegrationEnv(t)
+
+	orgURI := "https://voorbeelden.example.com/organisaties/etag"
+	apiID := uuid.NewString()
+	require.NoError(t, env.repo.Save(&models.Api{
+		Id:             apiID,
+		Title:          "Versie API",
+		OasUri:         "https://voorbeelden.example.com/apis/" + apiID + "/openapi.json",
+		OasHash:        "hash",
+		ContactName:    "Spec Contact",
+		Organisation:   &models.Organisation{Uri: orgURI, Label: "ETag Org"},
+		OrganisationID: &orgURI,
+		Version:        "1.0.0",
+	}))
+	path := "/v1/apis/" + apiID
+	patchHeaders := func(etag string) map[string]string {
+		headers := map[string]string{"Content-Type": models.MergePatchContentType}
+		if etag != "" {
+			headers["If-Match"] = etag
+		}
+		return headers
+	}
+
+	resp := env.doRequest(t, http.MethodGet, path)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	etag := resp.Header.Get("ETag")
+	require.True(t, strings.HasPrefix(etag, `"1-`), "de ETag begint met de revisie: %s", etag)
+	require.NoError(t, resp.Body.Close())
+
+	resp = env.doJSONRequestWithHeaders(t, http.MethodPatch, path, map[string]any{"docsUrl": "https://register.example.com/docs"}, patchHeaders(""))
+	require.Equal(t, http.StatusPreconditionRequired, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	resp = env.doJSONRequestWithHeaders(t, http.MethodPatch, path, map[string]any{"docsUrl": "https://register.example.com/docs"}, patchHeaders(etag))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, `"2"`, resp.Header.Get("ETag"))
+	require.NoError(t, resp.Body.Close())
+
+	resp = env.doJSONRequestWithHeaders(t, http.MethodPatch, path, map[string]any{"docsUrl": nil}, patchHeaders(etag))
+	require.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+	require.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+	require.NoError(t, resp.Body.Close())
+
+	resp = env.doJSONRequestWithHeaders(t, http.MethodPut, path, map[string]any{"oasUrl": "https://voorbeelden.example.com/nieuw.json", "organisationUri": orgURI}, map[string]string{"If-Match": etag})
+	require.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	// Een achtergrondjob die met een verouderde kopie schrijft overschrijft de
+	// wijziging van de gebruiker niet.
+	stale, err := env.repo.GetApiByID(context.Background(), apiID)
+	require.NoError(t, err)
+	stale.Revision = 1
+	stale.OasHash = "refresh"
+	require.ErrorIs(t, env.repo.UpdateApi(context.Background(), *stale), repositories.ErrRevisionConflict)
+
+	stored, err := env.repo.GetApiByID(context.Background(), apiID)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), stored.Revision)
+	require.NotNil(t, stored.Overrides.DocsUrl)
+}
+
+func TestConditionalGet_ReadEndpoints(t *testing.T) {
+	env := newIntegrationEnv(t, api_client.WithCacheControl(httpcache.Config{
+		Lists:     "no-cache",
+		Apis:      "no-cache",
+		Artifacts: "max-age=300",
+	}))
+	ctx := context.Background()
+
+	orgURI := "https://voorbeelden.example.com/organisaties/cache-" + uuid.NewString()
+	apiID := uuid.NewString()
+	require.NoError(t, env.repo.Save(&models.Api{
+		Id:             apiID,
+		Title:          "Cache API",
+		OasUri:         "https://voorbeelden.example.com/apis/" + apiID + "/openapi.json",
+		ContactName:    "Cache Team",
+		Organisation:   &models.Organisation{Uri: orgURI, Label: "Cache Org"},
+		OrganisationID: &orgURI,
+	}))
+	created := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	for _, art := range []*models.ApiArtifact{
+		{Kind: "postman", Filename: "postman.json", ContentType: "application/json", Data: []byte(`{"info":{}}`)},
+		{Kind: "oas", Version: "3.1", Format: "json", Source: "original", Filename: "openapi.json", ContentType: "application/json", Data: []byte(`{"openapi":"3.1.0"}`)},
+	} {
+		art.ID = uuid.NewString()
+		art.ApiID = apiID
+		art.CreatedAt = created
+		require.NoError(t, env.repo.SaveArtifact(ctx, art))
+	}
+
+	revalidate := func(t *testing.T, path string) string {
+		t.Helper()
+		resp := env.doRequest(t, http.MethodGet, path)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		etag := resp.Header.Get("ETag")
+		require.NotEmpty(t, etag)
+		require.NotEmpty(t, readRawBody(t, resp))
+
+		resp = env.doRequestWithHeaders(t, http.MethodGet, path, map[string]string{"If-None-Match": etag})
+		require.Equal(t, http.StatusNotModified, resp.StatusCode)
+		require.Equal(t, etag, resp.Header.Get("ETag"))
+		require.Empty(t, readRawBody(t, resp))
+		return etag
+	}
+
+	t.Run("artifacts", func(t *testing.T) {
+		for _, path := range []string{"/v1/apis/" + apiID + "/postman", "/v1/apis/" + apiID + "/oas/3.1.json"} {
+			revalidate(t, path)
+
+			resp := env.doRequestWithHeaders(t, http.MethodGet, path, map[string]string{"If-Modified-Since": created.Format(http.TimeFormat)})
+			require.Equal(t, http.StatusNotModified, resp.StatusCode)
+			require.Equal(t, "max-age=300", resp.Header.Get("Cache-Control"))
+			require.Equal(t, created.Format(http.TimeFormat), resp.Header.Get("Last-Modified"))
+			require.NoError(t, resp.Body.Close())
+
+			resp = env.doRequestWithHeaders(t, http.MethodGet, path, map[string]string{"If-Modified-Since": created.Add(-time.Hour).Format(http.TimeFormat)})
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.NoError(t, resp.Body.Close())
+		}
+
+		resp := env.doRequest(t, http.MethodGet, "/v1/apis/onbekend/postman")
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		require.Empty(t, resp.Header.Get("Cache-Control"), "fouten worden niet gecachet")
+		require.NoError(t, resp.Body.Close())
+	})
+
+	t.Run("api detail follows the revision", func(t *testing.T) {
+		path := "/v1/apis/" + apiID
+		etag := revalidate(t, path)
+
+		resp := env.doJSONRequestWithHeaders(t, http.MethodPatch, path, map[string]any{"docsUrl": "https://register.example.com/docs"}, map[string]string{
+			"Content-Type": models.MergePatchContentType,
+			"If-Match":     etag,
+		})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+
+		resp = env.doRequestWithHeaders(t, http.MethodGet, path, map[string]string{"If-None-Match": etag})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+		require.NoError(t, resp.Body.Close())
+	})
+
+	t.Run("api detail has an etag per representation", func(t *testing.T) {
+		path := "/v1/apis/" + apiID
+		etags := map[string]bool{}
+		for _, variant := range []struct {
+			query  string
+			accept string
+		}{
+			{query: ""},
+			{query: "?fields=title"},
+			{query: "?expand=servers"},
+			{accept: "application/ld+json"},
+		} {
+			headers := map[string]string{}
+			if variant.accept != "" {
+				headers["Accept"] = variant.accept
+			}
+			resp := env.doRequestWithHeaders(t, http.MethodGet, path+variant.query, headers)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.NoError(t, resp.Body.Close())
+			etag := resp.Header.Get("ETag")
+			require.False(t, etags[etag], "ETag %s is al gebruikt door een andere representatie", etag)
+			etags[etag] = true
+
+			// De ETag van de ene representatie geeft geen 304 op een andere.
+			other := "?fields=id"
+			if variant.query == other {
+				other = ""
+			}
+			resp = env.doRequestWithHeaders(t, http.MethodGet, path+other, map[string]string{"If-None-Match": etag})
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.NoError(t, resp.Body.Close())
+		}
+
+		// Dezelfde fields in een andere volgorde zijn dezelfde representatie.
+		resp := env.doRequest(t, http.MethodGet, path+"?fields=title,docsUrl")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+		resp2 := env.doRequest(t, http.MethodGet, path+"?fields=docsUrl,%20title")
+		require.Equal(t, http.StatusOK, resp2.StatusCode)
+		require.NoError(t, resp2.Body.Close())
+		require.Equal(t, resp.Header.Get("ETag"), resp2.Header.Get("ETag"))
+	})
+
+	t.Run("list changes when an organisation is renamed", func(t *testing.T) {
+		path := "/v1/apis?organisation=" + url.QueryEscape(orgURI)
+		etag := revalidate(t, path)
+
+		resp := env.doJSONRequest(t, http.MethodPut, "/v1/organisations/"+url.PathEscape(orgURI), map[string]any{"label": "Nieuwe naam"})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+
+		resp = env.doRequestWithHeaders(t, http.MethodGet, path, map[string]string{"If-None-Match": etag})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Contains(t, string(readRawBody(t, resp)), "Nieuwe naam")
+	})
+}
+
+func TestListAndSearchEndpoints_Sort(t *testing.T) {
+	env := newIntegrationEnv(t)
+
+	orgURI := "https://voorbeelden.example.com/organisaties/sort-" + uuid.NewString()
+	require.NoError(t, env.repo.SaveOrganisatie(&models.Organisation{Uri: orgURI, Label: "Sorteer Org"}))
+	for i, score := range []int{60, 95, 80} {
+		score := score
+		require.NoError(t, env.repo.Save(&models.Api{
+			Id:             uuid.NewString(),
+			Title:          fmt.Sprintf("Sorteerbare API %d", i),
+			OasUri:         fmt.Sprintf("https://voorbeelden.example.com/apis/sort-%s.json", uuid.NewString()),
+			AdrScore:       &score,
+			OrganisationID: &orgURI,
+		}))
+	}
+	org := url.QueryEscape(orgURI)
+
+	for _, path := range []string{
+		"/v1/apis?organisation=" + org + "&perPage=2&sort=-adrScore",
+		"/v1/apis/_search?q=sorteerbare&organisation=" + org + "&perPage=2&sort=-adrScore",
+	} {
+		resp := env.doRequest(t, http.MethodGet, path)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Contains(t, resp.Header.Get("Link"), "sort=-adrScore")
+		require.Contains(t, resp.Header.Get("Link"), `rel="next"`)
+		apis := decodeBody[[]models.ApiSummary](t, resp)
+		require.Len(t, apis, 2)
+		require.Equal(t, 95, *apis[0].AdrScore)
+		require.Equal(t, 80, *apis[1].AdrScore)
+	}
+
+	for _, path := range []string{"/v1/apis?sort=oasUrl", "/v1/apis/_search?q=sorteerbare&sort=-"} {
+		resp := env.doRequest(t, http.MethodGet, path)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		prob := decodeBody[problem.APIError](t, resp)
+		require.Equal(t, "sort", prob.Errors[0].Location)
+	}
+}
+
+func TestListEndpoints_CursorPagination(t *testing.T) {
+	env := newIntegrationEnv(t)
+	ctx := context.Background()
+
+	orgURI := "https://voorbeelden.example.com/organisaties/cursor-" + uuid.NewString()
+	require.NoError(t, env.repo.SaveOrganisatie(&models.Organisation{Uri: orgURI, Label: "Cursor Org"}))
+	for _, label := range []string{"Cursor Org B", "Cursor Org C"} {
+		require.NoError(t, env.repo.SaveOrganisatie(&models.Organisation{Uri: orgURI + "/" + label, Label: label}))
+	}
+	var apiIDs []string
+	for i := 0; i < 3; i++ {
+		id := uuid.NewString()
+		apiIDs = append(apiIDs, id)
+		require.NoError(t, env.repo.Save(&models.Api{
+			Id:             id,
+			Title:          fmt.Sprintf("Cursorbare API %d", i),
+			OasUri:         fmt.Sprintf("https://voorbeelden.example.com/apis/cursor-%s.json", id),
+			OrganisationID: &orgURI,
+		}))
+		require.NoError(t, env.repo.SaveLintResult(ctx, &models.LintResult{ID: uuid.NewString(), ApiID: id, CreatedAt: time.Now()}))
+	}
+	org := url.QueryEscape(orgURI)
+
+	nextLink := func(t *testing.T, resp *http.Response) string {
+		t.Helper()
+		match := regexp.MustCompile(`<([^>]+)>; rel="next"`).FindStringSubmatch(resp.Header.Get("Link"))
+		if match == nil {
+			return ""
+		}
+		u, err := url.Parse(match[1])
+		require.NoError(t, err)
+		require.Empty(t, u.Query().Get("page"))
+		require.Equal(t, "2", u.Query().Get("limit"))
+		return u.RequestURI()
+	}
+
+	for _, path := range []string{
+		"/v1/apis?organisation=" + org + "&limit=2",
+		"/v1/apis/_search?q=cursorbare&organisation=" + org + "&limit=2",
+	} {
+		var titles []string
+		for path != "" {
+			resp := env.doRequest(t, http.MethodGet, path)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, "3", resp.Header.Get("Total-Count"))
+			require.Empty(t, resp.Header.Get("Total-Pages"))
+			path = nextLink(t, resp)
+			for _, api := range decodeBody[[]models.ApiSummary](t, resp) {
+				titles = append(titles, api.Title)
+			}
+		}
+		require.Equal(t, []string{"Cursorbare API 0", "Cursorbare API 1", "Cursorbare API 2"}, titles)
+	}
+
+	resp := env.doRequest(t, http.MethodGet, "/v1/organisations?limit=2")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NotEmpty(t, nextLink(t, resp))
+
+	resp = env.doRequest(t, http.MethodGet, "/v1/lint-results?limit=2")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, decodeBody[[]models.LintResult](t, resp), 2)
+	next := nextLink(t, resp)
+	require.NotEmpty(t, next)
+	resp = env.doRequest(t, http.MethodGet, next)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = env.doRequest(t, http.MethodGet, "/v1/lint-results?perPage=2")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "1", resp.Header.Get("Current-Page"), "zonder cursor en limit wordt per pagina gepagineerd")
+	require.Len(t, decodeBody[[]models.LintResult](t, resp), 2)
+
+	cursor := models.Cursor{Sort: "title", ID: apiIDs[0]}.Encode()
+	for _, path := range []string{
+		"/v1/apis?cursor=" + cursor + "&sort=-adrScore",
+		"/v1/organisations?cursor=" + cursor,
+		"/v1/lint-results?cursor=kapot",
+	} {
+		resp := env.doRequest(t, http.MethodGet, path)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode, path)
+		prob := decodeBody[problem.APIError](t, resp)
+		require.Equal(t, "cursor", prob.Errors[0].Location)
+	}
+}
+
+func TestApiEndpoints_FieldsAndExpand(t *testing.T) {
+	env := newIntegrationEnv(t)
+	ctx := context.Background()
+
+	orgURI := "https://voorbeelden.example.com/organisaties/expand-" + uuid.NewString()
+	require.NoError(t, env.repo.SaveOrganisatie(&models.Organisation{Uri: orgURI, Label: "Expand Org"}))
+	apiID := uuid.NewString()
+	require.NoError(t, env.repo.Save(&models.Api{
+		Id:             apiID,
+		Title:          "Uitbreidbare API",
+		OasUri:         "https://voorbeelden.example.com/apis/expand-" + apiID + ".json",
+		Auth:           "oauth2",
+		OrganisationID: &orgURI,
+		Servers:        []models.Server{{Id: uuid.NewString(), Uri: "https://api.example.com/" + apiID}},
+	}))
+	for i := 0; i < 2; i++ {
+		require.NoError(t, env.repo.SaveLintResult(ctx, &models.LintResult{
+			ID: uuid.NewString(), ApiID: apiID, CreatedAt: time.Now().Add(time.Duration(i) * time.Minute),
+		}))
+	}
+	org := url.QueryEscape(orgURI)
+
+	t.Run("list with fields and expand", func(t *testing.T) {
+		resp := env.doRequest(t, http.MethodGet, "/v1/apis?organisation="+org+"&fields=title,auth,servers,lintResults&expand=servers,lintResults")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		items := decodeBody[[]map[string]json.RawMessage](t, resp)
+		require.Len(t, items, 1)
+		require.ElementsMatch(t, []string{"id", "title", "auth", "servers", "lintResults"}, slices.Collect(maps.Keys(items[0])))
+		var lint []models.LintResult
+		require.NoError(t, json.Unmarshal(items[0]["lintResults"], &lint))
+		require.Len(t, lint, 1, "lintResults geeft alleen het laatste resultaat")
+	})
+
+	t.Run("list without organisation expand", func(t *testing.T) {
+		resp := env.doRequest(t, http.MethodGet, "/v1/apis?organisation="+org+"&expand=none")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		items := decodeBody[[]models.ApiSummary](t, resp)
+		require.Len(t, items, 1)
+		require.Equal(t, orgURI, items[0].Organisation.Uri)
+		require.Empty(t, items[0].Organisation.Label)
+	})
+
+	t.Run("detail defaults and expand", func(t *testing.T) {
+		resp := env.doRequest(t, http.MethodGet, "/v1/apis/"+apiID)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		detail := decodeBody[models.ApiDetail](t, resp)
+		require.Len(t, detail.LintResults, 2)
+		require.Len(t, detail.Servers, 1)
+		require.Equal(t, "Expand Org", detail.Organisation.Label)
+
+		resp = env.doRequest(t, http.MethodGet, "/v1/apis/"+apiID+"?expand=lintResults&fields=lintResults")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		body := decodeBody[map[string]json.RawMessage](t, resp)
+		require.ElementsMatch(t, []string{"id", "lintResults"}, slices.Collect(maps.Keys(body)))
+	})
+
+	for _, tc := range []struct{ path, param string }{
+		{"/v1/apis?fields=oasVersion", "fields"},
+		{"/v1/apis/_search?q=uitbreidbare&expand=owner", "expand"},
+		{"/v1/apis/" + apiID + "?expand=lintResults:some", "expand"},
+	} {
+		resp := env.doRequest(t, http.MethodGet, tc.path)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode, tc.path)
+		prob := decodeBody[problem.APIError](t, resp)
+		require.Equal(t, tc.param, prob.Errors[0].Location)
+	}
+}
+
+func TestChangeFeed_TimestampsAndUpdatedSince(t *testing.T) {
+	// De feed wacht normaal een paar seconden op nog lopende inserts.
+	t.Setenv("CHANGES_SETTLE_DELAY", "0")
+	issuer := testutil.NewTokenIssuer(t, "https://auth.example.com/realms/don", "api-register")
+	authenticator, err := auth.NewAuthenticator(auth.Config{
+		Issuer:   issuer.Issuer,
+		Audience: issuer.Audience,
+		JWKSFile: issuer.JWKSFile,
+	})
+	require.NoError(t, err)
+	env := newIntegrationEnv(t, api_client.WithAuthenticator(authenticator))
+
+	orgURI := "https://voorbeelden.example.com/organisaties/" + uuid.NewString()
+	owner := map[string]string{
+		"Authorization": "Bearer " + issuer.OrganisationToken(t, "eigenaar", []string{orgURI}, "apis:write"),
+		"Content-Type":  models.MergePatchContentType,
+	}
+	admin := map[string]string{"Authorization": "Bearer " + issuer.Token(t, "beheer", "admin")}
+
+	// Lees de feed tot het einde, zodat alleen de wijzigingen van deze test
+	// volgen.
+	since := ""
+	for {
+		resp := env.doRequest(t, http.MethodGet, "/v1/changes?limit=1000&since="+since)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		feed := decodeBody[models.ChangeFeed](t, resp)
+		require.NotEmpty(t, feed.Next)
+		since = feed.Next
+		if !feed.HasMore {
+			break
+		}
+	}
+
+	apiID := uuid.NewString()
+	require.NoError(t, env.repo.Save(&models.Api{
+		Id:             apiID,
+		Title:          "Gevolgde API",
+		OasUri:         "https://voorbeelden.example.com/apis/" + apiID + "/openapi.json",
+		Organisation:   &models.Organisation{Uri: orgURI, Label: "Feed Org"},
+		OrganisationID: &orgURI,
+		Version:        "1.0.0",
+	}))
+	listPath := "/v1/apis?ids=" + apiID + "&updatedSince="
+
+	resp := env.doJSONRequestWithHeaders(t, http.MethodPatch, "/v1/apis/"+apiID,
+		map[string]any{"sunset": "2031-06-30"}, env.ifMatch(t, apiID, owner))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	patched := decodeBody[models.ApiSummary](t, resp)
+	require.NotNil(t, patched.CreatedAt)
+	require.NotNil(t, patched.UpdatedAt)
+
+	resp = env.doRequest(t, http.MethodGet, listPath+url.QueryEscape(patched.UpdatedAt.Format(time.RFC3339)))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	listed := decodeBody[[]models.ApiSummary](t, resp)
+	require.Len(t, listed, 1)
+	require.Equal(t, patched.UpdatedAt.Unix(), listed[0].UpdatedAt.Unix())
+
+	future := patched.UpdatedAt.Add(time.Hour).Format(time.RFC3339)
+	resp = env.doRequest(t, http.MethodGet, listPath+url.QueryEscape(future))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Empty(t, decodeBody[[]models.ApiSummary](t, resp))
+
+	resp = env.doRequest(t, http.MethodGet, listPath+"gisteren")
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	resp = env.doRequestWithHeaders(t, http.MethodDelete, "/v1/apis/"+apiID, owner)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+	resp = env.doRequestWithHeaders(t, http.MethodPost, "/v1/apis/"+apiID+"/restore", admin)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	var types []string
+	for {
+		resp := env.doRequest(t, http.MethodGet, "/v1/changes?limit=1&since="+since)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		feed := decodeBody[models.ChangeFeed](t, resp)
+		require.LessOrEqual(t, len(feed.Changes), 1)
+		for _, change := range feed.Changes {
+			if change.ApiID == apiID {
+				types = append(types, change.Type)
+			}
+		}
+		since = feed.Next
+		if !feed.HasMore {
+			break
+		}
+	}
+	require.Equal(t, []string{
+		models.ApiChangeLifecycleChanged,
+		models.ApiChangeDeleted,
+		models.ApiChangeCreated,
+	}, types)
+
+	resp = env.doRequest(t, http.MethodGet, "/v1/changes?since="+since)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	feed := decodeBody[models.ChangeFeed](t, resp)
+	require.Empty(t, feed.Changes)
+	require.Equal(t, since, feed.Next)
+
+	resp = env.doRequest(t, http.MethodGet, "/v1/changes?since=niet-geldig")
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+}
+
+func TestArazzoEndpoints(t *testing.T) {
+	env := newIntegrationEnv(t)
+	ctx := context.Background()
+
+	org, err := env.service.CreateOrganisation(ctx, &models.Organisation{
+		Uri:   "https://voorbeelden.example.com/organisaties/" + uuid.NewString(),
+		Label: "Arazzo Org",
+	})
+	require.NoError(t, err)
+
+	oasSrv := testutil.NewTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+  "openapi": "3.0.0",
+  "info": { "title": "Arazzo API", "version": "1.0.0", "contact": { "name": "Team", "email": "team@example.com", "url": "https://example.com" } },
+  "paths": { "/ping-` + r.URL.Path[1:] + `": { "get": { "operationId": "ping", "responses": { "200": { "description": "pong" } } } } }
+}`))
+	}))
+	arazzo := `arazzo: 1.0.1
+info:
+  title: Pingen
+  version: 1.0.0
+sourceDescriptions:
+  - name: api
+    url: openapi.json
+workflows:
+  - workflowId: ping
+    steps:
+      - stepId: ping
+        operationId: ping
+`
+
+	resp := env.doJSONRequest(t, http.MethodPost, "/v1/apis", map[string]any{
+		"oasUrl":          oasSrv.URL + "/" + uuid.NewString(),
+		"organisationUri": org.Uri,
+		"arazzoBody":      "arazzo: 2.0.0",
+	})
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	prob := decodeBody[problem.APIError](t, resp)
+	require.Equal(t, "arazzoBody", prob.Errors[0].Location)
+	require.Contains(t, prob.Errors[0].Detail, "unsupported Arazzo version")
+
+	resp = env.doJSONRequest(t, http.MethodPost, "/v1/apis", map[string]any{
+		"oasUrl":          oasSrv.URL + "/" + uuid.NewString(),
+		"organisationUri": org.Uri,
+		"arazzoBody":      arazzo,
+	})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	apiID := decodeBody[models.ApiSummary](t, resp).Id
+	_, err = env.service.ApproveApi(ctx, apiID)
+	require.NoError(t, err)
+
+	base := "/v1/apis/" + apiID
+	require.Eventually(t, func() bool {
+		resp := env.doRequest(t, http.MethodGet, base+"/arazzo?format=json")
+		defer resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 30*time.Second, 100*time.Millisecond)
+
+	resp = env.doRequest(t, http.MethodGet, base+"/arazzo")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/yaml", resp.Header.Get("Content-Type"))
+	require.Equal(t, "1.0.1", resp.Header.Get("Arazzo-Version"))
+	require.Equal(t, strings.TrimSpace(arazzo), string(readRawBody(t, resp)))
+
+	resp = env.doRequest(t, http.MethodGet, base+"/arazzo?format=json")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Contains(t, resp.Header.Get("Content-Disposition"), `filename="arazzo.json"`)
+	require.Equal(t, "ping", decodeBody[map[string]any](t, resp)["workflows"].([]any)[0].(map[string]any)["workflowId"])
+
+	resp = env.doRequest(t, http.MethodGet, base+"/arazzo?format=xml")
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	resp = env.doRequest(t, http.MethodGet, base+"/arazzo/mermaid")
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	require.NoError(t, env.repo.SaveArtifact(ctx, &models.ApiArtifact{
+		ID:          uuid.NewString(),
+		ApiID:       apiID,
+		Kind:        "arazzo_markdown",
+		Format:      "markdown",
+		Filename:    "arazzo.md",
+		ContentType: "text/markdown; charset=utf-8",
+		Data:        []byte("# Pingen"),
+		CreatedAt:   time.Now(),
+	}))
+	resp = env.doRequest(t, http.MethodGet, base+"/arazzo/markdown")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "# Pingen", string(readRawBody(t, resp)))
+
+	resp = env.doRequest(t, http.MethodGet, base)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	links := decodeBody[models.ApiDetail](t, resp).Links
+	require.NotNil(t, links)
+	require.Equal(t, []models.Link{
+		{Href: base + "/arazzo?format=json", Name: "json", Type: "application/json"},
+		{Href: base + "/arazzo?format=yaml", Name: "yaml", Type: "application/yaml"},
+	}, links.Arazzo)
+	require.Equal(t, &models.Link{Href: base + "/arazzo/markdown", Type: "text/markdown; charset=utf-8"}, links.ArazzoMarkdown)
+	require.Nil(t, links.ArazzoMermaid)
+}
+
+func TestArtifactCatalogue(t *testing.T) {
+	env := newIntegrationEnv(t)
+	ctx := context.Background()
+
+	orgURI := "https://voorbeelden.example.com/organisaties/" + uuid.NewString()
+	apiID := uuid.NewString()
+	require.NoError(t, env.repo.Save(&models.Api{
+		Id:             apiID,
+		OasUri:         "https://voorbeelden.example.com/apis/" + apiID + "/openapi.json",
+		Title:          "Artifact API",
+		OrganisationID: &orgURI,
+		Organisation:   &models.Organisation{Uri: orgURI, Label: "Artifact Org"},
+	}))
+	base := "/v1/apis/" + apiID
+
+	resp := env.doRequest(t, http.MethodGet, base+"/artifacts")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Empty(t, decodeBody[[]models.ApiArtifact](t, resp))
+
+	saved := map[string]models.ApiArtifact{}
+	for _, art := range []models.ApiArtifact{
+		{Kind: "bruno", Filename: "bruno.zip", ContentType: "application/zip", Data: []byte("PK")},
+		{Kind: "oas", Version: "3.1", Format: "json", Source: "converted", Filename: "openapi.json", ContentType: "application/json", Data: []byte(`{"openapi":"3.1.0"}`)},
+		{Kind: "arazzo_mermaid", Format: "mermaid", Filename: "arazzo.mmd", ContentType: "text/plain; charset=utf-8", Data: []byte("flowchart TD")},
+	} {
+		art.ID = uuid.NewString()
+		art.ApiID = apiID
+		art.CreatedAt = time.Now()
+		require.NoError(t, env.repo.SaveArtifact(ctx, &art))
+		saved[art.ID] = art
+	}
+
+	resp = env.doRequest(t, http.MethodGet, base+"/artifacts")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	list := decodeBody[[]models.ApiArtifact](t, resp)
+	require.Len(t, list, len(saved))
+	for _, art := range list {
+		want := saved[art.ID]
+		require.Equal(t, want.Kind, art.Kind)
+		require.Equal(t, want.Filename, art.Filename)
+		require.Equal(t, int64(len(want.Data)), art.Size)
+		sum := sha256.Sum256(want.Data)
+		require.Equal(t, hex.EncodeToString(sum[:]), art.Checksum)
+
+		resp := env.doRequest(t, http.MethodGet, base+"/artifacts/"+art.ID)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, want.ContentType, resp.Header.Get("Content-Type"))
+		require.Contains(t, resp.Header.Get("Content-Disposition"), `filename="`+want.Filename+`"`)
+		require.Equal(t, want.Data, readRawBody(t, resp))
+	}
+
+	resp = env.doRequest(t, http.MethodGet, "/v1/apis/"+uuid.NewString()+"/artifacts")
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	resp = env.doRequest(t, http.MethodGet, "/v1/apis/"+uuid.NewString()+"/artifacts/"+list[0].ID)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	resp = env.doRequest(t, http.MethodGet, base+"/artifacts/"+uuid.NewString())
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	prob := decodeBody[problem.APIError](t, resp)
+	require.Contains(t, prob.Errors[0].Detail, "Artifact not found")
+
+	// Een artifact van een API die op review wacht of verwijderd is, wordt
+	// niet meer uitgeleverd.
+	api, err := env.repo.GetApiByID(ctx, apiID)
+	require.NoError(t, err)
+	api.ReviewStatus = models.ReviewStatusPending
+	require.NoError(t, env.repo.UpdateApi(ctx, *api))
+	resp = env.doRequest(t, http.MethodGet, base+"/artifacts/"+list[0].ID)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	api, err = env.repo.GetApiByID(ctx, apiID)
+	require.NoError(t, err)
+	api.ReviewStatus = models.ReviewStatusApproved
+	require.NoError(t, env.repo.UpdateApi(ctx, *api))
+	resp = env.doRequest(t, http.MethodGet, base+"/artifacts/"+list[0].ID)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	require.NoError(t, env.repo.DeleteApi(ctx, apiID))
+	resp = env.doRequest(t, http.MethodGet, base+"/artifacts/"+list[0].ID)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+}
+
+func TestApiLintResultsEndpoints(t *testing.T) {
+	env := newIntegrationEnv(t)
+	ctx := context.Background()
+
+	orgURI := "https://voorbeelden.example.com/organisaties/" + uuid.NewString()
+	apiID := uuid.NewString()
+	require.NoError(t, env.repo.Save(&models.Api{
+		Id:             apiID,
+		OasUri:         "https://voorbeelden.example.com/apis/" + apiID + "/openapi.json",
+		Title:          "Lint API",
+		OrganisationID: &orgURI,
+		Organisation:   &models.Organisation{Uri: orgURI, Label: "Lint Org"},
+	}))
+	base := "/v1/apis/" + apiID
+
+	resp := env.doRequest(t, http.MethodGet, base+"/lint-results/latest")
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	older := time.Date(2026, 9, 1, 7, 0, 0, 0, time.UTC)
+	newer := older.Add(24 * time.Hour)
+	for _, res := range []models.LintResult{
+		{ID: uuid.NewString(), Failures: 1, CreatedAt: older, Messages: []models.LintMessage{
+			{ID: uuid.NewString(), Severity: "error", Code: "nlgov:paths-kebab-case", RulesetVersion: "2026.04"},
+		}},
+		{ID: uuid.NewString(), Warnings: 1, CreatedAt: newer, Messages: []models.LintMessage{
+			{ID: uuid.NewString(), Severity: "warning", Code: "nlgov:info-contact", RulesetVersion: "2026.05"},
+		}},
+	} {
+		res.ApiID = apiID
+		require.NoError(t, env.repo.SaveLintResult(ctx, &res))
+	}
+
+	resp = env.doRequest(t, http.MethodGet, base+"/lint-results?perPage=1")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "2", resp.Header.Get("Total-Count"))
+	require.Contains(t, resp.Header.Get("Link"), `rel="next"`)
+	results := decodeBody[[]models.LintResult](t, resp)
+	require.Len(t, results, 1)
+	require.Equal(t, 1, results[0].Warnings)
+
+	resp = env.doRequest(t, http.MethodGet, base+"/lint-results?severity=error")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	results = decodeBody[[]models.LintResult](t, resp)
+	require.Len(t, results, 1)
+	require.Equal(t, "nlgov:paths-kebab-case", results[0].Messages[0].Code)
+
+	resp = env.doRequest(t, http.MethodGet, base+"/lint-results?rulesetVersion=2026.05&since="+newer.Format(time.RFC3339))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, decodeBody[[]models.LintResult](t, resp), 1)
+
+	resp = env.doRequest(t, http.MethodGet, base+"/lint-results?until="+older.Format(time.RFC3339))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Empty(t, decodeBody[[]models.LintResult](t, resp))
+
+	resp = env.doRequest(t, http.MethodGet, base+"/lint-results?since=gisteren")
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.Equal(t, "since", decodeBody[problem.APIError](t, resp).Errors[0].Location)
+
+	resp = env.doRequest(t, http.MethodGet, base+"/lint-results/latest")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	latest := decodeBody[models.LintResult](t, resp)
+	require.True(t, latest.CreatedAt.Equal(newer))
+	require.Len(t, latest.Messages, 1)
+
+	resp = env.doRequest(t, http.MethodGet, "/v1/lint-results?apiId="+apiID+"&since="+newer.Format(time.RFC3339))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	results = decodeBody[[]models.LintResult](t, resp)
+	require.Len(t, results, 1)
+	require.Equal(t, apiID, results[0].ApiID)
+
+	resp = env.doRequest(t, http.MethodGet, "/v1/apis/"+uuid.NewString()+"/lint-results")
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+}
+
+func TestArtifactDownloads_HiddenWhileUnreviewed(t *testing.T) {
+	env := newIntegrationEnv(t)
+	ctx := context.Background()
+
+	orgURI := "https://voorbeelden.example.com/organisaties/" + uuid.NewString()
+	for _, status := range []string{models.ReviewStatusPending, models.ReviewStatusRejected} {
+		apiID := uuid.NewString()
+		require.NoError(t, env.repo.Save(&models.Api{
+			Id:             apiID,
+			OasUri:         "https://voorbeelden.example.com/apis/" + apiID + "/openapi.json",
+			Title:          "Ongereviewde API",
+			OrganisationID: &orgURI,
+			Organisation:   &models.Organisation{Uri: orgURI, Label: "Review Org"},
+			ReviewStatus:   status,
+		}))
+		require.NoError(t, env.repo.SaveArtifact(ctx, &models.ApiArtifact{
+			ID: uuid.NewString(), ApiID: apiID, Kind: "oas", Version: "3.0", Format: "json", Source: "original",
+			Filename: "openapi.json", ContentType: "application/json", Data: []byte(`{}`), CreatedAt: time.Now(),
+		}))
+
+		resp := env.doRequest(t, http.MethodGet, "/v1/apis/"+apiID+"/oas/3.0.json")
+		require.Equal(t, http.StatusNotFound, resp.StatusCode, status)
+		require.NoError(t, resp.Body.Close())
+	}
+}
@@ -0,0 +1,52 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+type DeletedApiPurger interface {
+	PurgeDeletedApis(ctx context.Context) (int, error)
+}
+
+// ScheduleDeletedApiPurge ruimt dagelijks verwijderde APIs op waarvan de
+// retentieperiode verstreken is, en direct bij opstart.
+func ScheduleDeletedApiPurge(ctx context.Context, svc DeletedApiPurger) *cron.Cron {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	run := func(parent context.Context, label string) {
+		jobCtx, cancel := context.WithTimeout(parent, 5*time.Minute)
+		defer cancel()
+		n, err := svc.PurgeDeletedApis(jobCtx)
+		if err != nil {
+			fmt.Printf("[%s] failed: %v\n", label, err)
+			return
+		}
+		fmt.Printf("[%s] %d api(s) definitief verwijderd\n", label, n)
+	}
+
+	c := cron.New(cron.WithChain(
+		cron.Recover(cron.DefaultLogger),
+		cron.SkipIfStillRunning(cron.DefaultLogger),
+	))
+	if _, err := c.AddFunc("0 3 * * *", func() { run(context.Background(), "purge") }); err != nil {
+		fmt.Printf("failed to schedule purge: %v\n", err)
+		return c
+	}
+	c.Start()
+
+	// directe run bij opstart
+	go run(ctx, "initial purge")
+
+	// stoppen als context sluit
+	go func() {
+		<-ctx.Done()
+		c.Stop()
+	}()
+	return c
+}
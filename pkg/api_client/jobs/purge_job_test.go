@@ -0,0 +1,49 @@
+package jobs_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/jobs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type purgerStub struct {
+	callCh chan bool
+}
+
+func (s *purgerStub) PurgeDeletedApis(ctx context.Context) (int, error) {
+	_, hasDeadline := ctx.Deadline()
+	s.callCh <- hasDeadline
+	return 0, nil
+}
+
+func waitForPurge(t *testing.T, ch <-chan bool) bool {
+	t.Helper()
+
+	select {
+	case hasDeadline := <-ch:
+		return hasDeadline
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for purge call")
+		return false
+	}
+}
+
+func TestScheduleDeletedApiPurge_RunsOnStartupAndOnSchedule(t *testing.T) {
+	stub := &purgerStub{callCh: make(chan bool, 2)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := jobs.ScheduleDeletedApiPurge(ctx, stub)
+	require.NotNil(t, c)
+	assert.True(t, waitForPurge(t, stub.callCh))
+
+	entries := c.Entries()
+	require.Len(t, entries, 1)
+	entries[0].Job.Run()
+	assert.True(t, waitForPurge(t, stub.callCh))
+}
@@ -11,15 +11,25 @@ package models
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"strconv"
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type Api struct {
-	Id             string        `gorm:"column:id;primaryKey"`
-	OasUri         string        `json:"oasUrl,omitempty"`
-	OasHash        string        `json:"-" gorm:"column:oas_hash"`
-	OAS            OASMetadata   `gorm:"embedded;embeddedPrefix:oas_" json:"-"`
+	Id      string      `gorm:"column:id;primaryKey"`
+	OasUri  string      `json:"oasUrl,omitempty"`
+	OasHash string      `json:"-" gorm:"column:oas_hash"`
+	OAS     OASMetadata `gorm:"embedded;embeddedPrefix:oas_" json:"-"`
+	// ArazzoUri is de URL van het Arazzo-document; de dagelijkse refresh haalt
+	// hem opnieuw op. ArazzoHash herkent een gewijzigd document.
+	ArazzoUri      string        `gorm:"column:arazzo_uri" json:"arazzoUrl,omitempty"`
+	ArazzoHash     string        `gorm:"column:arazzo_hash" json:"-" audit:"-"`
 	DocsUrl        string        `json:"docsUrl,omitempty"`
 	Title          string        `json:"title,omitempty"`
 	Description    string        `json:"description,omitempty"`
@@ -35,6 +45,92 @@ type Api struct {
 	Version        string        `json:"version,omitempty"`
 	Sunset         string        `json:"sunset,omitempty"`
 	Deprecated     string        `json:"deprecated,omitempty"`
+	// ReplacesID en ReplacedByID koppelen een API aan zijn voorganger en
+	// opvolger, bv. wanneer de OAS naar een nieuwe URL is verhuisd.
+	ReplacesID   *string `gorm:"column:replaces_id;index" json:"replaces,omitempty"`
+	ReplacedByID *string `gorm:"column:replaced_by_id;index" json:"replacedBy,omitempty"`
+	// Overrides bevat de waarden die via PATCH in het register zijn gezet. Ze
+	// gaan voor op wat de OAS zegt, ook na een refresh.
+	Overrides ApiOverrides `gorm:"column:overrides;type:text;serializer:json" json:"-"`
+	// ReviewStatus geeft aan of een curator de registratie heeft goedgekeurd.
+	// Alleen goedgekeurde APIs zijn publiek zichtbaar.
+	ReviewStatus string     `gorm:"column:review_status;index;default:approved" json:"reviewStatus,omitempty"`
+	ReviewReason string     `gorm:"column:review_reason" json:"reviewReason,omitempty"`
+	ReviewedBy   string     `gorm:"column:reviewed_by" json:"reviewedBy,omitempty"`
+	ReviewedAt   *time.Time `gorm:"column:reviewed_at" json:"reviewedAt,omitempty"`
+	// Revision wordt bij elke wijziging opgehoogd. Een update slaagt alleen als
+	// de revisie sinds het laden niet is veranderd; GET geeft hem als ETag.
+	// De audit trail negeert hem: een nieuwe revisie is geen inhoudelijke
+	// wijziging.
+	Revision int64 `gorm:"column:revision;not null;default:1" json:"-" audit:"-"`
+	// CreatedAt, UpdatedAt en LastOASChangeAt houdt de service bij. Ze zijn
+	// leeg voor APIs die sinds de invoering van deze velden niet zijn
+	// gewijzigd of waarvan de OAS sindsdien niet is veranderd.
+	CreatedAt       *time.Time `gorm:"column:created_at;index" json:"-" audit:"-"`
+	UpdatedAt       *time.Time `gorm:"column:updated_at;index" json:"-" audit:"-"`
+	LastOASChangeAt *time.Time `gorm:"column:last_oas_change_at" json:"-" audit:"-"`
+	// DeletedAt markeert een verwijderde API; gorm sluit deze rijen standaard
+	// uit van alle queries. Tot de retentieperiode verstreken is kan een
+	// beheerder de API herstellen.
+	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at;index" json:"-"`
+	// LintResults en Artifacts vult de repository alleen als ze met expand
+	// zijn opgevraagd.
+	LintResults []LintResult  `gorm:"-" json:"-" audit:"-"`
+	Artifacts   []ApiArtifact `gorm:"-" json:"-" audit:"-"`
+}
+
+// ApiOverrides zijn door het register beheerde waarden; nil betekent dat de
+// waarde uit de OAS komt.
+type ApiOverrides struct {
+	ContactName   *string `json:"contactName,omitempty"`
+	ContactUrl    *string `json:"contactUrl,omitempty"`
+	ContactEmail  *string `json:"contactEmail,omitempty"`
+	DocsUrl       *string `json:"docsUrl,omitempty"`
+	RepositoryUri *string `json:"repositoryUri,omitempty"`
+	Sunset        *string `json:"sunset,omitempty"`
+	Deprecated    *string `json:"deprecated,omitempty"`
+}
+
+// Reviewstatussen van een registratie.
+const (
+	ReviewStatusPending  = "pending"
+	ReviewStatusApproved = "approved"
+	ReviewStatusRejected = "rejected"
+)
+
+// IsApproved meldt of de API publiek zichtbaar mag zijn. APIs van vóór de
+// review-workflow hebben geen status en gelden als goedgekeurd.
+func (a Api) IsApproved() bool {
+	return a.ReviewStatus == "" || a.ReviewStatus == ReviewStatusApproved
+}
+
+// RevisionETag geeft de strong ETag voor een revisie van een API.
+func RevisionETag(revision int64) string {
+	return `"` + strconv.FormatInt(revision, 10) + `"`
+}
+
+// RepresentationETag geeft de strong ETag van één representatie van een
+// revisie. De revisie staat voorop, zodat If-Match hem herkent; de hash van
+// variant onderscheidt mediatype, fields en expand.
+func RepresentationETag(revision int64, variant ...string) string {
+	h := sha256.New()
+	for _, part := range variant {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return `"` + strconv.FormatInt(revision, 10) + "-" + hex.EncodeToString(h.Sum(nil))[:16] + `"`
+}
+
+// ETagRevision haalt de revisie uit een strong ETag van RevisionETag of
+// RepresentationETag.
+func ETagRevision(tag string) (int64, bool) {
+	tag = strings.TrimSpace(tag)
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+	raw, _, _ := strings.Cut(tag[1:len(tag)-1], "-")
+	revision, err := strconv.ParseInt(raw, 10, 64)
+	return revision, err == nil
 }
 
 type OASMetadata struct {
@@ -64,6 +160,10 @@ type Server struct {
 // Link representeert een hypermedia‐link
 type Link struct {
 	Href string `json:"href"`
+	// Name en Type onderscheiden links met dezelfde relatie, bv. de
+	// OAS-documenten per versie en formaat.
+	Name string `json:"name,omitempty"`
+	Type string `json:"type,omitempty"`
 }
 type Links struct {
 	First *Link `json:"first,omitempty"`
@@ -72,6 +172,16 @@ type Links struct {
 	Next  *Link `json:"next,omitempty"`
 	Last  *Link `json:"last,omitempty"`
 	Apis  *Link `json:"apis,omitempty"` // link naar de lijst van APIs
+	// Replaces en ReplacedBy verwijzen naar de voorganger en opvolger van een API.
+	Replaces   *Link `json:"replaces,omitempty"`
+	ReplacedBy *Link `json:"replacedBy,omitempty"`
+	// Downloads van de beschikbare artifacts van een API.
+	Oas            []Link `json:"oas,omitempty"`
+	Postman        *Link  `json:"postman,omitempty"`
+	Bruno          *Link  `json:"bruno,omitempty"`
+	Arazzo         []Link `json:"arazzo,omitempty"`
+	ArazzoMarkdown *Link  `json:"arazzoMarkdown,omitempty"`
+	ArazzoMermaid  *Link  `json:"arazzoMermaid,omitempty"`
 }
 
 // Contact bundelt de contactgegevens
@@ -156,6 +266,11 @@ type Pagination struct {
 	RecordsPerPage int
 	TotalPages     int
 	TotalRecords   int
+	// NextCursor wijst naar de volgende pagina; leeg op de laatste pagina.
+	NextCursor string
+	// Cursor geeft aan dat de request met een cursor pagineerde; de Link
+	// header bevat dan cursors in plaats van paginanummers.
+	Cursor bool
 }
 type ApiSummary struct {
 	Id           string              `json:"id"`
@@ -167,6 +282,30 @@ type ApiSummary struct {
 	AdrScore     *int                `json:"adrScore"`
 	Links        *Links              `json:"_links,omitempty"`
 	Lifecycle    Lifecycle           `json:"lifecycle"`
+	// Review is alleen gevuld zolang de registratie niet is goedgekeurd.
+	Review          *Review    `json:"review,omitempty"`
+	CreatedAt       *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt       *time.Time `json:"updatedAt,omitempty"`
+	LastOASChangeAt *time.Time `json:"lastOasChangeAt,omitempty"`
+	// Revision wordt als ETag-header meegestuurd, niet in de body.
+	Revision int64 `json:"-"`
+	// Onderstaande velden zijn alleen gevuld in de detailweergave, met fields
+	// of met expand.
+	Auth        []string      `json:"auth,omitempty"`
+	DocsUrl     string        `json:"docsUrl,omitempty"`
+	Servers     []ServerInfo  `json:"servers,omitempty"`
+	LintResults []LintResult  `json:"lintResults,omitempty"`
+	Artifacts   []ApiArtifact `json:"artifacts,omitempty"`
+
+	fields []string
+}
+
+// Review beschrijft de beoordeling van een registratie door een curator.
+type Review struct {
+	Status     string     `json:"status"`
+	Reason     string     `json:"reason,omitempty"`
+	ReviewedBy string     `json:"reviewedBy,omitempty"`
+	ReviewedAt *time.Time `json:"reviewedAt,omitempty"`
 }
 
 type ServerInfo struct {
@@ -174,13 +313,11 @@ type ServerInfo struct {
 	Description string `json:"description,omitempty"`
 }
 
+// ApiDetail is een ApiSummary met de velden die alleen in de detailweergave
+// staan; de JSON komt van ApiSummary.
 type ApiDetail struct {
-	ApiSummary               // embed alles van ApiSummary
-	Auth        []string     `json:"auth,omitempty"`
-	DocsUrl     string       `json:"docsUrl,omitempty"`
-	Servers     []ServerInfo `json:"servers,omitempty"`
-	LintResults []LintResult `json:"lintResults,omitempty"`
-	OasVersion  string       `json:"-"`
+	ApiSummary        // embed alles van ApiSummary
+	OasVersion string `json:"-"`
 }
 
 type ContactJsonLd struct {
@@ -199,6 +336,8 @@ type ApiDetailJsonLd struct {
 	EndpointDescription string          `json:"dcat:endpointDescription,omitempty"`
 	ContactPoint        ContactJsonLd   `json:"dcat:contactPoint"`
 	Publisher           string          `json:"dct:publisher,omitempty"`
+	Replaces            string          `json:"dct:replaces,omitempty"`
+	IsReplacedBy        string          `json:"dct:isReplacedBy,omitempty"`
 }
 
 type ApiPost struct {
@@ -209,19 +348,39 @@ type ApiPost struct {
 	ArazzoBody      string  `json:"arazzoBody,omitempty"`
 	OrganisationUri string  `json:"organisationUri" binding:"required,url"`
 	Contact         Contact `json:"contact"`
+	// Replaces is het id van de API die door deze registratie wordt opgevolgd.
+	Replaces string `json:"replaces,omitempty"`
 }
 
 type ApiParams struct {
 	Id string `path:"id"`
 }
 
+// RetrieveApiParams zijn de parameters van GET /apis/{id}.
+type RetrieveApiParams struct {
+	Id     string `path:"id"`
+	Fields string `query:"fields"`
+	Expand string `query:"expand"`
+}
+
 type ApiOasParams struct {
 	Id      string `path:"id"`
 	Version string `path:"version"`
 }
 
+type ApiArtifactParams struct {
+	Id         string `path:"id"`
+	ArtifactId string `path:"artifactId"`
+}
+
+type ApiArazzoParams struct {
+	Id     string `path:"id"`
+	Format string `query:"format"`
+}
+
 type UpdateApiInput struct {
 	Id              string         `path:"id"` // <-- uit path param
+	IfMatch         string         `header:"If-Match" json:"-"`
 	OasUrl          string         `json:"oasUrl" binding:"required_without_all=OasBody Sunset Deprecated,omitempty,url"`
 	OasBody         string         `json:"oasBody,omitempty" binding:"required_without_all=OasUrl Sunset Deprecated"`
 	ArazzoUrl       string         `json:"arazzoUrl,omitempty"`
@@ -232,8 +391,10 @@ type UpdateApiInput struct {
 	Deprecated      OptionalString `json:"deprecated,omitempty"`
 }
 
+// OrganisationSummary verwijst naar een organisatie. Label ontbreekt als de
+// organisatie bij een API niet is ingesloten.
 type OrganisationSummary struct {
 	Uri   string `json:"uri"`
-	Label string `json:"label"`
+	Label string `json:"label,omitempty"`
 	Links *Links `json:"_links,omitempty"`
 }
@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
 
 // ApiArtifact stores generated artifacts
 // associated with an API. Content is stored as a blob.
@@ -13,6 +17,15 @@ type ApiArtifact struct {
 	Source      string    `gorm:"column:source;index" json:"source,omitempty"` // bv. original / derived
 	Filename    string    `gorm:"column:filename" json:"filename"`
 	ContentType string    `gorm:"column:content_type" json:"contentType"`
+	Size        int64     `gorm:"column:size" json:"size"`                   // aantal bytes van Data
+	Checksum    string    `gorm:"column:checksum" json:"checksum,omitempty"` // hex SHA-256 van Data
 	Data        []byte    `gorm:"column:data;type:bytea" json:"-"`
 	CreatedAt   time.Time `gorm:"column:created_at" json:"createdAt"`
 }
+
+// SetDigest vult Size en Checksum op basis van data.
+func (a *ApiArtifact) SetDigest(data []byte) {
+	sum := sha256.Sum256(data)
+	a.Size = int64(len(data))
+	a.Checksum = hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// ApiKey is een read-only sleutel voor de publieke endpoints. Alleen de
+// SHA-256 hash van de sleutel wordt opgeslagen; de sleutel zelf wordt
+// eenmalig teruggegeven bij het aanmaken.
+type ApiKey struct {
+	ID           string `gorm:"column:id;primaryKey" json:"id"`
+	Prefix       string `gorm:"column:prefix;index" json:"prefix"`
+	Hash         string `gorm:"column:hash;uniqueIndex" json:"-"`
+	ConsumerName string `gorm:"column:consumer_name" json:"consumerName"`
+	ContactName  string `gorm:"column:contact_name" json:"contactName,omitempty"`
+	ContactEmail string `gorm:"column:contact_email" json:"contactEmail"`
+	// OrganisationUri koppelt de sleutel aan de organisatie van de afnemer.
+	OrganisationUri string     `gorm:"column:organisation_uri" json:"organisationUri,omitempty"`
+	UsageCount      int64      `gorm:"column:usage_count;not null;default:0" json:"usageCount"`
+	LastUsedAt      *time.Time `gorm:"column:last_used_at" json:"lastUsedAt,omitempty"`
+	CreatedAt       time.Time  `gorm:"column:created_at" json:"createdAt"`
+	RevokedAt       *time.Time `gorm:"column:revoked_at" json:"revokedAt,omitempty"`
+}
+
+// Active geeft aan of de sleutel nog niet is ingetrokken.
+func (k ApiKey) Active() bool {
+	return k.RevokedAt == nil
+}
+
+type ApiKeyInput struct {
+	ConsumerName    string `json:"consumerName" binding:"required"`
+	ContactName     string `json:"contactName,omitempty"`
+	ContactEmail    string `json:"contactEmail" binding:"required,email"`
+	OrganisationUri string `json:"organisationUri,omitempty" binding:"omitempty,url"`
+}
+
+// ApiKeyCreated bevat de nieuw aangemaakte sleutel inclusief de platte tekst.
+type ApiKeyCreated struct {
+	ApiKey
+	Key string `json:"key"`
+}
+
+type ApiKeyParams struct {
+	Id string `path:"id"`
+}
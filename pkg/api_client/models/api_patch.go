@@ -0,0 +1,85 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// MergePatchContentType is het mediatype van een JSON Merge Patch (RFC 7396).
+const MergePatchContentType = "application/merge-patch+json"
+
+// ApiPatch is een JSON Merge Patch op de velden die het register zelf beheert.
+// Een waarde zet een override, null verwijdert hem weer.
+type ApiPatch struct {
+	Id            string         `json:"-" path:"id"`
+	IfMatch       string         `json:"-" header:"If-Match"`
+	Contact       ContactPatch   `json:"contact"`
+	DocsUrl       OptionalString `json:"docsUrl"`
+	RepositoryUri OptionalString `json:"repositoryUri"`
+	Sunset        OptionalString `json:"sunset"`
+	Deprecated    OptionalString `json:"deprecated"`
+
+	unknown []string
+}
+
+// ContactPatch is het contact-deel van een ApiPatch; "contact": null
+// verwijdert alle contact-overrides.
+type ContactPatch struct {
+	Name  OptionalString `json:"name"`
+	URL   OptionalString `json:"url"`
+	Email OptionalString `json:"email"`
+}
+
+var patchableApiFields = map[string]bool{
+	"contact":       true,
+	"docsUrl":       true,
+	"repositoryUri": true,
+	"sunset":        true,
+	"deprecated":    true,
+}
+
+func (p *ApiPatch) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	type alias ApiPatch
+	aux := alias{Id: p.Id}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	*p = ApiPatch(aux)
+	for name := range fields {
+		if !patchableApiFields[name] {
+			p.unknown = append(p.unknown, name)
+		}
+	}
+	sort.Strings(p.unknown)
+	return nil
+}
+
+// UnknownFields geeft de velden in de patch die niet via PATCH te wijzigen zijn.
+func (p ApiPatch) UnknownFields() []string {
+	return p.unknown
+}
+
+// IsEmpty meldt of de patch geen enkel veld wijzigt.
+func (p ApiPatch) IsEmpty() bool {
+	return !p.Contact.Name.Set && !p.Contact.URL.Set && !p.Contact.Email.Set &&
+		!p.DocsUrl.Set && !p.RepositoryUri.Set && !p.Sunset.Set && !p.Deprecated.Set
+}
+
+func (c *ContactPatch) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		*c = ContactPatch{Name: NewNullString(), URL: NewNullString(), Email: NewNullString()}
+		return nil
+	}
+	type alias ContactPatch
+	var aux alias
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	*c = ContactPatch(aux)
+	return nil
+}
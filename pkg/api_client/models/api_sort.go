@@ -0,0 +1,58 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Sorteervelden voor GET /apis en /apis/_search. Met een "-" ervoor wordt
+// aflopend gesorteerd, bijvoorbeeld sort=-adrScore.
+const (
+	ApiSortTitle        = "title"
+	ApiSortAdrScore     = "adrScore"
+	ApiSortOrganisation = "organisation"
+	ApiSortStatus       = "status"
+	ApiSortSunset       = "sunset"
+	ApiSortLastUpdated  = "lastUpdated"
+)
+
+// ApiSortFields zijn de toegestane waarden van de sort-parameter, zonder "-".
+var ApiSortFields = []string{
+	ApiSortTitle,
+	ApiSortAdrScore,
+	ApiSortOrganisation,
+	ApiSortStatus,
+	ApiSortSunset,
+	ApiSortLastUpdated,
+}
+
+// ApiSort is een gevalideerde sortering. De nulwaarde sorteert op titel.
+type ApiSort struct {
+	Field string
+	Desc  bool
+}
+
+// ParseApiSort valideert de sort-parameter. Een lege waarde sorteert op titel.
+func ParseApiSort(raw string) (ApiSort, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ApiSort{Field: ApiSortTitle}, nil
+	}
+	sort := ApiSort{Field: raw}
+	if strings.HasPrefix(raw, "-") {
+		sort = ApiSort{Field: raw[1:], Desc: true}
+	}
+	for _, field := range ApiSortFields {
+		if sort.Field == field {
+			return sort, nil
+		}
+	}
+	return ApiSort{}, fmt.Errorf("moet een van %s zijn, eventueel met - ervoor voor aflopend", strings.Join(ApiSortFields, ", "))
+}
+
+func (s ApiSort) String() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+	return s.Field
+}
@@ -0,0 +1,37 @@
+package models
+
+import "testing"
+
+func TestParseApiSort(t *testing.T) {
+	tests := []struct {
+		raw     string
+		expect  ApiSort
+		wantErr bool
+	}{
+		{raw: "", expect: ApiSort{Field: ApiSortTitle}},
+		{raw: "adrScore", expect: ApiSort{Field: ApiSortAdrScore}},
+		{raw: " -lastUpdated ", expect: ApiSort{Field: ApiSortLastUpdated, Desc: true}},
+		{raw: "-", wantErr: true},
+		{raw: "AdrScore", wantErr: true},
+		{raw: "oasUrl", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		current := tc
+		t.Run(current.raw, func(t *testing.T) {
+			got, err := ParseApiSort(current.raw)
+			if current.wantErr {
+				if err == nil {
+					t.Fatalf("expected error for %q", current.raw)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != current.expect {
+				t.Fatalf("expected %+v, got %+v", current.expect, got)
+			}
+		})
+	}
+}
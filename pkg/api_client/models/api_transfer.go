@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Statussen van een eigendomsoverdracht.
+const (
+	ApiTransferPending   = "pending"
+	ApiTransferCompleted = "completed"
+)
+
+// ApiTransfer legt de overdracht van een API tussen twee organisaties vast.
+// Een overdracht is voltooid zodra beide organisaties (of een beheerder) hebben
+// ingestemd; voltooide overdrachten zorgen dat filters op de oude organisatie
+// de API blijven vinden.
+type ApiTransfer struct {
+	ID                  string     `gorm:"column:id;primaryKey" json:"id"`
+	ApiID               string     `gorm:"column:api_id;index" json:"apiId"`
+	FromOrganisationUri string     `gorm:"column:from_organisation_uri;index" json:"fromOrganisationUri"`
+	ToOrganisationUri   string     `gorm:"column:to_organisation_uri;index" json:"toOrganisationUri"`
+	Status              string     `gorm:"column:status;index" json:"status"`
+	ApprovedByFrom      string     `gorm:"column:approved_by_from" json:"approvedByFrom,omitempty"`
+	ApprovedByTo        string     `gorm:"column:approved_by_to" json:"approvedByTo,omitempty"`
+	CreatedAt           time.Time  `gorm:"column:created_at" json:"createdAt"`
+	CompletedAt         *time.Time `gorm:"column:completed_at" json:"completedAt,omitempty"`
+}
+
+// ApiTransferInput vraagt de overdracht van een API naar een andere organisatie
+// aan, of stemt in met een openstaande aanvraag.
+type ApiTransferInput struct {
+	Id              string `json:"-" path:"id"`
+	OrganisationUri string `json:"organisationUri" binding:"required,url"`
+}
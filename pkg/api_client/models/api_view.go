@@ -0,0 +1,177 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Waarden van de expand-parameter op /apis, /apis/_search en /apis/{id}.
+// lintResults geeft alleen het laatste lintresultaat, lintResults:all de hele
+// historie; none sluit niets in.
+const (
+	ApiExpandNone           = "none"
+	ApiExpandServers        = "servers"
+	ApiExpandLintResults    = "lintResults"
+	ApiExpandLintResultsAll = "lintResults:all"
+	ApiExpandArtifacts      = "artifacts"
+	ApiExpandOrganisation   = "organisation"
+)
+
+// ApiExpandValues zijn de toegestane waarden van de expand-parameter.
+var ApiExpandValues = []string{
+	ApiExpandNone,
+	ApiExpandServers,
+	ApiExpandLintResults,
+	ApiExpandLintResultsAll,
+	ApiExpandArtifacts,
+	ApiExpandOrganisation,
+}
+
+// Hoeveel lintresultaten ApiExpand insluit.
+const (
+	LintResultsLatest = "latest"
+	LintResultsAll    = "all"
+)
+
+// ApiExpand bepaalt welke gerelateerde gegevens bij een API worden geladen en
+// ingesloten. Zonder Organisation bevat de organisatie alleen uri en links.
+type ApiExpand struct {
+	Servers      bool
+	Organisation bool
+	Artifacts    bool
+	// LintResults is leeg, LintResultsLatest of LintResultsAll.
+	LintResults string
+}
+
+// Standaard-expand zonder expand-parameter; gelijk aan de vorm van de lijst en
+// de detailweergave van voor expand bestond.
+var (
+	DefaultListExpand   = ApiExpand{Organisation: true}
+	DefaultDetailExpand = ApiExpand{Servers: true, Organisation: true, LintResults: LintResultsAll}
+)
+
+// ParseApiExpand valideert de komma-gescheiden expand-parameter. Zonder
+// waarde geldt def.
+func ParseApiExpand(raw string, def ApiExpand) (ApiExpand, error) {
+	values := splitList(raw)
+	if len(values) == 0 {
+		return def, nil
+	}
+	var expand ApiExpand
+	for _, value := range values {
+		switch value {
+		case ApiExpandNone:
+		case ApiExpandServers:
+			expand.Servers = true
+		case ApiExpandOrganisation:
+			expand.Organisation = true
+		case ApiExpandArtifacts:
+			expand.Artifacts = true
+		case ApiExpandLintResults:
+			if expand.LintResults == "" {
+				expand.LintResults = LintResultsLatest
+			}
+		case ApiExpandLintResultsAll:
+			expand.LintResults = LintResultsAll
+		default:
+			return ApiExpand{}, fmt.Errorf("onbekende waarde %q; moet een van %s zijn", value, strings.Join(ApiExpandValues, ", "))
+		}
+	}
+	return expand, nil
+}
+
+// ApiFields zijn de eigenschappen van een API die met fields te kiezen zijn,
+// in de volgorde waarin ze in de response staan.
+var ApiFields = []string{
+	"id", "oasUrl", "title", "description", "contact", "organisation", "adrScore",
+	"_links", "lifecycle", "review", "createdAt", "updatedAt", "lastOasChangeAt",
+	"auth", "docsUrl", "servers", "lintResults", "artifacts",
+}
+
+// ParseApiFields valideert de komma-gescheiden fields-parameter. Zonder
+// waarde geeft hij nil: alle eigenschappen. id zit er altijd bij.
+func ParseApiFields(raw string) ([]string, error) {
+	values := splitList(raw)
+	if len(values) == 0 {
+		return nil, nil
+	}
+	fields := []string{"id"}
+	for _, value := range values {
+		if !slices.Contains(ApiFields, value) {
+			return nil, fmt.Errorf("onbekend veld %q; moet een van %s zijn", value, strings.Join(ApiFields, ", "))
+		}
+		if !slices.Contains(fields, value) {
+			fields = append(fields, value)
+		}
+	}
+	return fields, nil
+}
+
+// ApiView is de gevraagde vorm van een API: de ingesloten gegevens en,
+// als Fields niet nil is, de eigenschappen die in de response komen.
+type ApiView struct {
+	Fields []string
+	Expand ApiExpand
+}
+
+// Key beschrijft de view genormaliseerd: dezelfde fields en expand in een
+// andere volgorde geven dezelfde sleutel.
+func (v ApiView) Key() string {
+	fields := "*"
+	if v.Fields != nil {
+		sorted := slices.Clone(v.Fields)
+		slices.Sort(sorted)
+		fields = strings.Join(sorted, ",")
+	}
+	return fmt.Sprintf("fields=%s;servers=%t;organisation=%t;artifacts=%t;lintResults=%s",
+		fields, v.Expand.Servers, v.Expand.Organisation, v.Expand.Artifacts, v.Expand.LintResults)
+}
+
+// SelectFields beperkt de JSON van s tot fields; nil geeft alle velden.
+func (s *ApiSummary) SelectFields(fields []string) {
+	s.fields = fields
+}
+
+// apiSummaryJSON heeft de velden van ApiSummary zonder MarshalJSON.
+type apiSummaryJSON ApiSummary
+
+func (s ApiSummary) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(apiSummaryJSON(s))
+	if err != nil || s.fields == nil {
+		return data, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for _, field := range ApiFields {
+		value, ok := all[field]
+		if !ok || !slices.Contains(s.fields, field) {
+			continue
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(field)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func splitList(raw string) []string {
+	var values []string
+	for _, value := range strings.Split(raw, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseApiExpand(t *testing.T) {
+	expand, err := ParseApiExpand("", DefaultDetailExpand)
+	if err != nil || expand != DefaultDetailExpand {
+		t.Fatalf("expected default, got %+v (%v)", expand, err)
+	}
+
+	expand, err = ParseApiExpand(" servers, lintResults ,artifacts", DefaultDetailExpand)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := ApiExpand{Servers: true, Artifacts: true, LintResults: LintResultsLatest}
+	if expand != want {
+		t.Fatalf("expected %+v, got %+v", want, expand)
+	}
+
+	if expand, _ = ParseApiExpand("lintResults:all,lintResults", ApiExpand{}); expand.LintResults != LintResultsAll {
+		t.Fatalf("expected all lint results, got %+v", expand)
+	}
+
+	if expand, _ = ParseApiExpand("none", DefaultListExpand); expand != (ApiExpand{}) {
+		t.Fatalf("expected nothing expanded, got %+v", expand)
+	}
+
+	if _, err = ParseApiExpand("owner", ApiExpand{}); err == nil {
+		t.Fatal("expected error for unknown expand")
+	}
+}
+
+func TestApiSummarySelectFields(t *testing.T) {
+	fields, err := ParseApiFields("title, adrScore,title")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := ParseApiFields("oasVersion"); err == nil {
+		t.Fatal("expected error for unknown field")
+	}
+	if fields, _ := ParseApiFields(" "); fields != nil {
+		t.Fatalf("expected nil fields, got %v", fields)
+	}
+
+	score := 80
+	summary := ApiSummary{Id: "api-1", Title: "Titel", AdrScore: &score, Description: "weg"}
+	summary.SelectFields(fields)
+	data, err := json.Marshal(ApiDetail{ApiSummary: summary})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := string(data); got != `{"id":"api-1","title":"Titel","adrScore":80}` {
+		t.Fatalf("unexpected json %s", got)
+	}
+}
@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// Audit-acties die in de audit trail worden vastgelegd.
+const (
+	AuditActionApiCreated          = "api.created"
+	AuditActionApiUpdated          = "api.updated"
+	AuditActionApiLifecycleChanged = "api.lifecycle_changed"
+	AuditActionApiPatched          = "api.patched"
+	AuditActionApiRefreshed        = "api.refreshed"
+	AuditActionApiDeleted          = "api.deleted"
+	AuditActionApiRestored         = "api.restored"
+	AuditActionApiReplaced         = "api.replaced"
+	AuditActionApiTransferred      = "api.transferred"
+	AuditActionApiApproved         = "api.approved"
+	AuditActionApiRejected         = "api.rejected"
+	AuditActionOrganisationCreated = "organisation.created"
+	AuditActionOrganisationUpdated = "organisation.updated"
+	AuditActionOrganisationDeleted = "organisation.deleted"
+	AuditActionOrganisationMerged  = "organisation.merged"
+)
+
+// AuditEntry legt vast wie wanneer welke wijziging heeft gedaan.
+type AuditEntry struct {
+	ID              string        `gorm:"column:id;primaryKey" json:"id"`
+	ApiID           *string       `gorm:"column:api_id;index" json:"apiId,omitempty"`
+	OrganisationUri string        `gorm:"column:organisation_uri;index" json:"organisationUri,omitempty"`
+	Actor           string        `gorm:"column:actor;index" json:"actor"`
+	Action          string        `gorm:"column:action;index" json:"action"`
+	Changes         []FieldChange `gorm:"column:changes;type:text;serializer:json" json:"changes"`
+	CreatedAt       time.Time     `gorm:"column:created_at;index" json:"createdAt"`
+}
+
+// FieldChange beschrijft de waarde van één veld voor en na een wijziging.
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+// AuditFilter beperkt de audit trail op API, organisatie, actor, actie en periode.
+type AuditFilter struct {
+	ApiID        string
+	Organisation string
+	Actor        string
+	Action       string
+	Since        *time.Time
+	Until        *time.Time
+}
+
+type ListAuditParams struct {
+	Page         int     `query:"page"`
+	PerPage      int     `query:"perPage"`
+	ApiId        *string `query:"apiId"`
+	Organisation *string `query:"organisation"`
+	Actor        *string `query:"actor"`
+	Action       *string `query:"action"`
+	Since        *string `query:"since" description:"RFC 3339 timestamp (inclusive)"`
+	Until        *string `query:"until" description:"RFC 3339 timestamp (exclusive)"`
+}
+
+type ApiAuditParams struct {
+	Id      string `path:"id"`
+	Page    int    `query:"page"`
+	PerPage int    `query:"perPage"`
+}
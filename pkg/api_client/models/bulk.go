@@ -0,0 +1,55 @@
+package models
+
+import (
+	"encoding/json"
+
+	problem "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/problem"
+)
+
+// Uitkomsten van één item in een bulkregistratie.
+const (
+	BulkStatusCreated     = "created"
+	BulkStatusExists      = "exists"
+	BulkStatusInvalid     = "invalid"
+	BulkStatusFetchFailed = "fetch_failed"
+	BulkStatusForbidden   = "forbidden"
+	BulkStatusFailed      = "failed"
+)
+
+// ApiBulkInput is de body van POST /apis/_bulk: een array van ApiPost-items.
+// Items worden afzonderlijk gevalideerd zodat één fout item de rest niet
+// blokkeert.
+type ApiBulkInput struct {
+	Items []ApiPost
+}
+
+func (in *ApiBulkInput) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &in.Items)
+}
+
+// ApiBulkResult is de uitkomst van één item, in de volgorde van de request.
+type ApiBulkResult struct {
+	Index         int                    `json:"index"`
+	OasUrl        string                 `json:"oasUrl,omitempty"`
+	Status        string                 `json:"status"`
+	HttpStatus    int                    `json:"httpStatus"`
+	Api           *ApiSummary            `json:"api,omitempty"`
+	Detail        string                 `json:"detail,omitempty"`
+	InvalidParams []problem.InvalidParam `json:"invalidParams,omitempty"`
+}
+
+// ApiBulkSummary telt de items per uitkomst.
+type ApiBulkSummary struct {
+	Total       int `json:"total"`
+	Created     int `json:"created"`
+	Exists      int `json:"exists"`
+	Invalid     int `json:"invalid"`
+	FetchFailed int `json:"fetchFailed"`
+	Forbidden   int `json:"forbidden"`
+	Failed      int `json:"failed"`
+}
+
+type ApiBulkReport struct {
+	Summary ApiBulkSummary  `json:"summary"`
+	Results []ApiBulkResult `json:"results"`
+}
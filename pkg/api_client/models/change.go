@@ -0,0 +1,64 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Soorten gebeurtenissen in de wijzigingsfeed.
+const (
+	ApiChangeCreated          = "created"
+	ApiChangeUpdated          = "updated"
+	ApiChangeLifecycleChanged = "lifecycleChanged"
+	ApiChangeDeleted          = "deleted"
+)
+
+// ApiChange is een gebeurtenis in de wijzigingsfeed van /changes. De feed
+// volgt alleen wat publiek zichtbaar is: een API die wordt goedgekeurd of
+// hersteld verschijnt als created, een API die verdwijnt als deleted.
+type ApiChange struct {
+	Seq        int64     `gorm:"column:seq;primaryKey;autoIncrement" json:"-"`
+	ApiID      string    `gorm:"column:api_id;index" json:"apiId"`
+	Type       string    `gorm:"column:type" json:"type"`
+	OccurredAt time.Time `gorm:"column:occurred_at" json:"occurredAt"`
+}
+
+// ListChangesParams zijn de queryparameters van GET /changes.
+type ListChangesParams struct {
+	Since string `query:"since"`
+	Limit int    `query:"limit"`
+}
+
+// ChangeFeed is een pagina van de wijzigingsfeed. Next is het token voor de
+// volgende aanroep, ook als er geen nieuwe wijzigingen zijn.
+type ChangeFeed struct {
+	Changes []ApiChange `json:"changes"`
+	Next    string      `json:"next"`
+	HasMore bool        `json:"hasMore"`
+}
+
+// EncodeChangeToken geeft de positie in de feed als opaque token.
+func EncodeChangeToken(seq int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("c" + strconv.FormatInt(seq, 10)))
+}
+
+// DecodeChangeToken leest een token uit EncodeChangeToken; leeg is het begin
+// van de feed.
+func DecodeChangeToken(raw string) (int64, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil || !strings.HasPrefix(string(data), "c") {
+		return 0, errors.New("token is niet leesbaar")
+	}
+	seq, err := strconv.ParseInt(string(data[1:]), 10, 64)
+	if err != nil || seq < 0 {
+		return 0, errors.New("token is niet leesbaar")
+	}
+	return seq, nil
+}
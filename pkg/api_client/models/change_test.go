@@ -0,0 +1,24 @@
+package models
+
+import "testing"
+
+func TestChangeTokenRoundTrip(t *testing.T) {
+	for _, seq := range []int64{0, 1, 4242} {
+		got, err := DecodeChangeToken(EncodeChangeToken(seq))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != seq {
+			t.Fatalf("expected %d, got %d", seq, got)
+		}
+	}
+
+	if got, err := DecodeChangeToken(""); err != nil || got != 0 {
+		t.Fatalf("expected start of feed, got %d, %v", got, err)
+	}
+	for _, raw := range []string{"niet-base64!", "MTI", "Yy0x"} {
+		if _, err := DecodeChangeToken(raw); err == nil {
+			t.Fatalf("expected error for %q", raw)
+		}
+	}
+}
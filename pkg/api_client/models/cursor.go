@@ -0,0 +1,58 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// Cursor is een positie in een gesorteerde lijst: de sorteerwaarde en het id
+// van het laatste item van de vorige pagina. De volgende pagina begint bij het
+// eerste item daarna, zodat nieuwe items de pagina's niet laten verschuiven.
+// Clients krijgen de cursor als opaque string en sturen hem ongewijzigd terug.
+type Cursor struct {
+	// Sort is de sortering waarbij de cursor hoort.
+	Sort    string `json:"o,omitempty"`
+	Missing bool   `json:"m,omitempty"`
+	Str     string `json:"s,omitempty"`
+	Num     int64  `json:"n,omitempty"`
+	Title   string `json:"t,omitempty"`
+	ID      string `json:"i"`
+}
+
+// Encode geeft de cursor als opaque string voor in een URL.
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor leest een cursor uit Encode.
+func DecodeCursor(raw string) (Cursor, error) {
+	var c Cursor
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(raw))
+	if err != nil {
+		return c, errors.New("cursor is niet leesbaar")
+	}
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == "" {
+		return c, errors.New("cursor is niet leesbaar")
+	}
+	return c, nil
+}
+
+// Vaste sorteringen van lijsten die niet op sort-parameter sorteren. Een
+// cursor van de ene lijst is zo niet bruikbaar in een andere.
+const (
+	OrganisationsCursorSort = "label"
+	LintResultsCursorSort   = "-createdAt"
+)
+
+// PageRequest bepaalt welke pagina een lijst teruggeeft: pagina Page van
+// PerPage items, of met After de PerPage items na die cursor.
+type PageRequest struct {
+	Page    int
+	PerPage int
+	After   *Cursor
+	// Cursor geeft aan dat de client met cursor en limit pagineert.
+	Cursor bool
+}
@@ -0,0 +1,20 @@
+package models
+
+import "testing"
+
+func TestCursorRoundTrip(t *testing.T) {
+	in := Cursor{Sort: "-adrScore", Num: 80, Title: "digid api", ID: "api-1"}
+	out, err := DecodeCursor(in.Encode())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != in {
+		t.Fatalf("expected %+v, got %+v", in, out)
+	}
+
+	for _, raw := range []string{"", "niet-base64!", "e30"} {
+		if _, err := DecodeCursor(raw); err == nil {
+			t.Fatalf("expected error for %q", raw)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package models
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type FilterOption struct {
 	Value       string  `json:"value"`
@@ -58,6 +61,8 @@ type ApiFiltersParams struct {
 	Version      []string `query:"version"`
 	AdrScore     []string `query:"adrScore"`
 	Auth         []string `query:"auth"`
+	// UpdatedSince wordt door de service uit updatedSince geparsed.
+	UpdatedSince *time.Time
 }
 
 var LifecycleStatusLabels = map[string][2]string{
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// IdempotencyRecord bewaart de response op een request met een
+// Idempotency-Key, zodat een herhaling van hetzelfde request dezelfde
+// response krijgt. Status 0 betekent dat het request nog wordt verwerkt.
+type IdempotencyRecord struct {
+	// ID is de hash van aanroeper, methode, pad en sleutel.
+	ID          string `gorm:"column:id;primaryKey"`
+	Fingerprint string `gorm:"column:fingerprint"`
+	Status      int    `gorm:"column:status;not null;default:0"`
+	ContentType string `gorm:"column:content_type"`
+	// Headers zijn de overige response-headers, zoals ETag en Location.
+	Headers   map[string][]string `gorm:"column:headers;type:text;serializer:json"`
+	Body      []byte              `gorm:"column:body"`
+	CreatedAt time.Time           `gorm:"column:created_at;index"`
+}
+
+// Completed geeft aan of de response al is opgeslagen.
+func (r IdempotencyRecord) Completed() bool {
+	return r.Status != 0
+}
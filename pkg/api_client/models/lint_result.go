@@ -16,6 +16,49 @@ type LintResult struct {
 	Messages  []LintMessage `gorm:"foreignKey:LintResultID" json:"messages,omitempty"`
 }
 
+// ListLintResultsParams zijn de query-parameters van GET /lint-results.
+// Zonder cursor en limit wordt gepagineerd met page en perPage.
+type ListLintResultsParams struct {
+	Page    int     `query:"page"`
+	PerPage int     `query:"perPage"`
+	Cursor  string  `query:"cursor"`
+	Limit   int     `query:"limit"`
+	ApiId   *string `query:"apiId"`
+	Since   *string `query:"since" description:"RFC 3339 timestamp (inclusive)"`
+	Until   *string `query:"until" description:"RFC 3339 timestamp (exclusive)"`
+}
+
+// ApiLintResultsParams zijn de parameters van GET /apis/:id/lint-results.
+type ApiLintResultsParams struct {
+	Id             string  `path:"id"`
+	Page           int     `query:"page"`
+	PerPage        int     `query:"perPage"`
+	Cursor         string  `query:"cursor"`
+	Limit          int     `query:"limit"`
+	Severity       *string `query:"severity" description:"Only results with a message of this severity, e.g. error or warning"`
+	Code           *string `query:"code" description:"Only results with a message for this rule code"`
+	RulesetVersion *string `query:"rulesetVersion" description:"Only results with a message from this ruleset version"`
+	Since          *string `query:"since" description:"RFC 3339 timestamp (inclusive)"`
+	Until          *string `query:"until" description:"RFC 3339 timestamp (exclusive)"`
+}
+
+// LintResultFilter beperkt een lijst lintresultaten. Met Severity, Code of
+// RulesetVersion komen alleen resultaten met een passende melding terug, en
+// van die resultaten alleen de passende meldingen.
+type LintResultFilter struct {
+	ApiID          string
+	Severity       string
+	Code           string
+	RulesetVersion string
+	Since          *time.Time
+	Until          *time.Time
+}
+
+// FiltersMessages geeft aan of het filter op meldingen selecteert.
+func (f LintResultFilter) FiltersMessages() bool {
+	return f.Severity != "" || f.Code != "" || f.RulesetVersion != ""
+}
+
 type LintMessage struct {
 	ID             string            `gorm:"column:id;primaryKey" json:"id"`
 	LintResultID   string            `gorm:"column:lint_result_id" json:"lintResultId"`
@@ -12,6 +12,12 @@ type ListApisParams struct {
 	Version      []string `query:"version"`
 	AdrScore     []string `query:"adrScore"`
 	Auth         []string `query:"auth"`
+	Sort         string   `query:"sort"`
+	Cursor       string   `query:"cursor"`
+	Limit        int      `query:"limit"`
+	Fields       string   `query:"fields"`
+	Expand       string   `query:"expand"`
+	UpdatedSince string   `query:"updatedSince"`
 	BaseURL      string
 }
 
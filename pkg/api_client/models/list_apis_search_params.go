@@ -5,5 +5,10 @@ type ListApisSearchParams struct {
 	PerPage      int     `query:"perPage"`
 	Organisation *string `query:"organisation"`
 	Query        string  `query:"q" binding:"required"`
+	Sort         string  `query:"sort"`
+	Cursor       string  `query:"cursor"`
+	Limit        int     `query:"limit"`
+	Fields       string  `query:"fields"`
+	Expand       string  `query:"expand"`
 	BaseURL      string
 }
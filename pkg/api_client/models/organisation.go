@@ -0,0 +1,63 @@
+package models
+
+import (
+	"net/url"
+	"strings"
+)
+
+type ListOrganisationsParams struct {
+	Page    int    `query:"page"`
+	PerPage int    `query:"perPage"`
+	Cursor  string `query:"cursor"`
+	Limit   int    `query:"limit"`
+}
+
+// OrganisationParams bevat de URI van een organisatie uit het pad. Omdat een
+// URI slashes bevat is dit een catch-all parameter; de URI mag zowel
+// ge-escaped als letterlijk worden meegegeven.
+type OrganisationParams struct {
+	Uri string `path:"uri"`
+}
+
+// OrganisationURI geeft de genormaliseerde URI uit het pad.
+func (p OrganisationParams) OrganisationURI() string {
+	return organisationURIFromPath(p.Uri)
+}
+
+type OrganisationUpdateInput struct {
+	Uri   string `path:"uri" json:"-"`
+	Label string `json:"label" binding:"required"`
+}
+
+// OrganisationURI geeft de genormaliseerde URI uit het pad.
+func (in OrganisationUpdateInput) OrganisationURI() string {
+	return organisationURIFromPath(in.Uri)
+}
+
+// OrganisationMergeInput voegt een dubbele organisatie samen met de canonieke.
+type OrganisationMergeInput struct {
+	DuplicateUri string `json:"duplicateUri" binding:"required,url"`
+	CanonicalUri string `json:"canonicalUri" binding:"required,url"`
+}
+
+// OrganisationApiCounts telt de APIs van een organisatie per lifecycle-status.
+type OrganisationApiCounts struct {
+	Total      int `json:"total"`
+	Active     int `json:"active"`
+	Deprecated int `json:"deprecated"`
+	Sunset     int `json:"sunset"`
+	Retired    int `json:"retired"`
+}
+
+type OrganisationDetail struct {
+	OrganisationSummary
+	ApiCounts OrganisationApiCounts `json:"apiCounts"`
+}
+
+func organisationURIFromPath(raw string) string {
+	raw = strings.TrimPrefix(strings.TrimSpace(raw), "/")
+	if unescaped, err := url.PathUnescape(raw); err == nil {
+		raw = unescaped
+	}
+	return raw
+}
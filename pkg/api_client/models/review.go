@@ -0,0 +1,40 @@
+package models
+
+import "encoding/json"
+
+type ListReviewsParams struct {
+	Page    int    `query:"page"`
+	PerPage int    `query:"perPage"`
+	Status  string `query:"status" description:"pending (default) or rejected"`
+}
+
+// ApiReviewItem is een registratie in de reviewwachtrij, met de lint-resultaten
+// en gegenereerde artifacts die een curator nodig heeft om te beoordelen.
+type ApiReviewItem struct {
+	ApiDetail
+	Artifacts []ApiArtifact `json:"artifacts"`
+}
+
+// apiReviewItemJSON is de JSON-vorm van ApiReviewItem. apiSummaryJSON heeft
+// geen MarshalJSON, dus de velden van de API komen inline; artifacts staat
+// hier ondieper en gaat daarom voor het veld van ApiSummary, dat een lege
+// lijst weglaat.
+type apiReviewItemJSON struct {
+	apiSummaryJSON
+	Artifacts []ApiArtifact `json:"artifacts"`
+}
+
+// MarshalJSON voegt artifacts, ook als lege lijst, toe aan de JSON van de API;
+// zonder deze methode zou die van ApiSummary het veld weglaten.
+func (i ApiReviewItem) MarshalJSON() ([]byte, error) {
+	return json.Marshal(apiReviewItemJSON{
+		apiSummaryJSON: apiSummaryJSON(i.ApiSummary),
+		Artifacts:      i.Artifacts,
+	})
+}
+
+// ApiRejectInput keurt een registratie af met een reden voor de indiener.
+type ApiRejectInput struct {
+	Id     string `json:"-" path:"id"`
+	Reason string `json:"reason" binding:"required"`
+}
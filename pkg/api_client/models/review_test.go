@@ -0,0 +1,40 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestApiReviewItemJSON_AlwaysHasArtifacts(t *testing.T) {
+	item := ApiReviewItem{
+		ApiDetail: ApiDetail{ApiSummary: ApiSummary{Id: "api-1", Title: "Titel"}},
+		Artifacts: []ApiArtifact{},
+	}
+	data, err := json.Marshal(item)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got map[string]json.RawMessage
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("invalid json %s: %v", data, err)
+	}
+	if string(got["id"]) != `"api-1"` || string(got["title"]) != `"Titel"` {
+		t.Fatalf("expected the api fields inline, got %s", data)
+	}
+	if string(got["artifacts"]) != `[]` {
+		t.Fatalf("expected an empty artifacts list, got %s", data)
+	}
+
+	item.Artifacts = []ApiArtifact{{ID: "art-1", Kind: "postman"}}
+	data, err = json.Marshal(item)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded ApiReviewItem
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("invalid json %s: %v", data, err)
+	}
+	if len(decoded.Artifacts) != 1 || decoded.Artifacts[0].ID != "art-1" {
+		t.Fatalf("expected one artifact, got %s", data)
+	}
+}
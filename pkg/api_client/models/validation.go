@@ -0,0 +1,24 @@
+package models
+
+import problem "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/problem"
+
+// ApiValidation is het resultaat van POST /apis/_validate: wat een registratie
+// met dezelfde input zou opleveren, zonder dat er iets wordt opgeslagen.
+type ApiValidation struct {
+	// Valid is true als de registratie zou slagen.
+	Valid           bool                   `json:"valid"`
+	Api             *ApiDetail             `json:"api"`
+	InvalidParams   []problem.InvalidParam `json:"invalidParams,omitempty"`
+	Auth            string                 `json:"auth,omitempty"`
+	LifecycleStatus string                 `json:"lifecycleStatus"`
+	Lint            *LintResult            `json:"lint,omitempty"`
+	// LintError is gevuld als de ADR-linter niet bereikbaar was.
+	LintError string       `json:"lintError,omitempty"`
+	Conflict  *ApiConflict `json:"conflict,omitempty"`
+}
+
+// ApiConflict beschrijft een bestaande registratie met dezelfde oasUrl.
+type ApiConflict struct {
+	Detail string      `json:"detail"`
+	Api    *ApiSummary `json:"api,omitempty"`
+}
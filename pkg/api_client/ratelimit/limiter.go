@@ -0,0 +1,272 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/auth"
+	problem "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/problem"
+	"github.com/gin-gonic/gin"
+)
+
+// Class onderscheidt de budgetten voor lezende en schrijvende requests.
+type Class string
+
+const (
+	Read  Class = "read"
+	Write Class = "write"
+	// AuthFailure telt mislukte authenticaties per IP-adres.
+	AuthFailure Class = "auth"
+)
+
+const (
+	defaultReadLimit        = 600
+	defaultWriteLimit       = 60
+	defaultAuthFailureLimit = 30
+	defaultWindow           = time.Minute
+)
+
+// ClassForMethod bepaalt het budget op basis van de HTTP-methode.
+func ClassForMethod(method string) Class {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return Read
+	default:
+		return Write
+	}
+}
+
+// Config bevat het aantal toegestane requests per client per venster. Een
+// limiet van 0 schakelt limitering voor die klasse uit.
+type Config struct {
+	ReadLimit        int
+	WriteLimit       int
+	AuthFailureLimit int
+	Window           time.Duration
+}
+
+// ConfigFromEnv leest RATE_LIMIT_READ, RATE_LIMIT_WRITE,
+// RATE_LIMIT_AUTH_FAILURES en RATE_LIMIT_WINDOW.
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		ReadLimit:        defaultReadLimit,
+		WriteLimit:       defaultWriteLimit,
+		AuthFailureLimit: defaultAuthFailureLimit,
+		Window:           defaultWindow,
+	}
+	var err error
+	if cfg.ReadLimit, err = intFromEnv("RATE_LIMIT_READ", cfg.ReadLimit); err != nil {
+		return cfg, err
+	}
+	if cfg.WriteLimit, err = intFromEnv("RATE_LIMIT_WRITE", cfg.WriteLimit); err != nil {
+		return cfg, err
+	}
+	if cfg.AuthFailureLimit, err = intFromEnv("RATE_LIMIT_AUTH_FAILURES", cfg.AuthFailureLimit); err != nil {
+		return cfg, err
+	}
+	if raw := strings.TrimSpace(os.Getenv("RATE_LIMIT_WINDOW")); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < time.Second {
+			return cfg, fmt.Errorf("ongeldige RATE_LIMIT_WINDOW: %q", raw)
+		}
+		cfg.Window = d
+	}
+	return cfg, nil
+}
+
+func intFromEnv(name string, def int) (int, error) {
+	raw := strings.TrimSpace(os.Getenv(name))
+	if raw == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return def, fmt.Errorf("ongeldige %s: %q", name, raw)
+	}
+	return n, nil
+}
+
+// TrustedProxiesFromEnv leest TRUSTED_PROXIES: een kommagescheiden lijst van
+// IP-adressen of CIDR-ranges van proxies waarvan X-Forwarded-For vertrouwd
+// wordt. Zonder lijst telt alleen het adres van de verbinding.
+func TrustedProxiesFromEnv() ([]string, error) {
+	var proxies []string
+	for _, raw := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		p := strings.TrimSpace(raw)
+		if p == "" {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(p); err != nil && net.ParseIP(p) == nil {
+			return nil, fmt.Errorf("ongeldige TRUSTED_PROXIES: %q", p)
+		}
+		proxies = append(proxies, p)
+	}
+	return proxies, nil
+}
+
+// Result beschrijft de stand van het budget na een request.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	Reset     time.Duration
+}
+
+type window struct {
+	start time.Time
+	count int
+}
+
+// Limiter telt requests per client en klasse in vaste vensters.
+type Limiter struct {
+	cfg Config
+	now func() time.Time
+
+	mu        sync.Mutex
+	windows   map[string]*window
+	lastSweep time.Time
+}
+
+// New maakt een Limiter op basis van cfg.
+func New(cfg Config) *Limiter {
+	if cfg.Window <= 0 {
+		cfg.Window = defaultWindow
+	}
+	return &Limiter{cfg: cfg, now: time.Now, windows: make(map[string]*window)}
+}
+
+func (l *Limiter) limit(class Class) int {
+	switch class {
+	case Write:
+		return l.cfg.WriteLimit
+	case AuthFailure:
+		return l.cfg.AuthFailureLimit
+	default:
+		return l.cfg.ReadLimit
+	}
+}
+
+// Allow registreert een request van client binnen class.
+func (l *Limiter) Allow(client string, class Class) Result {
+	return l.take(client, class, true)
+}
+
+// peek geeft de stand van het budget zonder de request mee te tellen.
+func (l *Limiter) peek(client string, class Class) Result {
+	return l.take(client, class, false)
+}
+
+func (l *Limiter) take(client string, class Class, count bool) Result {
+	limit := l.limit(class)
+	if limit <= 0 {
+		return Result{Allowed: true}
+	}
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	key := string(class) + "|" + client
+	w := l.windows[key]
+	if w == nil || now.Sub(w.start) >= l.cfg.Window {
+		w = &window{start: now}
+		l.windows[key] = w
+	}
+	reset := w.start.Add(l.cfg.Window).Sub(now)
+	if w.count >= limit {
+		return Result{Allowed: false, Limit: limit, Remaining: 0, Reset: reset}
+	}
+	if !count {
+		return Result{Allowed: true, Limit: limit, Remaining: limit - w.count, Reset: reset}
+	}
+	w.count++
+	return Result{Allowed: true, Limit: limit, Remaining: limit - w.count, Reset: reset}
+}
+
+// sweep verwijdert verlopen vensters zodat de map niet onbegrensd groeit.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.cfg.Window {
+		return
+	}
+	for key, w := range l.windows {
+		if now.Sub(w.start) >= l.cfg.Window {
+			delete(l.windows, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// Middleware limiteert de request op basis van de aanroeper: de API key of
+// het token-subject als er een principal is, anders het IP-adres. Draai hem
+// daarom na de authenticatie. Bij overschrijding volgt een 429 met Retry-After.
+func (l *Limiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		class := ClassForMethod(c.Request.Method)
+		l.respond(c, class, l.Allow(ClientKey(c), class))
+	}
+}
+
+// Guard voert authn uit en limiteert daarna de request met Middleware.
+// Mislukte authenticaties tellen per IP-adres mee voor een eigen budget; is
+// dat op, dan volgt een 429 nog vóór de authenticatie, zodat tokens en API
+// keys niet onbeperkt te proberen zijn. authn mag zelf geen c.Next() aanroepen.
+func (l *Limiter) Guard(authn gin.HandlerFunc) gin.HandlerFunc {
+	limit := l.Middleware()
+	return func(c *gin.Context) {
+		ip := ipKey(c)
+		if res := l.peek(ip, AuthFailure); !res.Allowed {
+			l.respond(c, AuthFailure, res)
+			return
+		}
+		authn(c)
+		if c.IsAborted() {
+			if c.Writer.Status() == http.StatusUnauthorized {
+				l.Allow(ip, AuthFailure)
+			}
+			return
+		}
+		limit(c)
+	}
+}
+
+// respond zet de RateLimit-headers en breekt de request af met een 429 als
+// het budget op is.
+func (l *Limiter) respond(c *gin.Context, class Class, res Result) {
+	if res.Limit == 0 {
+		return
+	}
+	reset := int((res.Reset + time.Second - 1) / time.Second)
+	h := c.Writer.Header()
+	h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(reset))
+	h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", res.Limit, int(l.cfg.Window/time.Second)))
+	if res.Allowed {
+		return
+	}
+	h.Set("Retry-After", strconv.Itoa(reset))
+	apiErr := problem.NewTooManyRequests(fmt.Sprintf("te veel %s-requests; probeer het over %d seconden opnieuw", class, reset))
+	c.Header("Content-Type", "application/problem+json")
+	c.AbortWithStatusJSON(apiErr.Status, apiErr)
+}
+
+// ClientKey identificeert de aanroeper voor het budget. Het IP-adres komt van
+// c.ClientIP(), dat X-Forwarded-For alleen volgt achter een vertrouwde proxy;
+// zie TrustedProxiesFromEnv.
+func ClientKey(c *gin.Context) string {
+	if id := auth.PrincipalFromContext(c.Request.Context()).ID(); id != "" {
+		return "principal:" + id
+	}
+	return ipKey(c)
+}
+
+func ipKey(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
@@ -0,0 +1,168 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/auth"
+	problem "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/problem"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLimiter(cfg Config) (*Limiter, *time.Time) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	l := New(cfg)
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestLimiter_SeparateBudgetsPerClientAndClass(t *testing.T) {
+	l, now := newTestLimiter(Config{ReadLimit: 2, WriteLimit: 1, Window: time.Minute})
+
+	assert.True(t, l.Allow("a", Read).Allowed)
+	res := l.Allow("a", Read)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+	assert.False(t, l.Allow("a", Read).Allowed)
+
+	assert.True(t, l.Allow("a", Write).Allowed, "write budget staat los van read")
+	assert.False(t, l.Allow("a", Write).Allowed)
+	assert.True(t, l.Allow("b", Read).Allowed, "andere client heeft eigen budget")
+
+	*now = now.Add(30 * time.Second)
+	res = l.Allow("a", Read)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 30*time.Second, res.Reset)
+
+	*now = now.Add(30 * time.Second)
+	assert.True(t, l.Allow("a", Read).Allowed, "nieuw venster")
+}
+
+func TestLimiter_ZeroLimitDisablesClass(t *testing.T) {
+	l, _ := newTestLimiter(Config{ReadLimit: 0, WriteLimit: 1, Window: time.Minute})
+	for i := 0; i < 10; i++ {
+		assert.True(t, l.Allow("a", Read).Allowed)
+	}
+}
+
+func TestMiddleware_HeadersAndTooManyRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	l, _ := newTestLimiter(Config{ReadLimit: 1, WriteLimit: 1, Window: time.Minute})
+
+	r := gin.New()
+	r.GET("/apis", func(c *gin.Context) {
+		if sub := c.GetHeader("X-Sub"); sub != "" {
+			auth.SetPrincipal(c, &auth.Principal{Subject: sub})
+		}
+	}, l.Middleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	do := func(sub string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/apis", nil)
+		if sub != "" {
+			req.Header.Set("X-Sub", sub)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("client-1")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "60", w.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "1;w=60", w.Header().Get("RateLimit-Policy"))
+
+	w = do("client-1")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+	var prob problem.APIError
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &prob))
+	assert.Equal(t, 429, prob.Status)
+
+	assert.Equal(t, http.StatusOK, do("client-2").Code, "budget per principal")
+	assert.Equal(t, http.StatusOK, do("").Code, "anoniem valt terug op IP")
+}
+
+func TestGuard_LimitsFailedAuthenticationPerIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	l, now := newTestLimiter(Config{ReadLimit: 100, WriteLimit: 100, AuthFailureLimit: 2, Window: time.Minute})
+
+	authn := func(c *gin.Context) {
+		switch c.GetHeader("Authorization") {
+		case "":
+		case "Bearer goed":
+			auth.SetPrincipal(c, &auth.Principal{Subject: "client-1"})
+		default:
+			c.AbortWithStatus(http.StatusUnauthorized)
+		}
+	}
+	r := gin.New()
+	r.GET("/apis", l.Guard(authn), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	do := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/apis", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusOK, do("goed").Code)
+	require.Equal(t, http.StatusUnauthorized, do("fout").Code)
+	require.Equal(t, http.StatusUnauthorized, do("fout").Code)
+
+	w := do("fout")
+	require.Equal(t, http.StatusTooManyRequests, w.Code, "budget voor mislukte pogingen is op")
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusTooManyRequests, do("goed").Code, "ook een geldig token wacht tot het venster voorbij is")
+	assert.Equal(t, http.StatusTooManyRequests, do("").Code)
+
+	*now = now.Add(time.Minute)
+	assert.Equal(t, http.StatusOK, do("goed").Code)
+}
+
+func TestClassForMethod(t *testing.T) {
+	assert.Equal(t, Read, ClassForMethod(http.MethodGet))
+	assert.Equal(t, Read, ClassForMethod(http.MethodHead))
+	assert.Equal(t, Write, ClassForMethod(http.MethodPost))
+	assert.Equal(t, Write, ClassForMethod(http.MethodPut))
+	assert.Equal(t, Write, ClassForMethod(http.MethodDelete))
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("RATE_LIMIT_READ", "100")
+	t.Setenv("RATE_LIMIT_WRITE", "0")
+	t.Setenv("RATE_LIMIT_WINDOW", "30s")
+	cfg, err := ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, Config{ReadLimit: 100, WriteLimit: 0, AuthFailureLimit: defaultAuthFailureLimit, Window: 30 * time.Second}, cfg)
+
+	t.Setenv("RATE_LIMIT_WRITE", "-1")
+	_, err = ConfigFromEnv()
+	assert.Error(t, err)
+}
+
+func TestTrustedProxiesFromEnv(t *testing.T) {
+	t.Setenv("TRUSTED_PROXIES", "")
+	proxies, err := TrustedProxiesFromEnv()
+	require.NoError(t, err)
+	assert.Empty(t, proxies)
+
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.10 ,")
+	proxies, err = TrustedProxiesFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.10"}, proxies)
+
+	t.Setenv("TRUSTED_PROXIES", "ingress")
+	_, err = TrustedProxiesFromEnv()
+	assert.Error(t, err)
+}
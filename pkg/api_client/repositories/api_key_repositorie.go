@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/models"
+	"gorm.io/gorm"
+)
+
+type ApiKeyRepository interface {
+	CreateApiKey(ctx context.Context, key *models.ApiKey) error
+	ListApiKeys(ctx context.Context) ([]models.ApiKey, error)
+	GetApiKey(ctx context.Context, id string) (*models.ApiKey, error)
+	FindApiKeyByHash(ctx context.Context, hash string) (*models.ApiKey, error)
+	RevokeApiKey(ctx context.Context, id string, at time.Time) error
+	RecordApiKeyUsage(ctx context.Context, id string, at time.Time) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewApiKeyRepository(db *gorm.DB) ApiKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) CreateApiKey(ctx context.Context, key *models.ApiKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+func (r *apiKeyRepository) ListApiKeys(ctx context.Context) ([]models.ApiKey, error) {
+	var keys []models.ApiKey
+	if err := r.db.WithContext(ctx).Order("created_at desc").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *apiKeyRepository) GetApiKey(ctx context.Context, id string) (*models.ApiKey, error) {
+	var key models.ApiKey
+	if err := r.db.WithContext(ctx).First(&key, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) FindApiKeyByHash(ctx context.Context, hash string) (*models.ApiKey, error) {
+	var key models.ApiKey
+	if err := r.db.WithContext(ctx).Where("hash = ?", hash).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) RevokeApiKey(ctx context.Context, id string, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.ApiKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at).Error
+}
+
+func (r *apiKeyRepository) RecordApiKeyUsage(ctx context.Context, id string, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.ApiKey{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"usage_count":  gorm.Expr("usage_count + 1"),
+			"last_used_at": at,
+		}).Error
+}
@@ -1,10 +1,12 @@
 package repositories
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"math"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -15,8 +17,9 @@ import (
 )
 
 type ApiRepository interface {
-	GetApis(ctx context.Context, page, perPage int, p *models.ApiFiltersParams) ([]models.Api, models.Pagination, error)
-	SearchApis(ctx context.Context, page, perPage int, organisation *string, query string) ([]models.Api, models.Pagination, error)
+	GetApis(ctx context.Context, page models.PageRequest, p *models.ApiFiltersParams, order models.ApiSort, expand models.ApiExpand) ([]models.Api, models.Pagination, error)
+	SearchApis(ctx context.Context, page models.PageRequest, organisation *string, query string, order models.ApiSort, expand models.ApiExpand) ([]models.Api, models.Pagination, error)
+	GetApiExpanded(ctx context.Context, id string, expand models.ApiExpand) (*models.Api, error)
 	GetApiByID(ctx context.Context, oasUrl string) (*models.Api, error)
 	Save(api *models.Api) error
 	UpdateApi(ctx context.Context, api models.Api) error
@@ -27,15 +30,38 @@ type ApiRepository interface {
 	AllApis(ctx context.Context) ([]models.Api, error)
 	SaveLintResult(ctx context.Context, result *models.LintResult) error
 	GetLintResults(ctx context.Context, apiID string) ([]models.LintResult, error)
-	ListLintResults(ctx context.Context) ([]models.LintResult, error)
-	GetOrganisations(ctx context.Context) ([]models.Organisation, int, error)
+	ListLintResults(ctx context.Context, page models.PageRequest, filter models.LintResultFilter) ([]models.LintResult, models.Pagination, error)
+	GetLatestLintResult(ctx context.Context, apiID string) (*models.LintResult, error)
+	GetOrganisations(ctx context.Context, page models.PageRequest) ([]models.Organisation, models.Pagination, error)
 	FindOrganisationByURI(ctx context.Context, uri string) (*models.Organisation, error)
+	ListApisByOrganisation(ctx context.Context, uri string) ([]models.Api, error)
+	CountApisByOrganisation(ctx context.Context, uri string) (int, error)
+	DeleteOrganisation(ctx context.Context, uri string) error
+	MergeOrganisations(ctx context.Context, duplicateURI, canonicalURI string) ([]string, error)
+	BumpApiRevisionsByOrganisation(ctx context.Context, uri string) error
 	SaveArtifact(ctx context.Context, art *models.ApiArtifact) error
 	HasArtifactOfKind(ctx context.Context, apiID, kind string) (bool, error)
 	GetOasArtifact(ctx context.Context, apiID, version, format string) (*models.ApiArtifact, error)
+	GetArazzoArtifact(ctx context.Context, apiID, format string) (*models.ApiArtifact, error)
 	GetArtifact(ctx context.Context, apiID, kind string) (*models.ApiArtifact, error)
+	GetArtifactData(ctx context.Context, id string) ([]byte, error)
 	DeleteArtifactsByKind(ctx context.Context, apiID, kind string, keepIDs []string) error
 	GetApiFilterCounts(ctx context.Context, p *models.ApiFiltersParams) (*models.ApiFilterCounts, error)
+	SaveAuditEntry(ctx context.Context, entry *models.AuditEntry) error
+	ListAuditEntries(ctx context.Context, page, perPage int, filter models.AuditFilter) ([]models.AuditEntry, models.Pagination, error)
+	DeleteApi(ctx context.Context, id string) error
+	GetDeletedApiByID(ctx context.Context, id string) (*models.Api, error)
+	RestoreApi(ctx context.Context, id string) error
+	PurgeDeletedApis(ctx context.Context, deletedBefore time.Time) ([]string, error)
+	FindPendingTransfer(ctx context.Context, apiID string) (*models.ApiTransfer, error)
+	SaveTransfer(ctx context.Context, transfer *models.ApiTransfer) error
+	CompleteTransfer(ctx context.Context, transfer *models.ApiTransfer, revision int64) error
+	ListApisByReviewStatus(ctx context.Context, status string, page, perPage int) ([]models.Api, models.Pagination, error)
+	ListArtifacts(ctx context.Context, apiID string) ([]models.ApiArtifact, error)
+	GetArtifactByID(ctx context.Context, apiID, id string) (*models.ApiArtifact, error)
+	SaveApiChange(ctx context.Context, change *models.ApiChange) error
+	ListApiChanges(ctx context.Context, afterSeq int64, limit int, settledBefore time.Time) ([]models.ApiChange, bool, error)
+	SaveReplacement(ctx context.Context, successor *models.Api, predecessorID string, link func(predecessor *models.Api)) (*models.Api, error)
 }
 
 type apiRepository struct {
@@ -45,6 +71,7 @@ type apiRepository struct {
 type apiFilterMatcher struct {
 	params          *models.ApiFiltersParams
 	organisation    string
+	transferred     map[string]bool
 	ids             map[string]bool
 	status          map[string]bool
 	oasVersion      map[string]bool
@@ -55,6 +82,17 @@ type apiFilterMatcher struct {
 	now             time.Time
 }
 
+// ErrRevisionConflict betekent dat een API sinds het laden door een ander is
+// gewijzigd.
+var ErrRevisionConflict = errors.New("api is intussen gewijzigd")
+
+// ErrApiExists betekent dat er al een API met dezelfde oasUrl is geregistreerd.
+var ErrApiExists = errors.New("api bestaat al")
+
+// ErrAlreadyReplaced betekent dat de voorganger al door een andere API is
+// opgevolgd.
+var ErrAlreadyReplaced = errors.New("api is al opgevolgd")
+
 func NewApiRepository(db *gorm.DB) ApiRepository {
 	return &apiRepository{db: db}
 }
@@ -65,25 +103,55 @@ func (r *apiRepository) Save(api *models.Api) error {
 		return err
 	}
 	if oldApi != nil {
-		return errors.New("api bestaat al")
+		return ErrApiExists
+	}
+	if api.Revision == 0 {
+		api.Revision = 1
 	}
 	return r.db.Create(api).Error
 }
 
-func (r *apiRepository) GetApis(ctx context.Context, page, perPage int, p *models.ApiFiltersParams) ([]models.Api, models.Pagination, error) {
-	if page < 1 {
-		page = 1
+// SaveReplacement slaat successor op en koppelt in dezelfde transactie de
+// voorganger eraan. De voorganger wordt binnen de transactie opnieuw gelezen
+// en door link aangepast. Is hij intussen opgevolgd, verwijderd of gewijzigd,
+// dan wordt niets opgeslagen en volgt ErrAlreadyReplaced of
+// ErrRevisionConflict. Geeft de bijgewerkte voorganger.
+func (r *apiRepository) SaveReplacement(ctx context.Context, successor *models.Api, predecessorID string, link func(predecessor *models.Api)) (*models.Api, error) {
+	var predecessor *models.Api
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		txRepo := &apiRepository{db: tx}
+		if err := txRepo.Save(successor); err != nil {
+			return err
+		}
+		var err error
+		if predecessor, err = txRepo.GetApiByID(ctx, predecessorID); err != nil {
+			return err
+		}
+		if predecessor == nil {
+			return ErrRevisionConflict
+		}
+		if predecessor.ReplacedByID != nil && *predecessor.ReplacedByID != "" {
+			return ErrAlreadyReplaced
+		}
+		link(predecessor)
+		return txRepo.UpdateApi(ctx, *predecessor)
+	})
+	if err != nil {
+		return nil, err
 	}
-	if perPage <= 0 {
-		perPage = 10
+	predecessor.Revision++
+	return predecessor, nil
+}
+
+func (r *apiRepository) GetApis(ctx context.Context, page models.PageRequest, p *models.ApiFiltersParams, order models.ApiSort, expand models.ApiExpand) ([]models.Api, models.Pagination, error) {
+	page = normalizePage(page)
+	matcher, err := r.compileApiFilters(ctx, p)
+	if err != nil {
+		return nil, models.Pagination{}, err
 	}
-	matcher := compileApiFilters(p)
 
 	var apis []models.Api
-	if err := applyApiOrdering(r.db.WithContext(ctx)).
-		Preload("Servers").
-		Preload("Organisation").
-		Find(&apis).Error; err != nil {
+	if err := preloadApis(r.approved(ctx), expand, order).Find(&apis).Error; err != nil {
 		return nil, models.Pagination{}, err
 	}
 
@@ -93,50 +161,220 @@ func (r *apiRepository) GetApis(ctx context.Context, page, perPage int, p *model
 			filtered = append(filtered, api)
 		}
 	}
+	keys := sortApis(filtered, order, matcher.now)
+	pageApis, pagination, err := paginateApis(filtered, keys, page, order)
+	if err != nil {
+		return nil, models.Pagination{}, err
+	}
+	if err := r.loadApiExpansions(ctx, pageApis, expand); err != nil {
+		return nil, models.Pagination{}, err
+	}
+	return pageApis, pagination, nil
+}
 
-	totalRecords := len(filtered)
-	totalPages := 0
-	if totalRecords > 0 {
-		totalPages = int(math.Ceil(float64(totalRecords) / float64(perPage)))
+// preloadApis laadt alleen de relaties die expand vraagt, plus de organisatie
+// als daarop gesorteerd wordt.
+func preloadApis(db *gorm.DB, expand models.ApiExpand, order models.ApiSort) *gorm.DB {
+	if expand.Servers {
+		db = db.Preload("Servers")
 	}
-	pagination := models.Pagination{
-		CurrentPage:    page,
-		RecordsPerPage: perPage,
-		TotalPages:     totalPages,
-		TotalRecords:   totalRecords,
+	if expand.Organisation || order.Field == models.ApiSortOrganisation {
+		db = db.Preload("Organisation")
 	}
+	return db
+}
 
-	if page < totalPages {
-		next := page + 1
-		pagination.Next = &next
+// loadApiExpansions laadt de lintresultaten en artifacts die expand vraagt
+// voor alle apis in twee queries.
+func (r *apiRepository) loadApiExpansions(ctx context.Context, apis []models.Api, expand models.ApiExpand) error {
+	if len(apis) == 0 || (expand.LintResults == "" && !expand.Artifacts) {
+		return nil
 	}
-	if page > 1 && totalPages > 0 {
-		prev := page - 1
-		pagination.Previous = &prev
+	ids := make([]string, len(apis))
+	index := make(map[string]int, len(apis))
+	for i, api := range apis {
+		ids[i] = api.Id
+		index[api.Id] = i
+	}
+	db := r.db.WithContext(ctx)
+	if expand.LintResults != "" {
+		query := db.Where("api_id IN ?", ids)
+		if expand.LintResults == models.LintResultsLatest {
+			var latest []models.LintResult
+			if err := db.Select("id", "api_id").
+				Where("api_id IN ?", ids).
+				Order("created_at desc").Order("id desc").
+				Find(&latest).Error; err != nil {
+				return err
+			}
+			seen := make(map[string]bool, len(apis))
+			latestIDs := make([]string, 0, len(apis))
+			for _, res := range latest {
+				if !seen[res.ApiID] {
+					seen[res.ApiID] = true
+					latestIDs = append(latestIDs, res.ID)
+				}
+			}
+			query = db.Where("id IN ?", latestIDs)
+		}
+		var results []models.LintResult
+		if err := query.
+			Preload("Messages").
+			Preload("Messages.Infos").
+			Order("created_at desc").Order("id desc").
+			Find(&results).Error; err != nil {
+			return err
+		}
+		for _, res := range results {
+			api := &apis[index[res.ApiID]]
+			api.LintResults = append(api.LintResults, res)
+		}
 	}
+	if expand.Artifacts {
+		var arts []models.ApiArtifact
+		if err := db.Omit("data").
+			Where("api_id IN ?", ids).
+			Order("kind").Order("created_at desc").
+			Find(&arts).Error; err != nil {
+			return err
+		}
+		for _, art := range arts {
+			api := &apis[index[art.ApiID]]
+			api.Artifacts = append(api.Artifacts, art)
+		}
+	}
+	return nil
+}
 
-	offset := (page - 1) * perPage
-	if offset >= totalRecords {
+// paginateApis geeft een pagina uit een gefilterde lijst, gesorteerd met
+// sortApis. keys zijn de sorteersleutels van apis.
+func paginateApis(apis []models.Api, keys []models.Cursor, page models.PageRequest, order models.ApiSort) ([]models.Api, models.Pagination, error) {
+	var pagination models.Pagination
+	start := 0
+	if page.Cursor {
+		pagination = models.Pagination{RecordsPerPage: page.PerPage, TotalRecords: len(apis), Cursor: true}
+		if page.After != nil {
+			start = sort.Search(len(keys), func(i int) bool {
+				return compareApiKeys(keys[i], *page.After, order.Desc) > 0
+			})
+		}
+	} else {
+		pagination = newPagination(page.Page, page.PerPage, len(apis))
+		start = (page.Page - 1) * page.PerPage
+	}
+	if start >= len(apis) {
 		return []models.Api{}, pagination, nil
 	}
+	end := start + page.PerPage
+	if end > len(apis) {
+		end = len(apis)
+	}
+	if end < len(apis) {
+		next := keys[end-1]
+		next.Sort = order.String()
+		pagination.NextCursor = next.Encode()
+	}
+	return apis[start:end], pagination, nil
+}
 
-	end := offset + perPage
-	if end > totalRecords {
-		end = totalRecords
+// sortApis sorteert apis volgens order en geeft de sorteersleutels in dezelfde
+// volgorde terug. APIs zonder waarde voor het sorteerveld staan altijd
+// achteraan; bij gelijke waarden beslist de titel en daarna het id.
+func sortApis(apis []models.Api, order models.ApiSort, now time.Time) []models.Cursor {
+	type keyed struct {
+		api models.Api
+		key models.Cursor
+	}
+	items := make([]keyed, len(apis))
+	for i, api := range apis {
+		items[i] = keyed{api: api, key: apiSortKey(api, order.Field, now)}
+	}
+	slices.SortStableFunc(items, func(a, b keyed) int {
+		return compareApiKeys(a.key, b.key, order.Desc)
+	})
+	keys := make([]models.Cursor, len(items))
+	for i, item := range items {
+		apis[i] = item.api
+		keys[i] = item.key
 	}
+	return keys
+}
+
+// lifecycleOrder zet de lifecycle-statussen in de volgorde waarin een API ze
+// doorloopt.
+var lifecycleOrder = map[string]int64{"active": 0, "deprecated": 1, "sunset": 2, "retired": 3}
 
-	return filtered[offset:end], pagination, nil
+// apiSortKey geeft de waarde van api voor het sorteerveld, aangevuld met titel
+// en id zodat elke API een unieke positie heeft.
+func apiSortKey(api models.Api, field string, now time.Time) models.Cursor {
+	key := models.Cursor{Title: strings.ToLower(api.Title), ID: api.Id}
+	switch field {
+	case models.ApiSortTitle:
+		key.Str = key.Title
+	case models.ApiSortAdrScore:
+		if api.AdrScore == nil {
+			key.Missing = true
+		} else {
+			key.Num = int64(*api.AdrScore)
+		}
+	case models.ApiSortOrganisation:
+		switch {
+		case api.Organisation != nil && strings.TrimSpace(api.Organisation.Label) != "":
+			key.Str = strings.ToLower(api.Organisation.Label)
+		case api.OrganisationID != nil:
+			key.Str = strings.ToLower(*api.OrganisationID)
+		default:
+			key.Missing = true
+		}
+	case models.ApiSortStatus:
+		key.Num = lifecycleOrder[api.LifecycleStatus(now)]
+	case models.ApiSortSunset:
+		if t, err := time.Parse(time.DateOnly, api.Sunset); err == nil {
+			key.Num = t.Unix()
+		} else {
+			key.Missing = true
+		}
+	case models.ApiSortLastUpdated:
+		if api.UpdatedAt == nil {
+			key.Missing = true
+		} else {
+			key.Num = api.UpdatedAt.UnixNano()
+		}
+	}
+	return key
 }
 
-func applyApiOrdering(db *gorm.DB) *gorm.DB {
-	return db.Order("title")
+func compareApiKeys(a, b models.Cursor, desc bool) int {
+	if a.Missing != b.Missing {
+		if a.Missing {
+			return 1
+		}
+		return -1
+	}
+	c := cmp.Compare(a.Str, b.Str)
+	if c == 0 {
+		c = cmp.Compare(a.Num, b.Num)
+	}
+	if desc {
+		c = -c
+	}
+	if c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.Title, b.Title); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.ID, b.ID)
 }
 
 func (r *apiRepository) GetApiFilterCounts(ctx context.Context, p *models.ApiFiltersParams) (*models.ApiFilterCounts, error) {
-	matcher := compileApiFilters(p)
+	matcher, err := r.compileApiFilters(ctx, p)
+	if err != nil {
+		return nil, err
+	}
 
 	var apis []models.Api
-	if err := r.db.WithContext(ctx).
+	if err := r.approved(ctx).
 		Preload("Organisation").
 		Find(&apis).Error; err != nil {
 		return nil, err
@@ -260,6 +498,30 @@ func filterCountSortKey(count models.FilterCount) string {
 	return strings.ToLower(label)
 }
 
+// compileApiFilters vult de filters aan met de APIs die van de gefilterde
+// organisatie zijn overgedragen, zodat links naar de oude eigenaar blijven werken.
+func (r *apiRepository) compileApiFilters(ctx context.Context, p *models.ApiFiltersParams) (*apiFilterMatcher, error) {
+	matcher := compileApiFilters(p)
+	if matcher.organisation == "" {
+		return matcher, nil
+	}
+	var ids []string
+	if err := r.transferredFrom(ctx, matcher.organisation).Pluck("api_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	matcher.transferred = make(map[string]bool, len(ids))
+	for _, id := range ids {
+		matcher.transferred[id] = true
+	}
+	return matcher, nil
+}
+
+// transferredFrom selecteert de voltooide overdrachten weg van een organisatie.
+func (r *apiRepository) transferredFrom(ctx context.Context, organisationURI string) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&models.ApiTransfer{}).
+		Where("from_organisation_uri = ? AND status = ?", organisationURI, models.ApiTransferCompleted)
+}
+
 func compileApiFilters(p *models.ApiFiltersParams) *apiFilterMatcher {
 	if p == nil {
 		p = &models.ApiFiltersParams{}
@@ -288,13 +550,23 @@ func apiMatchesCompiledFilters(api models.Api, matcher *apiFilterMatcher, exclud
 		return true
 	}
 	if exclude != "organisation" && matcher.organisation != "" {
-		if api.OrganisationID == nil || *api.OrganisationID != matcher.organisation {
+		owned := api.OrganisationID != nil && *api.OrganisationID == matcher.organisation
+		if !owned && !matcher.transferred[api.Id] {
 			return false
 		}
 	}
 	if len(matcher.ids) > 0 && !matcher.ids[api.Id] {
 		return false
 	}
+	if since := matcher.params.UpdatedSince; since != nil {
+		changed := api.UpdatedAt
+		if changed == nil {
+			changed = api.CreatedAt
+		}
+		if changed == nil || changed.Before(*since) {
+			return false
+		}
+	}
 	if exclude != "status" && len(matcher.status) > 0 {
 		if !matcher.status[api.LifecycleStatus(matcher.now)] {
 			return false
@@ -406,75 +678,51 @@ func normalizedAuthValue(value string) string {
 	}
 }
 
-func (r *apiRepository) SearchApis(ctx context.Context, page, perPage int, organisation *string, query string) ([]models.Api, models.Pagination, error) {
+// SearchApis zoekt op titel. Zoekresultaten worden net als GetApis in het
+// geheugen gesorteerd, zodat alle sorteervelden ook hier werken.
+func (r *apiRepository) SearchApis(ctx context.Context, page models.PageRequest, organisation *string, query string, order models.ApiSort, expand models.ApiExpand) ([]models.Api, models.Pagination, error) {
 	trimmed := strings.TrimSpace(query)
-	if page < 1 {
-		page = 1
-	}
-	if perPage <= 0 {
-		perPage = 10
-	}
+	page = normalizePage(page)
 	if trimmed == "" {
 		return []models.Api{}, models.Pagination{
-			CurrentPage:    page,
-			RecordsPerPage: perPage,
+			CurrentPage:    page.Page,
+			RecordsPerPage: page.PerPage,
+			Cursor:         page.Cursor,
 		}, nil
 	}
 
-	base := r.db.WithContext(ctx)
+	queryDB := r.approved(ctx)
 	if organisation != nil && strings.TrimSpace(*organisation) != "" {
-		base = base.Where("organisation_id = ?", strings.TrimSpace(*organisation))
-	}
-	var pattern string
-	if trimmed != "" {
-		pattern = fmt.Sprintf("%%%s%%", strings.ToLower(trimmed))
-		base = base.Where("LOWER(title) LIKE ?", pattern)
+		queryDB = r.whereOrganisation(ctx, queryDB, strings.TrimSpace(*organisation))
 	}
+	queryDB = queryDB.Where("LOWER(title) LIKE ?", fmt.Sprintf("%%%s%%", strings.ToLower(trimmed)))
 
-	var totalRecords int64
-	if err := base.Model(&models.Api{}).Count(&totalRecords).Error; err != nil {
+	var apis []models.Api
+	if err := preloadApis(queryDB, expand, order).Find(&apis).Error; err != nil {
 		return nil, models.Pagination{}, err
 	}
-
-	queryDB := r.db.WithContext(ctx)
-	if organisation != nil && strings.TrimSpace(*organisation) != "" {
-		queryDB = queryDB.Where("organisation_id = ?", strings.TrimSpace(*organisation))
-	}
-	if pattern != "" {
-		queryDB = queryDB.Where("LOWER(title) LIKE ?", pattern)
+	keys := sortApis(apis, order, time.Now())
+	pageApis, pagination, err := paginateApis(apis, keys, page, order)
+	if err != nil {
+		return nil, models.Pagination{}, err
 	}
-
-	var apis []models.Api
-	if err := queryDB.
-		Preload("Servers").
-		Preload("Organisation").
-		Order("title").
-		Offset((page - 1) * perPage).
-		Limit(perPage).
-		Find(&apis).Error; err != nil {
+	if err := r.loadApiExpansions(ctx, pageApis, expand); err != nil {
 		return nil, models.Pagination{}, err
 	}
+	return pageApis, pagination, nil
+}
 
-	totalPages := 0
-	if totalRecords > 0 {
-		totalPages = int(math.Ceil(float64(totalRecords) / float64(perPage)))
-	}
-	pagination := models.Pagination{
-		CurrentPage:    page,
-		RecordsPerPage: perPage,
-		TotalPages:     totalPages,
-		TotalRecords:   int(totalRecords),
-	}
-	if page < totalPages {
-		next := page + 1
-		pagination.Next = &next
-	}
-	if page > 1 && totalPages > 0 {
-		prev := page - 1
-		pagination.Previous = &prev
-	}
+// approved beperkt een query tot goedgekeurde APIs; registraties die nog op
+// review wachten of zijn afgewezen zijn niet publiek zichtbaar.
+func (r *apiRepository) approved(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Where("review_status = ?", models.ReviewStatusApproved)
+}
 
-	return apis, pagination, nil
+// whereOrganisation beperkt een query tot de APIs van een organisatie, inclusief
+// de APIs die sindsdien aan een andere organisatie zijn overgedragen.
+func (r *apiRepository) whereOrganisation(ctx context.Context, db *gorm.DB, organisationURI string) *gorm.DB {
+	return db.Where("organisation_id = ? OR id IN (?)", organisationURI,
+		r.transferredFrom(ctx, organisationURI).Select("api_id"))
 }
 
 func (r *apiRepository) GetApiByID(ctx context.Context, id string) (*models.Api, error) {
@@ -488,12 +736,36 @@ func (r *apiRepository) GetApiByID(ctx context.Context, id string) (*models.Api,
 	return &api, nil
 }
 
+// GetApiExpanded geeft een API met alleen de gerelateerde gegevens die expand
+// vraagt.
+func (r *apiRepository) GetApiExpanded(ctx context.Context, id string, expand models.ApiExpand) (*models.Api, error) {
+	var api models.Api
+	if err := preloadApis(r.db.WithContext(ctx), expand, models.ApiSort{}).First(&api, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	apis := []models.Api{api}
+	if err := r.loadApiExpansions(ctx, apis, expand); err != nil {
+		return nil, err
+	}
+	return &apis[0], nil
+}
+
+// UpdateApi schrijft api weg als compare-and-swap op de revisie: de update
+// slaagt alleen als de rij nog de revisie heeft waarmee api is geladen, en
+// hoogt die dan op. Anders volgt ErrRevisionConflict.
 func (r *apiRepository) UpdateApi(ctx context.Context, api models.Api) error {
-	return r.db.WithContext(ctx).Model(&models.Api{}).
-		Where("id = ?", api.Id).
+	expected := api.Revision
+	api.Revision = expected + 1
+	res := r.db.WithContext(ctx).Model(&models.Api{}).
+		Where("id = ? AND revision = ?", api.Id, expected).
 		Select(
 			"OasUri",
 			"OasHash",
+			"ArazzoUri",
+			"ArazzoHash",
 			"DocsUrl",
 			"Title",
 			"Description",
@@ -507,8 +779,25 @@ func (r *apiRepository) UpdateApi(ctx context.Context, api models.Api) error {
 			"Version",
 			"Sunset",
 			"Deprecated",
+			"ReplacesID",
+			"ReplacedByID",
+			"Overrides",
+			"ReviewStatus",
+			"ReviewReason",
+			"ReviewedBy",
+			"ReviewedAt",
+			"Revision",
+			"UpdatedAt",
+			"LastOASChangeAt",
 		).
-		Updates(api).Error
+		Updates(api)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrRevisionConflict
+	}
+	return nil
 }
 
 func (r *apiRepository) UpdateOASMetadata(ctx context.Context, apiID string, oas models.OASMetadata) error {
@@ -576,30 +865,121 @@ func (r *apiRepository) GetLintResults(ctx context.Context, apiID string) ([]mod
 	return results, nil
 }
 
-func (r *apiRepository) ListLintResults(ctx context.Context) ([]models.LintResult, error) {
+// ListLintResults geeft de lintresultaten die aan filter voldoen van nieuw
+// naar oud, per pagina of vanaf een cursor.
+func (r *apiRepository) ListLintResults(ctx context.Context, page models.PageRequest, filter models.LintResultFilter) ([]models.LintResult, models.Pagination, error) {
 	var results []models.LintResult
-	err := r.db.WithContext(ctx).
-		Preload("Messages").
+	db := r.db.WithContext(ctx)
+	query := db.Model(&models.LintResult{}).
+		Where("api_id IN (?)", db.Model(&models.Api{}).Select("id"))
+	if filter.ApiID != "" {
+		query = query.Where("api_id = ?", filter.ApiID)
+	}
+	if filter.Since != nil {
+		query = query.Where("created_at >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		query = query.Where("created_at < ?", *filter.Until)
+	}
+	if filter.FiltersMessages() {
+		query = query.Where("id IN (?)", filterLintMessages(db.Model(&models.LintMessage{}).Select("lint_result_id"), filter))
+	}
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, models.Pagination{}, err
+	}
+	var pagination models.Pagination
+	if page.Cursor {
+		pagination = models.Pagination{TotalRecords: int(total), RecordsPerPage: page.PerPage, Cursor: true}
+		if after := page.After; after != nil {
+			createdAt := time.Unix(0, after.Num)
+			query = query.Where("created_at < ? OR (created_at = ? AND id < ?)", createdAt, createdAt, after.ID)
+		}
+		query = query.Limit(page.PerPage + 1)
+	} else {
+		page = normalizePage(page)
+		pagination = newPagination(page.Page, page.PerPage, int(total))
+		query = query.Offset((page.Page - 1) * page.PerPage).Limit(page.PerPage)
+	}
+	err := query.
+		Preload("Messages", func(db *gorm.DB) *gorm.DB {
+			return filterLintMessages(db, filter)
+		}).
 		Preload("Messages.Infos").
-		Order("created_at desc").
+		Order("created_at desc").Order("id desc").
 		Find(&results).Error
 	if err != nil {
+		return nil, models.Pagination{}, err
+	}
+	if page.Cursor && len(results) > page.PerPage {
+		results = results[:page.PerPage]
+		last := results[len(results)-1]
+		pagination.NextCursor = models.Cursor{Sort: models.LintResultsCursorSort, Num: last.CreatedAt.UnixNano(), ID: last.ID}.Encode()
+	}
+	return results, pagination, nil
+}
+
+// filterLintMessages beperkt een query op lint_messages tot de meldingen die
+// bij filter passen.
+func filterLintMessages(query *gorm.DB, filter models.LintResultFilter) *gorm.DB {
+	if filter.Severity != "" {
+		query = query.Where("LOWER(severity) = ?", strings.ToLower(filter.Severity))
+	}
+	if filter.Code != "" {
+		query = query.Where("code = ?", filter.Code)
+	}
+	if filter.RulesetVersion != "" {
+		query = query.Where("ruleset_version = ?", filter.RulesetVersion)
+	}
+	return query
+}
+
+// GetLatestLintResult geeft het nieuwste lintresultaat van een API, of nil.
+func (r *apiRepository) GetLatestLintResult(ctx context.Context, apiID string) (*models.LintResult, error) {
+	var result models.LintResult
+	if err := r.db.WithContext(ctx).
+		Preload("Messages").
+		Preload("Messages.Infos").
+		Where("api_id = ?", apiID).
+		Order("created_at desc").Order("id desc").
+		Take(&result).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
 		return nil, err
 	}
-	return results, nil
+	return &result, nil
 }
 
-func (r *apiRepository) GetOrganisations(ctx context.Context) ([]models.Organisation, int, error) {
+// GetOrganisations geeft organisaties gesorteerd op label en uri, per pagina
+// of vanaf een cursor.
+func (r *apiRepository) GetOrganisations(ctx context.Context, page models.PageRequest) ([]models.Organisation, models.Pagination, error) {
+	page = normalizePage(page)
 	var organisations []models.Organisation
 	var total int64
 	db := r.db.WithContext(ctx)
 	if err := db.Model(&models.Organisation{}).Count(&total).Error; err != nil {
-		return nil, 0, err
+		return nil, models.Pagination{}, err
+	}
+	query := db.Order("label asc").Order("uri")
+	pagination := newPagination(page.Page, page.PerPage, int(total))
+	if page.Cursor {
+		pagination = models.Pagination{RecordsPerPage: page.PerPage, TotalRecords: int(total), Cursor: true}
+		if after := page.After; after != nil {
+			query = query.Where("label > ? OR (label = ? AND uri > ?)", after.Str, after.Str, after.ID)
+		}
+	} else {
+		query = query.Offset((page.Page - 1) * page.PerPage)
 	}
-	if err := db.Order("label asc").Find(&organisations).Error; err != nil {
-		return nil, 0, err
+	if err := query.Limit(page.PerPage + 1).Find(&organisations).Error; err != nil {
+		return nil, models.Pagination{}, err
+	}
+	if len(organisations) > page.PerPage {
+		organisations = organisations[:page.PerPage]
+		last := organisations[len(organisations)-1]
+		pagination.NextCursor = models.Cursor{Sort: models.OrganisationsCursorSort, Str: last.Label, ID: last.Uri}.Encode()
 	}
-	return organisations, int(total), nil
+	return organisations, pagination, nil
 }
 
 func (r *apiRepository) FindOrganisationByURI(ctx context.Context, uri string) (*models.Organisation, error) {
@@ -613,7 +993,76 @@ func (r *apiRepository) FindOrganisationByURI(ctx context.Context, uri string) (
 	return &org, nil
 }
 
+// ListApisByOrganisation geeft de actieve, goedgekeurde APIs van een organisatie.
+func (r *apiRepository) ListApisByOrganisation(ctx context.Context, uri string) ([]models.Api, error) {
+	var apis []models.Api
+	if err := r.approved(ctx).
+		Preload("Organisation").
+		Where("organisation_id = ?", uri).
+		Order("id").
+		Find(&apis).Error; err != nil {
+		return nil, err
+	}
+	return apis, nil
+}
+
+// CountApisByOrganisation telt alle APIs die naar een organisatie verwijzen,
+// inclusief verwijderde APIs die nog herstelbaar zijn.
+func (r *apiRepository) CountApisByOrganisation(ctx context.Context, uri string) (int, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Unscoped().
+		Model(&models.Api{}).
+		Where("organisation_id = ?", uri).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+func (r *apiRepository) DeleteOrganisation(ctx context.Context, uri string) error {
+	return r.db.WithContext(ctx).Delete(&models.Organisation{}, "uri = ?", uri).Error
+}
+
+// MergeOrganisations verplaatst alle APIs en API keys van de dubbele naar de
+// canonieke organisatie en verwijdert daarna de dubbele. Het resultaat bevat
+// de ids van de verplaatste APIs.
+func (r *apiRepository) MergeOrganisations(ctx context.Context, duplicateURI, canonicalURI string) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.Api{}).
+			Where("organisation_id = ?", duplicateURI).
+			Order("id").
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.Api{}).
+			Where("organisation_id = ?", duplicateURI).
+			Updates(map[string]any{"organisation_id": canonicalURI, "revision": gorm.Expr("revision + 1"), "updated_at": time.Now()}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ApiKey{}).
+			Where("organisation_uri = ?", duplicateURI).
+			Update("organisation_uri", canonicalURI).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Organisation{}, "uri = ?", duplicateURI).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// BumpApiRevisionsByOrganisation verhoogt de revisie van alle APIs van een
+// organisatie, zodat hun ETag na een wijziging van de organisatie verandert.
+func (r *apiRepository) BumpApiRevisionsByOrganisation(ctx context.Context, uri string) error {
+	return r.db.WithContext(ctx).Unscoped().Model(&models.Api{}).
+		Where("organisation_id = ?", uri).
+		Updates(map[string]any{"revision": gorm.Expr("revision + 1"), "updated_at": time.Now()}).Error
+}
+
 func (r *apiRepository) SaveArtifact(ctx context.Context, art *models.ApiArtifact) error {
+	art.SetDigest(art.Data)
 	return r.db.WithContext(ctx).Create(art).Error
 }
 
@@ -631,12 +1080,15 @@ func (r *apiRepository) HasArtifactOfKind(ctx context.Context, apiID, kind strin
 	return count > 0, nil
 }
 
+// GetOasArtifact geeft de metadata van het OAS-document in version en format,
+// zonder inhoud; haal die op met GetArtifactData.
 func (r *apiRepository) GetOasArtifact(ctx context.Context, apiID, version, format string) (*models.ApiArtifact, error) {
 	if strings.TrimSpace(apiID) == "" || strings.TrimSpace(version) == "" || strings.TrimSpace(format) == "" {
 		return nil, fmt.Errorf("apiID, version en format zijn verplicht")
 	}
 	var art models.ApiArtifact
 	query := r.db.WithContext(ctx).
+		Omit("data").
 		Where("api_id = ? AND kind = ? AND version = ? AND format = ?", apiID, "oas", version, strings.ToLower(format)).
 		Order("CASE WHEN source = 'original' THEN 0 ELSE 1 END").
 		Order("created_at desc")
@@ -649,9 +1101,34 @@ func (r *apiRepository) GetOasArtifact(ctx context.Context, apiID, version, form
 	return &art, nil
 }
 
+// GetArazzoArtifact geeft de metadata van het Arazzo-document in format. Zonder
+// format het document zoals het is aangeleverd.
+func (r *apiRepository) GetArazzoArtifact(ctx context.Context, apiID, format string) (*models.ApiArtifact, error) {
+	var art models.ApiArtifact
+	query := r.db.WithContext(ctx).
+		Omit("data").
+		Where("api_id = ? AND kind = ?", apiID, "arazzo")
+	if format != "" {
+		query = query.Where("format = ?", strings.ToLower(format))
+	}
+	if err := query.
+		Order("CASE WHEN source = 'original' THEN 0 ELSE 1 END").
+		Order("created_at desc").
+		First(&art).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &art, nil
+}
+
+// GetArtifact geeft de metadata van het nieuwste artifact van kind, zonder
+// inhoud; haal die op met GetArtifactData.
 func (r *apiRepository) GetArtifact(ctx context.Context, apiID, kind string) (*models.ApiArtifact, error) {
 	var a models.ApiArtifact
 	if err := r.db.WithContext(ctx).
+		Omit("data").
 		Where("api_id = ? AND kind = ?", apiID, kind).
 		Order("created_at desc").
 		First(&a).Error; err != nil {
@@ -663,6 +1140,22 @@ func (r *apiRepository) GetArtifact(ctx context.Context, apiID, kind string) (*m
 	return &a, nil
 }
 
+// GetArtifactData geeft de inhoud van een artifact. Artifacts worden nooit
+// gewijzigd, alleen vervangen door een nieuw artifact met een nieuw id.
+func (r *apiRepository) GetArtifactData(ctx context.Context, id string) ([]byte, error) {
+	var art models.ApiArtifact
+	if err := r.db.WithContext(ctx).
+		Select("data").
+		Where("id = ?", id).
+		Take(&art).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return art.Data, nil
+}
+
 func (r *apiRepository) DeleteArtifactsByKind(ctx context.Context, apiID, kind string, keepIDs []string) error {
 	if strings.TrimSpace(apiID) == "" || strings.TrimSpace(kind) == "" {
 		return fmt.Errorf("apiID en kind zijn verplicht voor verwijderen")
@@ -674,3 +1167,279 @@ func (r *apiRepository) DeleteArtifactsByKind(ctx context.Context, apiID, kind s
 	}
 	return query.Delete(&models.ApiArtifact{}).Error
 }
+
+// DeleteApi verwijdert de API zacht; artifacts en lint-historie blijven
+// bewaard tot PurgeDeletedApis ze opruimt.
+func (r *apiRepository) DeleteApi(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Delete(&models.Api{}, "id = ?", id).Error
+}
+
+func (r *apiRepository) GetDeletedApiByID(ctx context.Context, id string) (*models.Api, error) {
+	var api models.Api
+	if err := r.db.WithContext(ctx).Unscoped().
+		Preload("Servers").
+		Preload("Organisation").
+		Where("deleted_at IS NOT NULL").
+		First(&api, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &api, nil
+}
+
+func (r *apiRepository) RestoreApi(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Unscoped().
+		Model(&models.Api{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"deleted_at": nil, "updated_at": time.Now()}).Error
+}
+
+// PurgeDeletedApis verwijdert APIs die vóór deletedBefore zijn verwijderd
+// definitief, inclusief artifacts en lint-historie. De audit trail blijft staan.
+func (r *apiRepository) PurgeDeletedApis(ctx context.Context, deletedBefore time.Time) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.Api{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		resultIDs := tx.Model(&models.LintResult{}).Select("id").Where("api_id IN ?", ids)
+		messageIDs := tx.Model(&models.LintMessage{}).Select("id").Where("lint_result_id IN (?)", resultIDs)
+		if err := tx.Where("lint_message_id IN (?)", messageIDs).Delete(&models.LintMessageInfo{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("lint_result_id IN (?)", resultIDs).Delete(&models.LintMessage{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("api_id IN ?", ids).Delete(&models.LintResult{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("api_id IN ?", ids).Delete(&models.ApiArtifact{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM api_servers WHERE api_id IN ?", ids).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id IN ?", ids).Delete(&models.Api{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *apiRepository) SaveAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+func (r *apiRepository) ListAuditEntries(ctx context.Context, page, perPage int, filter models.AuditFilter) ([]models.AuditEntry, models.Pagination, error) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage <= 0 {
+		perPage = 10
+	}
+
+	query := r.db.WithContext(ctx).Model(&models.AuditEntry{})
+	if filter.ApiID != "" {
+		query = query.Where("api_id = ?", filter.ApiID)
+	}
+	if filter.Organisation != "" {
+		query = query.Where("organisation_uri = ?", filter.Organisation)
+	}
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Since != nil {
+		query = query.Where("created_at >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		query = query.Where("created_at < ?", *filter.Until)
+	}
+
+	var totalRecords int64
+	if err := query.Count(&totalRecords).Error; err != nil {
+		return nil, models.Pagination{}, err
+	}
+
+	var entries []models.AuditEntry
+	if err := query.
+		Order("created_at desc").
+		Order("id").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&entries).Error; err != nil {
+		return nil, models.Pagination{}, err
+	}
+
+	return entries, newPagination(page, perPage, int(totalRecords)), nil
+}
+
+// normalizePage vult een lege pagina aan met de standaardwaarden.
+func normalizePage(page models.PageRequest) models.PageRequest {
+	if page.Page < 1 {
+		page.Page = 1
+	}
+	if page.PerPage <= 0 {
+		page.PerPage = 10
+	}
+	return page
+}
+
+func newPagination(page, perPage, totalRecords int) models.Pagination {
+	totalPages := 0
+	if totalRecords > 0 {
+		totalPages = int(math.Ceil(float64(totalRecords) / float64(perPage)))
+	}
+	pagination := models.Pagination{
+		CurrentPage:    page,
+		RecordsPerPage: perPage,
+		TotalPages:     totalPages,
+		TotalRecords:   totalRecords,
+	}
+	if page < totalPages {
+		next := page + 1
+		pagination.Next = &next
+	}
+	if page > 1 && totalPages > 0 {
+		prev := page - 1
+		pagination.Previous = &prev
+	}
+	return pagination
+}
+
+func (r *apiRepository) FindPendingTransfer(ctx context.Context, apiID string) (*models.ApiTransfer, error) {
+	var transfer models.ApiTransfer
+	if err := r.db.WithContext(ctx).
+		Where("api_id = ? AND status = ?", apiID, models.ApiTransferPending).
+		Order("created_at DESC").
+		First(&transfer).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+func (r *apiRepository) SaveTransfer(ctx context.Context, transfer *models.ApiTransfer) error {
+	return r.db.WithContext(ctx).Save(transfer).Error
+}
+
+// CompleteTransfer zet de API over naar de nieuwe organisatie en legt de
+// voltooide overdracht in dezelfde transactie vast. Net als UpdateApi is het
+// een compare-and-swap: staat de API niet meer op revision bij de oude
+// organisatie, dan volgt ErrRevisionConflict.
+func (r *apiRepository) CompleteTransfer(ctx context.Context, transfer *models.ApiTransfer, revision int64) error {
+	updatedAt := time.Now().UTC()
+	if transfer.CompletedAt != nil {
+		updatedAt = *transfer.CompletedAt
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.Api{}).
+			Where("id = ? AND organisation_id = ? AND revision = ?", transfer.ApiID, transfer.FromOrganisationUri, revision).
+			Updates(map[string]any{"organisation_id": transfer.ToOrganisationUri, "revision": revision + 1, "updated_at": updatedAt})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrRevisionConflict
+		}
+		return tx.Save(transfer).Error
+	})
+}
+
+// ListApisByReviewStatus geeft de registraties met een reviewstatus,
+// gesorteerd op titel.
+func (r *apiRepository) ListApisByReviewStatus(ctx context.Context, status string, page, perPage int) ([]models.Api, models.Pagination, error) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage <= 0 {
+		perPage = 10
+	}
+	var total int64
+	db := r.db.WithContext(ctx).Model(&models.Api{}).Where("review_status = ?", status)
+	if err := db.Count(&total).Error; err != nil {
+		return nil, models.Pagination{}, err
+	}
+	var apis []models.Api
+	if err := r.db.WithContext(ctx).
+		Preload("Servers").
+		Preload("Organisation").
+		Where("review_status = ?", status).
+		Order("title").Order("id").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&apis).Error; err != nil {
+		return nil, models.Pagination{}, err
+	}
+	return apis, newPagination(page, perPage, int(total)), nil
+}
+
+// ListArtifacts geeft de metadata van alle artifacts van een API, zonder inhoud.
+func (r *apiRepository) ListArtifacts(ctx context.Context, apiID string) ([]models.ApiArtifact, error) {
+	var arts []models.ApiArtifact
+	if err := r.db.WithContext(ctx).
+		Omit("data").
+		Where("api_id = ?", apiID).
+		Order("kind").Order("created_at desc").
+		Find(&arts).Error; err != nil {
+		return nil, err
+	}
+	return arts, nil
+}
+
+// GetArtifactByID geeft de metadata van één artifact van een API, zonder
+// inhoud.
+func (r *apiRepository) GetArtifactByID(ctx context.Context, apiID, id string) (*models.ApiArtifact, error) {
+	var art models.ApiArtifact
+	if err := r.db.WithContext(ctx).
+		Omit("data").
+		Where("api_id = ? AND id = ?", apiID, id).
+		Take(&art).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &art, nil
+}
+
+func (r *apiRepository) SaveApiChange(ctx context.Context, change *models.ApiChange) error {
+	return r.db.WithContext(ctx).Create(change).Error
+}
+
+// ListApiChanges geeft maximaal limit wijzigingen na afterSeq, oudste eerst,
+// en meldt of er daarna nog meer zijn. Seq wordt bij de insert uitgedeeld en
+// niet bij de commit, dus een recente wijziging kan nog een lagere seq achter
+// zich hebben die pas straks zichtbaar wordt. De pagina stopt daarom bij de
+// eerste wijziging van na settledBefore; die volgt bij een latere aanroep.
+func (r *apiRepository) ListApiChanges(ctx context.Context, afterSeq int64, limit int, settledBefore time.Time) ([]models.ApiChange, bool, error) {
+	var changes []models.ApiChange
+	if err := r.db.WithContext(ctx).
+		Where("seq > ?", afterSeq).
+		Order("seq").
+		Limit(limit + 1).
+		Find(&changes).Error; err != nil {
+		return nil, false, err
+	}
+	for i, change := range changes {
+		if !change.OccurredAt.Before(settledBefore) {
+			return changes[:min(i, limit)], false, nil
+		}
+	}
+	if len(changes) > limit {
+		return changes[:limit], true, nil
+	}
+	return changes, false, nil
+}
@@ -2,6 +2,7 @@ package repositories_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -26,6 +27,11 @@ func setupDB(t *testing.T) *gorm.DB {
 		&models.LintResult{},
 		&models.LintMessage{},
 		&models.LintMessageInfo{},
+		&models.AuditEntry{},
+		&models.ApiKey{},
+		&models.ApiTransfer{},
+		&models.IdempotencyRecord{},
+		&models.ApiChange{},
 	))
 	return db
 }
@@ -88,18 +94,209 @@ func TestApiRepository_GetApisAppliesFilters(t *testing.T) {
 	}
 	require.NoError(t, db.Create(&apis).Error)
 
-	results, pagination, err := repo.GetApis(ctx, 1, 10, &models.ApiFiltersParams{
+	results, pagination, err := repo.GetApis(ctx, models.PageRequest{Page: 1, PerPage: 10}, &models.ApiFiltersParams{
 		Status:     []string{"deprecated"},
 		OasVersion: []string{"3.0.0"},
 		Auth:       []string{"oauth2"},
 		AdrScore:   []string{"unknown"},
-	})
+	}, models.ApiSort{}, models.DefaultListExpand)
 	require.NoError(t, err)
 	require.Len(t, results, 1)
 	assert.Equal(t, "deprecated-api", results[0].Id)
 	assert.Equal(t, 1, pagination.TotalRecords)
 }
 
+func TestApiRepository_GetApisAndSearchApisSort(t *testing.T) {
+	db := setupDB(t)
+	repo := repositories.NewApiRepository(db)
+	ctx := context.Background()
+	orgA, orgB := "org-a", "org-b"
+	require.NoError(t, db.Create(&models.Organisation{Uri: orgA, Label: "Alfa"}).Error)
+	require.NoError(t, db.Create(&models.Organisation{Uri: orgB, Label: "bravo"}).Error)
+
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+	apis := []models.Api{
+		{Id: "c", OasUri: "c", Title: "Charlie API", AdrScore: intPtr(90), OrganisationID: &orgA, UpdatedAt: &earlier},
+		{Id: "a", OasUri: "a", Title: "alpha API", AdrScore: intPtr(70), OrganisationID: &orgB, UpdatedAt: &now,
+			Sunset: now.AddDate(0, 6, 0).Format(time.DateOnly)},
+		{Id: "b", OasUri: "b", Title: "Bravo API", OrganisationID: &orgA,
+			Deprecated: now.AddDate(0, 0, -1).Format(time.DateOnly), Sunset: now.AddDate(0, 1, 0).Format(time.DateOnly)},
+	}
+	require.NoError(t, db.Create(&apis).Error)
+	require.NoError(t, db.Model(&models.Api{}).Where("id = ?", "b").UpdateColumn("updated_at", nil).Error)
+
+	ids := func(apis []models.Api) []string {
+		out := make([]string, len(apis))
+		for i, api := range apis {
+			out[i] = api.Id
+		}
+		return out
+	}
+	tests := []struct {
+		sort   string
+		expect []string
+	}{
+		{sort: "", expect: []string{"a", "b", "c"}},
+		{sort: "-title", expect: []string{"c", "b", "a"}},
+		{sort: "-adrScore", expect: []string{"c", "a", "b"}},
+		{sort: "adrScore", expect: []string{"a", "c", "b"}},
+		{sort: "organisation", expect: []string{"b", "c", "a"}},
+		{sort: "-organisation", expect: []string{"a", "b", "c"}},
+		{sort: "status", expect: []string{"c", "a", "b"}},
+		{sort: "sunset", expect: []string{"b", "a", "c"}},
+		{sort: "-sunset", expect: []string{"a", "b", "c"}},
+		{sort: "-lastUpdated", expect: []string{"a", "c", "b"}},
+	}
+	for _, tc := range tests {
+		order, err := models.ParseApiSort(tc.sort)
+		require.NoError(t, err)
+
+		got, _, err := repo.GetApis(ctx, models.PageRequest{Page: 1, PerPage: 10}, nil, order, models.DefaultListExpand)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expect, ids(got), "GetApis sort=%q", tc.sort)
+
+		got, _, err = repo.SearchApis(ctx, models.PageRequest{Page: 1, PerPage: 10}, nil, "api", order, models.DefaultListExpand)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expect, ids(got), "SearchApis sort=%q", tc.sort)
+	}
+
+	order, err := models.ParseApiSort("-adrScore")
+	require.NoError(t, err)
+	page, pagination, err := repo.SearchApis(ctx, models.PageRequest{Page: 2, PerPage: 2}, nil, "api", order, models.DefaultListExpand)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, ids(page))
+	assert.Equal(t, 3, pagination.TotalRecords)
+	assert.Equal(t, 2, pagination.TotalPages)
+}
+
+func TestApiRepository_CursorPaginationIsStableUnderInserts(t *testing.T) {
+	db := setupDB(t)
+	repo := repositories.NewApiRepository(db)
+	ctx := context.Background()
+	for _, id := range []string{"b", "d", "f", "h"} {
+		require.NoError(t, repo.Save(&models.Api{Id: id, OasUri: id, Title: id + " api"}))
+	}
+	order, err := models.ParseApiSort("title")
+	require.NoError(t, err)
+
+	first, pagination, err := repo.GetApis(ctx, models.PageRequest{PerPage: 2, Cursor: true}, nil, order, models.DefaultListExpand)
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+	assert.Equal(t, "d", first[1].Id)
+	assert.Equal(t, 4, pagination.TotalRecords)
+	require.NotEmpty(t, pagination.NextCursor)
+
+	// Een nieuwe API voor de cursor mag de volgende pagina niet verschuiven.
+	require.NoError(t, repo.Save(&models.Api{Id: "a", OasUri: "a", Title: "a api"}))
+	require.NoError(t, repo.Save(&models.Api{Id: "e", OasUri: "e", Title: "e api"}))
+
+	after, err := models.DecodeCursor(pagination.NextCursor)
+	require.NoError(t, err)
+	assert.Equal(t, "title", after.Sort)
+	second, pagination, err := repo.GetApis(ctx, models.PageRequest{PerPage: 2, Cursor: true, After: &after}, nil, order, models.DefaultListExpand)
+	require.NoError(t, err)
+	require.Len(t, second, 2)
+	assert.Equal(t, "e", second[0].Id)
+	assert.Equal(t, "f", second[1].Id)
+	require.NotEmpty(t, pagination.NextCursor)
+
+	after, err = models.DecodeCursor(pagination.NextCursor)
+	require.NoError(t, err)
+	last, pagination, err := repo.SearchApis(ctx, models.PageRequest{PerPage: 2, Cursor: true, After: &after}, nil, "api", order, models.DefaultListExpand)
+	require.NoError(t, err)
+	require.Len(t, last, 1)
+	assert.Equal(t, "h", last[0].Id)
+	assert.Empty(t, pagination.NextCursor)
+}
+
+func TestApiRepository_CursorPaginationOrganisationsAndLintResults(t *testing.T) {
+	db := setupDB(t)
+	repo := repositories.NewApiRepository(db)
+	ctx := context.Background()
+	for _, label := range []string{"Alfa", "Bravo", "Charlie"} {
+		require.NoError(t, repo.SaveOrganisatie(&models.Organisation{Uri: "https://org.example/" + label, Label: label}))
+	}
+
+	orgs, pagination, err := repo.GetOrganisations(ctx, models.PageRequest{PerPage: 2, Cursor: true})
+	require.NoError(t, err)
+	require.Len(t, orgs, 2)
+	require.NotEmpty(t, pagination.NextCursor)
+	require.NoError(t, repo.SaveOrganisatie(&models.Organisation{Uri: "https://org.example/Aa", Label: "Aa"}))
+	after, err := models.DecodeCursor(pagination.NextCursor)
+	require.NoError(t, err)
+	orgs, pagination, err = repo.GetOrganisations(ctx, models.PageRequest{PerPage: 2, Cursor: true, After: &after})
+	require.NoError(t, err)
+	require.Len(t, orgs, 1)
+	assert.Equal(t, "Charlie", orgs[0].Label)
+	assert.Empty(t, pagination.NextCursor)
+
+	require.NoError(t, repo.Save(&models.Api{Id: "api-1", OasUri: "u1"}))
+	base := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	for i, id := range []string{"lr-1", "lr-2", "lr-3"} {
+		require.NoError(t, repo.SaveLintResult(ctx, &models.LintResult{ID: id, ApiID: "api-1", CreatedAt: base.Add(time.Duration(i) * time.Minute)}))
+	}
+	results, pagination, err := repo.ListLintResults(ctx, models.PageRequest{PerPage: 2, Cursor: true}, models.LintResultFilter{})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "lr-3", results[0].ID)
+	assert.Equal(t, 3, pagination.TotalRecords)
+	require.NotEmpty(t, pagination.NextCursor)
+	require.NoError(t, repo.SaveLintResult(ctx, &models.LintResult{ID: "lr-4", ApiID: "api-1", CreatedAt: base.Add(time.Hour)}))
+	after, err = models.DecodeCursor(pagination.NextCursor)
+	require.NoError(t, err)
+	results, pagination, err = repo.ListLintResults(ctx, models.PageRequest{PerPage: 2, Cursor: true, After: &after}, models.LintResultFilter{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "lr-1", results[0].ID)
+	assert.Empty(t, pagination.NextCursor)
+}
+
+func TestApiRepository_ExpandLoadsOnlyWhatIsRequested(t *testing.T) {
+	db := setupDB(t)
+	repo := repositories.NewApiRepository(db)
+	ctx := context.Background()
+	orgURI := "org-expand"
+	require.NoError(t, repo.Save(&models.Api{
+		Id: "a1", OasUri: "u1", Title: "api", OrganisationID: &orgURI,
+		Organisation: &models.Organisation{Uri: orgURI, Label: "Org"},
+		Servers:      []models.Server{{Id: "srv-1", Uri: "https://example.org"}},
+	}))
+	require.NoError(t, repo.Save(&models.Api{Id: "a2", OasUri: "u2", Title: "api 2"}))
+	base := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	for i, id := range []string{"lr-old", "lr-new"} {
+		require.NoError(t, repo.SaveLintResult(ctx, &models.LintResult{ID: id, ApiID: "a1", CreatedAt: base.Add(time.Duration(i) * time.Hour)}))
+	}
+	require.NoError(t, repo.SaveArtifact(ctx, &models.ApiArtifact{ID: "art-1", ApiID: "a1", Kind: "postman", Data: []byte("{}")}))
+
+	api, err := repo.GetApiExpanded(ctx, "a1", models.ApiExpand{})
+	require.NoError(t, err)
+	assert.Nil(t, api.Organisation)
+	assert.Empty(t, api.Servers)
+	assert.Empty(t, api.LintResults)
+	assert.Empty(t, api.Artifacts)
+
+	api, err = repo.GetApiExpanded(ctx, "a1", models.ApiExpand{Servers: true, Organisation: true, Artifacts: true, LintResults: models.LintResultsLatest})
+	require.NoError(t, err)
+	require.NotNil(t, api.Organisation)
+	assert.Len(t, api.Servers, 1)
+	require.Len(t, api.LintResults, 1)
+	assert.Equal(t, "lr-new", api.LintResults[0].ID)
+	require.Len(t, api.Artifacts, 1)
+	assert.Nil(t, api.Artifacts[0].Data, "artifacts worden zonder inhoud geladen")
+
+	apis, _, err := repo.GetApis(ctx, models.PageRequest{Page: 1, PerPage: 10}, nil, models.ApiSort{}, models.ApiExpand{LintResults: models.LintResultsAll})
+	require.NoError(t, err)
+	require.Len(t, apis, 2)
+	assert.Len(t, apis[0].LintResults, 2)
+	assert.Empty(t, apis[1].LintResults)
+	assert.Nil(t, apis[0].Organisation)
+
+	missing, err := repo.GetApiExpanded(ctx, "onbekend", models.DefaultDetailExpand)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
 func TestApiRepository_GetApiFilterCountsRespectOtherFilters(t *testing.T) {
 	db := setupDB(t)
 	repo := repositories.NewApiRepository(db)
@@ -240,3 +437,434 @@ func TestApiRepository_SaveLintResult_PersistsMessageRulesetVersion(t *testing.T
 	require.Len(t, stored[0].Messages, 1)
 	assert.Equal(t, "2026.04", stored[0].Messages[0].RulesetVersion)
 }
+
+func TestApiRepository_ListAuditEntriesFiltersAndPaginates(t *testing.T) {
+	db := setupDB(t)
+	repo := repositories.NewApiRepository(db)
+	ctx := context.Background()
+
+	apiA, apiB := "api-a", "api-b"
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	entries := []models.AuditEntry{
+		{ID: "1", ApiID: &apiA, Actor: "client-a", Action: models.AuditActionApiCreated, CreatedAt: base},
+		{ID: "2", ApiID: &apiA, Actor: "client-a", Action: models.AuditActionApiUpdated, CreatedAt: base.Add(time.Hour),
+			Changes: []models.FieldChange{{Field: "title", Before: "Oud", After: "Nieuw"}}},
+		{ID: "3", ApiID: &apiB, Actor: "system:oas-refresh", Action: models.AuditActionApiRefreshed, CreatedAt: base.Add(2 * time.Hour)},
+		{ID: "4", OrganisationUri: "https://example.org", Actor: "beheer", Action: models.AuditActionOrganisationCreated, CreatedAt: base.Add(3 * time.Hour)},
+	}
+	for i := range entries {
+		require.NoError(t, repo.SaveAuditEntry(ctx, &entries[i]))
+	}
+
+	all, pagination, err := repo.ListAuditEntries(ctx, 1, 3, models.AuditFilter{})
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, "4", all[0].ID)
+	assert.Equal(t, 4, pagination.TotalRecords)
+	assert.Equal(t, 2, pagination.TotalPages)
+	require.NotNil(t, pagination.Next)
+
+	byApi, _, err := repo.ListAuditEntries(ctx, 1, 10, models.AuditFilter{ApiID: apiA})
+	require.NoError(t, err)
+	require.Len(t, byApi, 2)
+	assert.Equal(t, "2", byApi[0].ID)
+	require.Len(t, byApi[0].Changes, 1)
+	assert.Equal(t, "Nieuw", byApi[0].Changes[0].After)
+
+	since := base.Add(30 * time.Minute)
+	until := base.Add(3 * time.Hour)
+	window, _, err := repo.ListAuditEntries(ctx, 1, 10, models.AuditFilter{Since: &since, Until: &until})
+	require.NoError(t, err)
+	require.Len(t, window, 2)
+
+	byActor, _, err := repo.ListAuditEntries(ctx, 1, 10, models.AuditFilter{Actor: "system:oas-refresh", Action: models.AuditActionApiRefreshed})
+	require.NoError(t, err)
+	require.Len(t, byActor, 1)
+	assert.Equal(t, "3", byActor[0].ID)
+}
+
+func TestApiRepository_SoftDeleteRestoreAndPurge(t *testing.T) {
+	db := setupDB(t)
+	repo := repositories.NewApiRepository(db)
+	ctx := context.Background()
+
+	orgURI := "https://example.org"
+	for _, id := range []string{"keep", "gone"} {
+		require.NoError(t, repo.Save(&models.Api{
+			Id:             id,
+			OasUri:         "https://example.org/" + id + ".json",
+			Title:          id,
+			Organisation:   &models.Organisation{Uri: orgURI, Label: "Org"},
+			OrganisationID: &orgURI,
+			Servers:        []models.Server{{Id: "srv-" + id, Uri: "https://example.org/" + id}},
+		}))
+		require.NoError(t, repo.SaveArtifact(ctx, &models.ApiArtifact{ID: "art-" + id, ApiID: id, Kind: "postman"}))
+		require.NoError(t, repo.SaveLintResult(ctx, &models.LintResult{
+			ID:    "lint-" + id,
+			ApiID: id,
+			Messages: []models.LintMessage{{
+				ID:    "msg-" + id,
+				Infos: []models.LintMessageInfo{{ID: "info-" + id, Message: "m"}},
+			}},
+		}))
+	}
+
+	require.NoError(t, repo.DeleteApi(ctx, "gone"))
+
+	got, err := repo.GetApiByID(ctx, "gone")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+	apis, pagination, err := repo.GetApis(ctx, models.PageRequest{Page: 1, PerPage: 10}, nil, models.ApiSort{}, models.DefaultListExpand)
+	require.NoError(t, err)
+	require.Len(t, apis, 1)
+	assert.Equal(t, 1, pagination.TotalRecords)
+	found, _, err := repo.SearchApis(ctx, models.PageRequest{Page: 1, PerPage: 10}, nil, "gone", models.ApiSort{}, models.DefaultListExpand)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+	counts, err := repo.GetApiFilterCounts(ctx, &models.ApiFiltersParams{})
+	require.NoError(t, err)
+	require.Len(t, counts.Organisation, 1)
+	assert.Equal(t, 1, counts.Organisation[0].Count)
+	lint, _, err := repo.ListLintResults(ctx, models.PageRequest{}, models.LintResultFilter{})
+	require.NoError(t, err)
+	require.Len(t, lint, 1)
+	assert.Equal(t, "keep", lint[0].ApiID)
+
+	deleted, err := repo.GetDeletedApiByID(ctx, "gone")
+	require.NoError(t, err)
+	require.NotNil(t, deleted)
+	assert.True(t, deleted.DeletedAt.Valid)
+	missing, err := repo.GetDeletedApiByID(ctx, "keep")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	require.NoError(t, repo.RestoreApi(ctx, "gone"))
+	got, err = repo.GetApiByID(ctx, "gone")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+
+	require.NoError(t, repo.DeleteApi(ctx, "gone"))
+	purged, err := repo.PurgeDeletedApis(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, purged, "binnen retentie niet opruimen")
+
+	purged, err = repo.PurgeDeletedApis(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gone"}, purged)
+	deleted, err = repo.GetDeletedApiByID(ctx, "gone")
+	require.NoError(t, err)
+	assert.Nil(t, deleted)
+
+	var artifacts, results, messages, infos int64
+	require.NoError(t, db.Model(&models.ApiArtifact{}).Count(&artifacts).Error)
+	require.NoError(t, db.Model(&models.LintResult{}).Count(&results).Error)
+	require.NoError(t, db.Model(&models.LintMessage{}).Count(&messages).Error)
+	require.NoError(t, db.Model(&models.LintMessageInfo{}).Count(&infos).Error)
+	assert.EqualValues(t, 1, artifacts)
+	assert.EqualValues(t, 1, results)
+	assert.EqualValues(t, 1, messages)
+	assert.EqualValues(t, 1, infos)
+}
+
+func TestApiRepository_OrganisationsPaginateCountAndMerge(t *testing.T) {
+	db := setupDB(t)
+	repo := repositories.NewApiRepository(db)
+	ctx := context.Background()
+
+	dupURI := "https://org.example/dubbel"
+	canonURI := "https://org.example/canoniek"
+	require.NoError(t, repo.SaveOrganisatie(&models.Organisation{Uri: dupURI, Label: "B dubbel"}))
+	require.NoError(t, repo.SaveOrganisatie(&models.Organisation{Uri: canonURI, Label: "A canoniek"}))
+	require.NoError(t, repo.SaveOrganisatie(&models.Organisation{Uri: "https://org.example/leeg", Label: "C leeg"}))
+	require.NoError(t, repo.Save(&models.Api{Id: "a1", OasUri: "u1", OrganisationID: &dupURI}))
+	require.NoError(t, repo.Save(&models.Api{Id: "a2", OasUri: "u2", OrganisationID: &dupURI}))
+	require.NoError(t, repo.DeleteApi(ctx, "a2"))
+	require.NoError(t, db.Create(&models.ApiKey{ID: "k1", Hash: "h1", OrganisationUri: dupURI}).Error)
+
+	orgs, pagination, err := repo.GetOrganisations(ctx, models.PageRequest{Page: 2, PerPage: 2})
+	require.NoError(t, err)
+	require.Len(t, orgs, 1)
+	assert.Equal(t, "C leeg", orgs[0].Label)
+	assert.Equal(t, 3, pagination.TotalRecords)
+	assert.Equal(t, 2, pagination.TotalPages)
+
+	count, err := repo.CountApisByOrganisation(ctx, dupURI)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count, "verwijderde APIs tellen mee")
+	active, err := repo.ListApisByOrganisation(ctx, dupURI)
+	require.NoError(t, err)
+	assert.Len(t, active, 1)
+
+	moved, err := repo.MergeOrganisations(ctx, dupURI, canonURI)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a1", "a2"}, moved)
+
+	org, err := repo.FindOrganisationByURI(ctx, dupURI)
+	require.NoError(t, err)
+	assert.Nil(t, org)
+	count, err = repo.CountApisByOrganisation(ctx, canonURI)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	var key models.ApiKey
+	require.NoError(t, db.First(&key, "id = ?", "k1").Error)
+	assert.Equal(t, canonURI, key.OrganisationUri)
+}
+
+func TestApiRepository_GetArtifactLoadsDataSeparately(t *testing.T) {
+	db := setupDB(t)
+	repo := repositories.NewApiRepository(db)
+	ctx := context.Background()
+
+	now := time.Now()
+	require.NoError(t, repo.SaveArtifact(ctx, &models.ApiArtifact{ID: "old", ApiID: "a1", Kind: "postman", Data: []byte("oud"), CreatedAt: now.Add(-time.Hour)}))
+	require.NoError(t, repo.SaveArtifact(ctx, &models.ApiArtifact{ID: "new", ApiID: "a1", Kind: "postman", Data: []byte("nieuw"), CreatedAt: now}))
+	require.NoError(t, repo.SaveArtifact(ctx, &models.ApiArtifact{ID: "oas", ApiID: "a1", Kind: "oas", Version: "3.1", Format: "json", Data: []byte("{}"), CreatedAt: now}))
+
+	art, err := repo.GetArtifact(ctx, "a1", "postman")
+	require.NoError(t, err)
+	require.NotNil(t, art)
+	assert.Equal(t, "new", art.ID)
+	assert.Nil(t, art.Data)
+
+	oas, err := repo.GetOasArtifact(ctx, "a1", "3.1", "json")
+	require.NoError(t, err)
+	require.NotNil(t, oas)
+	assert.Nil(t, oas.Data)
+
+	data, err := repo.GetArtifactData(ctx, art.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("nieuw"), data)
+
+	data, err = repo.GetArtifactData(ctx, "onbekend")
+	require.NoError(t, err)
+	assert.Nil(t, data)
+}
+
+func TestApiRepository_ListArtifactsWithDigest(t *testing.T) {
+	db := setupDB(t)
+	repo := repositories.NewApiRepository(db)
+	ctx := context.Background()
+
+	require.NoError(t, repo.SaveArtifact(ctx, &models.ApiArtifact{ID: "bruno", ApiID: "a1", Kind: "bruno", Data: []byte("PK")}))
+	// Een artifact van voor de kolommen size en checksum.
+	require.NoError(t, db.Create(&models.ApiArtifact{ID: "legacy", ApiID: "a1", Kind: "postman", Data: []byte("{}")}).Error)
+	require.NoError(t, repo.SaveArtifact(ctx, &models.ApiArtifact{ID: "other", ApiID: "a2", Kind: "bruno", Data: []byte("PK")}))
+
+	arts, err := repo.ListArtifacts(ctx, "a1")
+	require.NoError(t, err)
+	require.Len(t, arts, 2)
+	assert.Equal(t, "bruno", arts[0].ID)
+	assert.Equal(t, int64(2), arts[0].Size)
+	assert.Equal(t, "fcab7fcc2b4cffd9bb45003bfc2e468a04ef6f77ca8200a7341f027631584d25", arts[0].Checksum)
+	assert.Nil(t, arts[0].Data)
+	assert.Equal(t, "legacy", arts[1].ID)
+	assert.Empty(t, arts[1].Checksum, "een GET vult geen checksums aan")
+
+	var stored models.ApiArtifact
+	require.NoError(t, db.Omit("data").Take(&stored, "id = ?", "legacy").Error)
+	assert.Empty(t, stored.Checksum)
+
+	art, err := repo.GetArtifactByID(ctx, "a1", "bruno")
+	require.NoError(t, err)
+	require.NotNil(t, art)
+	assert.Nil(t, art.Data)
+	art, err = repo.GetArtifactByID(ctx, "a1", "other")
+	require.NoError(t, err)
+	assert.Nil(t, art, "artifact van een andere API")
+}
+
+func TestApiRepository_ListLintResultsFilters(t *testing.T) {
+	db := setupDB(t)
+	repo := repositories.NewApiRepository(db)
+	ctx := context.Background()
+
+	require.NoError(t, repo.Save(&models.Api{Id: "api-1", OasUri: "u1"}))
+	require.NoError(t, repo.Save(&models.Api{Id: "api-2", OasUri: "u2"}))
+	base := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	save := func(id, apiID string, at time.Time, messages ...models.LintMessage) {
+		for i := range messages {
+			messages[i].ID = fmt.Sprintf("%s-m%d", id, i)
+		}
+		require.NoError(t, repo.SaveLintResult(ctx, &models.LintResult{ID: id, ApiID: apiID, CreatedAt: at, Messages: messages}))
+	}
+	save("lr-1", "api-1", base,
+		models.LintMessage{Severity: "error", Code: "nlgov:paths-kebab-case", RulesetVersion: "1.0"},
+		models.LintMessage{Severity: "warning", Code: "nlgov:info-contact", RulesetVersion: "1.0"})
+	save("lr-2", "api-1", base.Add(time.Hour),
+		models.LintMessage{Severity: "warning", Code: "nlgov:info-contact", RulesetVersion: "1.1"})
+	save("lr-3", "api-1", base.Add(2*time.Hour))
+	save("lr-4", "api-2", base.Add(time.Hour),
+		models.LintMessage{Severity: "error", Code: "nlgov:paths-kebab-case", RulesetVersion: "1.1"})
+
+	ids := func(results []models.LintResult) []string {
+		out := make([]string, len(results))
+		for i, res := range results {
+			out[i] = res.ID
+		}
+		return out
+	}
+
+	results, pagination, err := repo.ListLintResults(ctx, models.PageRequest{Page: 1, PerPage: 2}, models.LintResultFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lr-3", "lr-4"}, ids(results))
+	assert.Equal(t, 4, pagination.TotalRecords)
+	assert.Equal(t, 2, pagination.TotalPages)
+	results, _, err = repo.ListLintResults(ctx, models.PageRequest{}, models.LintResultFilter{})
+	require.NoError(t, err)
+	assert.Len(t, results, 4, "zonder paginering de eerste pagina van 10")
+
+	results, pagination, err = repo.ListLintResults(ctx, models.PageRequest{}, models.LintResultFilter{ApiID: "api-1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lr-3", "lr-2", "lr-1"}, ids(results))
+	assert.Equal(t, 3, pagination.TotalRecords)
+
+	since, until := base.Add(time.Hour), base.Add(2*time.Hour)
+	results, _, err = repo.ListLintResults(ctx, models.PageRequest{}, models.LintResultFilter{Since: &since, Until: &until})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lr-4", "lr-2"}, ids(results))
+
+	results, _, err = repo.ListLintResults(ctx, models.PageRequest{}, models.LintResultFilter{ApiID: "api-1", Severity: "ERROR"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"lr-1"}, ids(results))
+	require.Len(t, results[0].Messages, 1, "alleen de passende meldingen")
+	assert.Equal(t, "nlgov:paths-kebab-case", results[0].Messages[0].Code)
+
+	results, _, err = repo.ListLintResults(ctx, models.PageRequest{}, models.LintResultFilter{ApiID: "api-1", Code: "nlgov:info-contact", RulesetVersion: "1.1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lr-2"}, ids(results))
+
+	latest, err := repo.GetLatestLintResult(ctx, "api-1")
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	assert.Equal(t, "lr-3", latest.ID)
+	latest, err = repo.GetLatestLintResult(ctx, "onbekend")
+	require.NoError(t, err)
+	assert.Nil(t, latest)
+}
+
+func TestApiRepository_ChangesAndUpdatedSince(t *testing.T) {
+	db := setupDB(t)
+	repo := repositories.NewApiRepository(db)
+	ctx := context.Background()
+
+	settled := time.Now()
+	for _, changeType := range []string{models.ApiChangeCreated, models.ApiChangeUpdated, models.ApiChangeDeleted} {
+		require.NoError(t, repo.SaveApiChange(ctx, &models.ApiChange{ApiID: "api-1", Type: changeType, OccurredAt: settled.Add(-time.Minute)}))
+	}
+	changes, hasMore, err := repo.ListApiChanges(ctx, 0, 2, settled)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.True(t, hasMore)
+	assert.Equal(t, models.ApiChangeCreated, changes[0].Type)
+	changes, hasMore, err = repo.ListApiChanges(ctx, changes[1].Seq, 2, settled)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.False(t, hasMore)
+	assert.Equal(t, models.ApiChangeDeleted, changes[0].Type)
+
+	// Een recente wijziging houdt alles na zich tegen, ook een oudere met een
+	// hogere seq.
+	last := changes[0].Seq
+	require.NoError(t, repo.SaveApiChange(ctx, &models.ApiChange{ApiID: "api-2", Type: models.ApiChangeCreated, OccurredAt: settled}))
+	require.NoError(t, repo.SaveApiChange(ctx, &models.ApiChange{ApiID: "api-3", Type: models.ApiChangeCreated, OccurredAt: settled.Add(-time.Minute)}))
+	changes, hasMore, err = repo.ListApiChanges(ctx, last, 10, settled)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+	assert.False(t, hasMore)
+	changes, _, err = repo.ListApiChanges(ctx, last, 10, settled.Add(time.Second))
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, "api-2", changes[0].ApiID)
+
+	old := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	recent := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.Save(&models.Api{Id: "oud", OasUri: "oud", CreatedAt: &old, UpdatedAt: &old}))
+	require.NoError(t, repo.Save(&models.Api{Id: "recent", OasUri: "recent", CreatedAt: &old, UpdatedAt: &recent}))
+	since := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	apis, _, err := repo.GetApis(ctx, models.PageRequest{}, &models.ApiFiltersParams{UpdatedSince: &since}, models.ApiSort{}, models.ApiExpand{})
+	require.NoError(t, err)
+	require.Len(t, apis, 1)
+	assert.Equal(t, "recent", apis[0].Id)
+}
+
+func TestApiRepository_SaveReplacementIsAtomic(t *testing.T) {
+	db := setupDB(t)
+	repo := repositories.NewApiRepository(db)
+	ctx := context.Background()
+
+	orgURI := "https://example.org"
+	newApi := func(id string) *models.Api {
+		return &models.Api{
+			Id:             id,
+			OasUri:         "https://example.org/" + id + ".json",
+			Organisation:   &models.Organisation{Uri: orgURI, Label: "Org"},
+			OrganisationID: &orgURI,
+		}
+	}
+	require.NoError(t, repo.Save(newApi("oud")))
+	link := func(successorID string) func(*models.Api) {
+		return func(predecessor *models.Api) { predecessor.ReplacedByID = &successorID }
+	}
+
+	predecessor, err := repo.SaveReplacement(ctx, newApi("nieuw"), "oud", link("nieuw"))
+	require.NoError(t, err)
+	require.NotNil(t, predecessor.ReplacedByID)
+	assert.Equal(t, "nieuw", *predecessor.ReplacedByID)
+	assert.Equal(t, int64(2), predecessor.Revision)
+
+	// Een tweede opvolger voor dezelfde voorganger wordt niet opgeslagen.
+	_, err = repo.SaveReplacement(ctx, newApi("nog-nieuwer"), "oud", link("nog-nieuwer"))
+	assert.ErrorIs(t, err, repositories.ErrAlreadyReplaced)
+	got, err := repo.GetApiByID(ctx, "nog-nieuwer")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+	stored, err := repo.GetApiByID(ctx, "oud")
+	require.NoError(t, err)
+	assert.Equal(t, "nieuw", *stored.ReplacedByID)
+
+	_, err = repo.SaveReplacement(ctx, newApi("wees"), "onbekend", link("wees"))
+	assert.ErrorIs(t, err, repositories.ErrRevisionConflict)
+	got, err = repo.GetApiByID(ctx, "wees")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestApiRepository_ReviewStatusComesFromColumnDefault(t *testing.T) {
+	db := setupDB(t)
+	repo := repositories.NewApiRepository(db)
+	ctx := context.Background()
+
+	orgURI := "https://example.org"
+	api := &models.Api{Id: "a1", OasUri: "u1", Organisation: &models.Organisation{Uri: orgURI, Label: "Org"}, OrganisationID: &orgURI}
+	require.NoError(t, repo.Save(api))
+	assert.Equal(t, models.ReviewStatusApproved, api.ReviewStatus)
+
+	// UpdateApi schrijft de status die de aanroeper meegeeft.
+	api.ReviewStatus = models.ReviewStatusPending
+	require.NoError(t, repo.UpdateApi(ctx, *api))
+	got, err := repo.GetApiByID(ctx, "a1")
+	require.NoError(t, err)
+	assert.Equal(t, models.ReviewStatusPending, got.ReviewStatus)
+}
+
+func TestApiRepository_CompleteTransferIsCompareAndSwap(t *testing.T) {
+	db := setupDB(t)
+	repo := repositories.NewApiRepository(db)
+	ctx := context.Background()
+
+	from, to := "https://example.org/oud", "https://example.org/nieuw"
+	require.NoError(t, repo.Save(&models.Api{Id: "a1", OasUri: "u1", Organisation: &models.Organisation{Uri: from, Label: "Oud"}, OrganisationID: &from}))
+	require.NoError(t, repo.SaveOrganisatie(&models.Organisation{Uri: to, Label: "Nieuw"}))
+	transfer := &models.ApiTransfer{ID: "t1", ApiID: "a1", FromOrganisationUri: from, ToOrganisationUri: to, Status: models.ApiTransferCompleted}
+
+	// Een verouderde revisie zet niets over.
+	assert.ErrorIs(t, repo.CompleteTransfer(ctx, transfer, 7), repositories.ErrRevisionConflict)
+	require.NoError(t, repo.CompleteTransfer(ctx, transfer, 1))
+	got, err := repo.GetApiByID(ctx, "a1")
+	require.NoError(t, err)
+	assert.Equal(t, to, *got.OrganisationID)
+	assert.Equal(t, int64(2), got.Revision)
+
+	// Een tweede overdracht vanaf de oude organisatie vindt de API niet meer.
+	assert.ErrorIs(t, repo.CompleteTransfer(ctx, transfer, 2), repositories.ErrRevisionConflict)
+}
@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IdempotencyRepository interface {
+	// ReserveIdempotencyRecord legt rec vast en meldt false als er al een
+	// record met hetzelfde id bestaat.
+	ReserveIdempotencyRecord(ctx context.Context, rec *models.IdempotencyRecord) (bool, error)
+	FindIdempotencyRecord(ctx context.Context, id string) (*models.IdempotencyRecord, error)
+	// CompleteIdempotencyRecord slaat de response in rec op bij het record met
+	// rec.ID.
+	CompleteIdempotencyRecord(ctx context.Context, rec *models.IdempotencyRecord) error
+	DeleteIdempotencyRecord(ctx context.Context, id string) error
+	DeleteIdempotencyRecordsBefore(ctx context.Context, before time.Time) error
+}
+
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+func (r *idempotencyRepository) ReserveIdempotencyRecord(ctx context.Context, rec *models.IdempotencyRecord) (bool, error) {
+	res := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(rec)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (r *idempotencyRepository) FindIdempotencyRecord(ctx context.Context, id string) (*models.IdempotencyRecord, error) {
+	var rec models.IdempotencyRecord
+	if err := r.db.WithContext(ctx).First(&rec, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func (r *idempotencyRepository) CompleteIdempotencyRecord(ctx context.Context, rec *models.IdempotencyRecord) error {
+	return r.db.WithContext(ctx).
+		Model(&models.IdempotencyRecord{}).
+		Where("id = ?", rec.ID).
+		Select("Status", "ContentType", "Headers", "Body").
+		Updates(rec).Error
+}
+
+func (r *idempotencyRepository) DeleteIdempotencyRecord(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Delete(&models.IdempotencyRecord{}, "id = ?", id).Error
+}
+
+func (r *idempotencyRepository) DeleteIdempotencyRecordsBefore(ctx context.Context, before time.Time) error {
+	return r.db.WithContext(ctx).Delete(&models.IdempotencyRecord{}, "created_at < ?", before).Error
+}
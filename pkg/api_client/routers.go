@@ -4,8 +4,12 @@ import (
 	"net/http"
 
 	apispec "github.com/developer-overheid-nl/don-api-register/api"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/auth"
 	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/handler"
 	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/problem"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/httpcache"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/idempotency"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/ratelimit"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/loopfz/gadgeto/tonic"
@@ -20,6 +24,12 @@ var (
 		"",
 	)
 
+	idempotencyKeyHeaderOption = fizz.Header(
+		idempotency.Header,
+		"Unieke sleutel per request; een herhaling met dezelfde sleutel en body krijgt de oorspronkelijke response",
+		"",
+	)
+
 	apiVersionResponseHeader = &openapi.ResponseHeader{
 		Name:        "API-Version",
 		Description: "De API-versie van de response",
@@ -34,6 +44,22 @@ var (
 		nil,
 	)
 
+	unauthorizedResponse = fizz.Response(
+		"401",
+		"Missing or invalid bearer token",
+		problem.APIError{},
+		[]*openapi.ResponseHeader{apiVersionResponseHeader},
+		nil,
+	)
+
+	forbiddenResponse = fizz.Response(
+		"403",
+		"Insufficient scope",
+		problem.APIError{},
+		[]*openapi.ResponseHeader{apiVersionResponseHeader},
+		nil,
+	)
+
 	notFoundResponse = fizz.Response(
 		"404",
 		"Resource not found",
@@ -43,23 +69,129 @@ var (
 	)
 )
 
-func NewRouter(apiVersion string, controller *handler.APIsAPIController) *fizz.Fizz {
+// RouterOption configureert optionele onderdelen van de router.
+type RouterOption func(*routerConfig)
+
+type routerConfig struct {
+	authenticator *auth.Authenticator
+	apiKeys       *handler.ApiKeysController
+	rateLimiter   *ratelimit.Limiter
+	proxies       []string
+	idempotency   *idempotency.Store
+	cache         httpcache.Config
+}
+
+// WithAuthenticator dwingt bearer tokens en scopes af op de private endpoints.
+// Zonder authenticator worden de private endpoints niet afgeschermd; dit is
+// alleen bedoeld voor tests.
+func WithAuthenticator(a *auth.Authenticator) RouterOption {
+	return func(cfg *routerConfig) {
+		cfg.authenticator = a
+	}
+}
+
+// WithApiKeys activeert API keys op de publieke endpoints en de beheer-
+// endpoints onder /v1/api-keys.
+func WithApiKeys(c *handler.ApiKeysController) RouterOption {
+	return func(cfg *routerConfig) {
+		cfg.apiKeys = c
+	}
+}
+
+// WithRateLimiter begrenst het aantal requests per client. De limiter draait
+// na de authenticatie, zodat hij op API key of token-subject kan sleutelen;
+// mislukte authenticaties tellen per IP-adres mee.
+func WithRateLimiter(l *ratelimit.Limiter) RouterOption {
+	return func(cfg *routerConfig) {
+		cfg.rateLimiter = l
+	}
+}
+
+// WithTrustedProxies bepaalt achter welke proxies X-Forwarded-For gevolgd
+// wordt voor het IP-adres van de aanroeper. Zonder deze optie telt alleen het
+// adres van de verbinding, zodat clients hun adres niet kunnen vervalsen.
+func WithTrustedProxies(proxies []string) RouterOption {
+	return func(cfg *routerConfig) {
+		cfg.proxies = proxies
+	}
+}
+
+// WithIdempotency maakt POST-requests met een Idempotency-Key herhaalbaar.
+func WithIdempotency(s *idempotency.Store) RouterOption {
+	return func(cfg *routerConfig) {
+		cfg.idempotency = s
+	}
+}
+
+// WithCacheControl zet per groep leesendpoints een Cache-Control header.
+// Conditionele GETs met ETag en Last-Modified werken ook zonder deze optie.
+func WithCacheControl(c httpcache.Config) RouterOption {
+	return func(cfg *routerConfig) {
+		cfg.cache = c
+	}
+}
+
+// idempotent draait na de authenticatie, zodat sleutels per aanroeper gelden.
+func (cfg *routerConfig) idempotent() gin.HandlerFunc {
+	if cfg.idempotency == nil {
+		return func(c *gin.Context) {}
+	}
+	return cfg.idempotency.Middleware()
+}
+
+// optionalAuth herkent een meegestuurde API key of bearer token op de
+// publieke endpoints, zonder anonieme requests te weigeren.
+func (cfg *routerConfig) optionalAuth() gin.HandlerFunc {
+	var keys auth.APIKeyValidator
+	if cfg.apiKeys != nil {
+		keys = cfg.apiKeys.Service
+	}
+	return cfg.limited(auth.Optional(cfg.authenticator, keys))
+}
+
+func (cfg *routerConfig) requireScopes(scopes ...string) gin.HandlerFunc {
+	if cfg.authenticator == nil {
+		return cfg.limited(func(c *gin.Context) {})
+	}
+	return cfg.limited(auth.RequireScopes(cfg.authenticator, scopes...))
+}
+
+// limited voert authn en daarna de rate limiter uit binnen één handler.
+// authn mag daarvoor zelf geen c.Next() aanroepen.
+func (cfg *routerConfig) limited(authn gin.HandlerFunc) gin.HandlerFunc {
+	if cfg.rateLimiter == nil {
+		return authn
+	}
+	return cfg.rateLimiter.Guard(authn)
+}
+
+func NewRouter(apiVersion string, controller *handler.APIsAPIController, opts ...RouterOption) *fizz.Fizz {
+	cfg := &routerConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
 	//gin.SetMode(gin.ReleaseMode)
 	g := gin.Default()
+	// Standaard vertrouwt gin elke proxy; dan kan iedere client via
+	// X-Forwarded-For een ander IP-adres opgeven.
+	if err := g.SetTrustedProxies(cfg.proxies); err != nil {
+		panic(err)
+	}
 
 	// Configure CORS to allow access from everywhere
 	config := cors.DefaultConfig()
 	config.AllowAllOrigins = true
 	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization", "API-Version"}
-	config.ExposeHeaders = []string{"API-Version"}
+	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization", "API-Version", auth.APIKeyHeader, idempotency.Header, "If-Match", "If-None-Match", "If-Modified-Since"}
+	config.ExposeHeaders = []string{"API-Version", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After", idempotency.ReplayedHeader, "ETag"}
 	g.Use(cors.New(config))
 
 	g.Use(APIVersionMiddleware(apiVersion))
 	f := fizz.NewFromEngine(g)
 
 	apiGroup := f.Group("/v1", "APIs", "Endpoints for listing and managing APIs.")
-	publicApis := apiGroup.Group("", "Public endpoints", "Public endpoints, accessible with an API key or client credentials token.")
+	publicApis := apiGroup.Group("", "Public endpoints", "Public endpoints, accessible with an API key or client credentials token.", cfg.optionalAuth())
 	privateApis := apiGroup.Group("", "Private endpoints", "Private endpoints of the API register, accessible with a client credentials token.")
 	publicApis.GET("/apis/_search",
 		[]fizz.OperationOption{
@@ -90,6 +222,7 @@ func NewRouter(apiVersion string, controller *handler.APIsAPIController) *fizz.F
 			apiVersionHeaderOption,
 			badRequestResponse,
 		},
+		httpcache.CacheControl(cfg.cache.Lists),
 		tonic.Handler(controller.ListApis, 200),
 	)
 
@@ -111,6 +244,24 @@ func NewRouter(apiVersion string, controller *handler.APIsAPIController) *fizz.F
 		tonic.Handler(controller.ListApiFilters, 200),
 	)
 
+	publicApis.GET("/changes",
+		[]fizz.OperationOption{
+			fizz.ID("listChanges"),
+			fizz.Summary("List changes"),
+			fizz.Description("Returns created, updated, lifecycle-changed and deleted events in order. Pass the returned next token as since to resume."),
+			fizz.WithOptionalSecurity(),
+			fizz.Security(&openapi.SecurityRequirement{
+				"apiKey": []string{},
+			}),
+			fizz.Security(&openapi.SecurityRequirement{
+				"clientCredentials": {"apis:read"},
+			}),
+			apiVersionHeaderOption,
+			badRequestResponse,
+		},
+		tonic.Handler(controller.ListChanges, 200),
+	)
+
 	retrieveApiJson := tonic.Handler(controller.RetrieveApi, 200)
 	retrieveApiJsonLd := tonic.Handler(controller.RetrieveApiJsonLd, 200)
 	publicApis.GET("/apis/:id",
@@ -126,8 +277,10 @@ func NewRouter(apiVersion string, controller *handler.APIsAPIController) *fizz.F
 				"clientCredentials": {"apis:read"},
 			}),
 			apiVersionHeaderOption,
+			badRequestResponse,
 			notFoundResponse,
 		},
+		httpcache.CacheControl(cfg.cache.Apis),
 		func(c *gin.Context) {
 			c.Writer.Header().Add("Vary", "Accept")
 			if handler.AcceptsJsonLd(c.GetHeader("Accept")) {
@@ -153,9 +306,164 @@ func NewRouter(apiVersion string, controller *handler.APIsAPIController) *fizz.F
 			apiVersionHeaderOption,
 			notFoundResponse,
 		},
+		httpcache.CacheControl(cfg.cache.Artifacts),
 		tonic.Handler(controller.GetPostman, 200),
 	)
 
+	publicApis.GET("/apis/:id/bruno",
+		[]fizz.OperationOption{
+			fizz.ID("getBruno"),
+			fizz.Summary("Download Bruno collection"),
+			fizz.Description("Returns the generated Bruno collection as a zip archive."),
+			fizz.WithOptionalSecurity(),
+			fizz.Security(&openapi.SecurityRequirement{
+				"apiKey": []string{},
+			}),
+			fizz.Security(&openapi.SecurityRequirement{
+				"clientCredentials": {"apis:read"},
+			}),
+			apiVersionHeaderOption,
+			notFoundResponse,
+		},
+		httpcache.CacheControl(cfg.cache.Artifacts),
+		tonic.Handler(controller.GetBruno, 200),
+	)
+
+	publicApis.GET("/apis/:id/arazzo",
+		[]fizz.OperationOption{
+			fizz.ID("getArazzo"),
+			fizz.Summary("Download Arazzo document"),
+			fizz.Description("Returns the registered Arazzo document as JSON or YAML. Without format the document is returned as it was registered."),
+			fizz.WithOptionalSecurity(),
+			fizz.Security(&openapi.SecurityRequirement{
+				"apiKey": []string{},
+			}),
+			fizz.Security(&openapi.SecurityRequirement{
+				"clientCredentials": {"apis:read"},
+			}),
+			apiVersionHeaderOption,
+			badRequestResponse,
+			notFoundResponse,
+		},
+		httpcache.CacheControl(cfg.cache.Artifacts),
+		tonic.Handler(controller.GetArazzo, 200),
+	)
+
+	publicApis.GET("/apis/:id/arazzo/markdown",
+		[]fizz.OperationOption{
+			fizz.ID("getArazzoMarkdown"),
+			fizz.Summary("Download Arazzo markdown"),
+			fizz.Description("Returns the Markdown rendering of the Arazzo workflows."),
+			fizz.WithOptionalSecurity(),
+			fizz.Security(&openapi.SecurityRequirement{
+				"apiKey": []string{},
+			}),
+			fizz.Security(&openapi.SecurityRequirement{
+				"clientCredentials": {"apis:read"},
+			}),
+			apiVersionHeaderOption,
+			notFoundResponse,
+		},
+		httpcache.CacheControl(cfg.cache.Artifacts),
+		tonic.Handler(controller.GetArazzoMarkdown, 200),
+	)
+
+	publicApis.GET("/apis/:id/arazzo/mermaid",
+		[]fizz.OperationOption{
+			fizz.ID("getArazzoMermaid"),
+			fizz.Summary("Download Arazzo mermaid"),
+			fizz.Description("Returns the Mermaid diagram of the Arazzo workflows."),
+			fizz.WithOptionalSecurity(),
+			fizz.Security(&openapi.SecurityRequirement{
+				"apiKey": []string{},
+			}),
+			fizz.Security(&openapi.SecurityRequirement{
+				"clientCredentials": {"apis:read"},
+			}),
+			apiVersionHeaderOption,
+			notFoundResponse,
+		},
+		httpcache.CacheControl(cfg.cache.Artifacts),
+		tonic.Handler(controller.GetArazzoMermaid, 200),
+	)
+
+	publicApis.GET("/apis/:id/artifacts",
+		[]fizz.OperationOption{
+			fizz.ID("listArtifacts"),
+			fizz.Summary("List artifacts of an API"),
+			fizz.Description("Returns the metadata of every stored artifact of an API, without its content."),
+			fizz.WithOptionalSecurity(),
+			fizz.Security(&openapi.SecurityRequirement{
+				"apiKey": []string{},
+			}),
+			fizz.Security(&openapi.SecurityRequirement{
+				"clientCredentials": {"apis:read"},
+			}),
+			apiVersionHeaderOption,
+			notFoundResponse,
+		},
+		httpcache.CacheControl(cfg.cache.Apis),
+		tonic.Handler(controller.ListArtifacts, 200),
+	)
+
+	publicApis.GET("/apis/:id/artifacts/:artifactId",
+		[]fizz.OperationOption{
+			fizz.ID("getArtifact"),
+			fizz.Summary("Download artifact"),
+			fizz.Description("Returns the content of a single artifact of an API."),
+			fizz.WithOptionalSecurity(),
+			fizz.Security(&openapi.SecurityRequirement{
+				"apiKey": []string{},
+			}),
+			fizz.Security(&openapi.SecurityRequirement{
+				"clientCredentials": {"apis:read"},
+			}),
+			apiVersionHeaderOption,
+			notFoundResponse,
+		},
+		httpcache.CacheControl(cfg.cache.Artifacts),
+		tonic.Handler(controller.GetArtifactByID, 200),
+	)
+
+	publicApis.GET("/apis/:id/lint-results",
+		[]fizz.OperationOption{
+			fizz.ID("listApiLintResults"),
+			fizz.Summary("List lint results of an API"),
+			fizz.Description("Returns the lint history of an API, newest first. Supports page/perPage or cursor/limit pagination and filters on message severity, rule code, ruleset version and creation time."),
+			fizz.WithOptionalSecurity(),
+			fizz.Security(&openapi.SecurityRequirement{
+				"apiKey": []string{},
+			}),
+			fizz.Security(&openapi.SecurityRequirement{
+				"clientCredentials": {"apis:read"},
+			}),
+			apiVersionHeaderOption,
+			badRequestResponse,
+			notFoundResponse,
+		},
+		httpcache.CacheControl(cfg.cache.Apis),
+		tonic.Handler(controller.ListApiLintResults, 200),
+	)
+
+	publicApis.GET("/apis/:id/lint-results/latest",
+		[]fizz.OperationOption{
+			fizz.ID("getLatestLintResult"),
+			fizz.Summary("Get latest lint result of an API"),
+			fizz.Description("Returns the most recent lint result of an API with all its messages."),
+			fizz.WithOptionalSecurity(),
+			fizz.Security(&openapi.SecurityRequirement{
+				"apiKey": []string{},
+			}),
+			fizz.Security(&openapi.SecurityRequirement{
+				"clientCredentials": {"apis:read"},
+			}),
+			apiVersionHeaderOption,
+			notFoundResponse,
+		},
+		httpcache.CacheControl(cfg.cache.Apis),
+		tonic.Handler(controller.GetLatestLintResult, 200),
+	)
+
 	publicApis.GET("/apis/:id/oas/:version",
 		[]fizz.OperationOption{
 			fizz.ID("getOasVersion"),
@@ -172,17 +480,18 @@ func NewRouter(apiVersion string, controller *handler.APIsAPIController) *fizz.F
 			badRequestResponse,
 			notFoundResponse,
 		},
+		httpcache.CacheControl(cfg.cache.Artifacts),
 		tonic.Handler(controller.GetOas, 200),
 	)
 
 	orgGroup := f.Group("/v1", "Organisations", "Endpoints for listing and managing organisations.")
-	publicOrganisations := orgGroup.Group("", "Public endpoints", "Public endpoints, accessible with an API key or client credentials token.")
+	publicOrganisations := orgGroup.Group("", "Public endpoints", "Public endpoints, accessible with an API key or client credentials token.", cfg.optionalAuth())
 	privateOrganisations := orgGroup.Group("", "Private endpoints", "Private endpoints of the API register, accessible with a client credentials token.")
 	publicOrganisations.GET("/organisations",
 		[]fizz.OperationOption{
 			fizz.ID("listOrganisations"),
 			fizz.Summary("List organisations"),
-			fizz.Description("Returns a list of organisations included in the register."),
+			fizz.Description("Returns a paginated list of organisations included in the register."),
 			fizz.WithOptionalSecurity(),
 			fizz.Security(&openapi.SecurityRequirement{
 				"apiKey": []string{},
@@ -199,31 +508,102 @@ func NewRouter(apiVersion string, controller *handler.APIsAPIController) *fizz.F
 			fizz.ID("createOrganisation"),
 			fizz.Summary("Create organisation"),
 			fizz.Description("Create a new organisation."),
+			fizz.Security(&openapi.SecurityRequirement{
+				"clientCredentials": {"organisations:write"},
+			}),
+			apiVersionHeaderOption,
+			unauthorizedResponse,
+			forbiddenResponse,
+			idempotencyKeyHeaderOption,
+			badRequestResponse,
+		},
+		cfg.requireScopes("organisations:write"),
+		cfg.idempotent(),
+		tonic.Handler(controller.CreateOrganisation, 201),
+	)
+	// De URI van een organisatie bevat slashes; daarom een catch-all parameter.
+	publicOrganisations.GET("/organisations/*uri",
+		[]fizz.OperationOption{
+			fizz.ID("retrieveOrganisation"),
+			fizz.Summary("Retrieve organisation"),
+			fizz.Description("Returns an organisation with the number of APIs per lifecycle status."),
 			fizz.WithOptionalSecurity(),
 			fizz.Security(&openapi.SecurityRequirement{
 				"apiKey": []string{},
 			}),
+			fizz.Security(&openapi.SecurityRequirement{
+				"clientCredentials": {"organisations:read"},
+			}),
+			apiVersionHeaderOption,
+			notFoundResponse,
+		},
+		tonic.Handler(controller.RetrieveOrganisation, 200),
+	)
+	privateOrganisations.PUT("/organisations/*uri",
+		[]fizz.OperationOption{
+			fizz.ID("updateOrganisation"),
+			fizz.Summary("Update organisation"),
+			fizz.Description("Updates the label of an organisation."),
 			fizz.Security(&openapi.SecurityRequirement{
 				"clientCredentials": {"organisations:write"},
 			}),
 			apiVersionHeaderOption,
+			unauthorizedResponse,
+			forbiddenResponse,
 			badRequestResponse,
+			notFoundResponse,
 		},
-		tonic.Handler(controller.CreateOrganisation, 201),
+		cfg.requireScopes("organisations:write"),
+		tonic.Handler(controller.UpdateOrganisation, 200),
+	)
+	privateOrganisations.DELETE("/organisations/*uri",
+		[]fizz.OperationOption{
+			fizz.ID("deleteOrganisation"),
+			fizz.Summary("Delete organisation"),
+			fizz.Description("Deletes an organisation. Refused with 409 while APIs still reference the organisation."),
+			fizz.Security(&openapi.SecurityRequirement{
+				"clientCredentials": {"organisations:write"},
+			}),
+			apiVersionHeaderOption,
+			unauthorizedResponse,
+			forbiddenResponse,
+			notFoundResponse,
+		},
+		cfg.requireScopes("organisations:write"),
+		tonic.Handler(controller.DeleteOrganisation, 204),
+	)
+	privateOrganisations.POST("/organisations/_merge",
+		[]fizz.OperationOption{
+			fizz.ID("mergeOrganisations"),
+			fizz.Summary("Merge organisations"),
+			fizz.Description("Moves all APIs and API keys from a duplicate organisation to the canonical one and deletes the duplicate."),
+			fizz.Security(&openapi.SecurityRequirement{
+				"clientCredentials": {auth.AdminScope},
+			}),
+			apiVersionHeaderOption,
+			unauthorizedResponse,
+			forbiddenResponse,
+			badRequestResponse,
+			notFoundResponse,
+		},
+		cfg.requireScopes(auth.AdminScope),
+		tonic.Handler(controller.MergeOrganisations, 200),
 	)
 
 	privateApis.GET("/lint-results",
 		[]fizz.OperationOption{
 			fizz.ID("listLintResults"),
 			fizz.Summary("List lint results"),
-			fizz.Description("Returns all stored lint results for registered APIs."),
-			fizz.WithOptionalSecurity(),
+			fizz.Description("Returns the stored lint results of registered APIs, newest first. Supports page/perPage or cursor/limit pagination and filters on apiId and creation time."),
 			fizz.Security(&openapi.SecurityRequirement{
 				"clientCredentials": {"apis:read"},
 			}),
 			apiVersionHeaderOption,
+			unauthorizedResponse,
+			forbiddenResponse,
 			badRequestResponse,
 		},
+		cfg.requireScopes("apis:read"),
 		tonic.Handler(controller.ListLintResults, 200),
 	)
 
@@ -232,32 +612,279 @@ func NewRouter(apiVersion string, controller *handler.APIsAPIController) *fizz.F
 			fizz.ID("createApi"),
 			fizz.Summary("Register API"),
 			fizz.Description("Registers a new API in the register from its OpenAPI document."),
-			fizz.WithOptionalSecurity(),
 			fizz.Security(&openapi.SecurityRequirement{
 				"clientCredentials": {"apis:write"},
 			}),
 			apiVersionHeaderOption,
+			unauthorizedResponse,
+			forbiddenResponse,
+			idempotencyKeyHeaderOption,
 			badRequestResponse,
 		},
+		cfg.requireScopes("apis:write"),
+		cfg.idempotent(),
 		tonic.Handler(controller.CreateApiFromOas, 201),
 	)
 
+	privateApis.POST("/apis/_bulk",
+		[]fizz.OperationOption{
+			fizz.ID("bulkCreateApis"),
+			fizz.Summary("Register APIs in bulk"),
+			fizz.Description("Registers up to 100 APIs in one request. Every item is processed on its own and gets its own result: created, exists, invalid, fetch_failed, forbidden or failed."),
+			fizz.Security(&openapi.SecurityRequirement{
+				"clientCredentials": {"apis:write"},
+			}),
+			apiVersionHeaderOption,
+			unauthorizedResponse,
+			forbiddenResponse,
+			badRequestResponse,
+		},
+		cfg.requireScopes("apis:write"),
+		tonic.Handler(controller.BulkCreateApis, 207),
+	)
+
+	privateApis.POST("/apis/_validate",
+		[]fizz.OperationOption{
+			fizz.ID("validateApi"),
+			fizz.Summary("Validate API registration"),
+			fizz.Description("Dry run of POST /apis: fetches, validates and lints the OpenAPI document and returns what the registration would produce. Nothing is stored, generated or indexed."),
+			fizz.Security(&openapi.SecurityRequirement{
+				"clientCredentials": {"apis:write"},
+			}),
+			apiVersionHeaderOption,
+			unauthorizedResponse,
+			forbiddenResponse,
+			badRequestResponse,
+		},
+		cfg.requireScopes("apis:write"),
+		tonic.Handler(controller.ValidateApiFromOas, 200),
+	)
+
 	privateApis.PUT("/apis/:id",
 		[]fizz.OperationOption{
 			fizz.ID("updateApi"),
 			fizz.Summary("Update API"),
 			fizz.Description("Updates an existing API by id."),
-			fizz.WithOptionalSecurity(),
 			fizz.Security(&openapi.SecurityRequirement{
 				"clientCredentials": {"apis:write"},
 			}),
 			apiVersionHeaderOption,
+			unauthorizedResponse,
+			forbiddenResponse,
 			badRequestResponse,
 			notFoundResponse,
 		},
+		cfg.requireScopes("apis:write"),
 		tonic.Handler(controller.UpdateApi, 200),
 	)
 
+	privateApis.PATCH("/apis/:id",
+		[]fizz.OperationOption{
+			fizz.ID("patchApi"),
+			fizz.Summary("Patch API metadata"),
+			fizz.Description("Updates register-owned metadata (contact, docsUrl, repositoryUri, sunset, deprecated) with a JSON Merge Patch without re-fetching the OAS. Values set here survive the OAS refresh; null removes the override."),
+			fizz.Security(&openapi.SecurityRequirement{
+				"clientCredentials": {"apis:write"},
+			}),
+			apiVersionHeaderOption,
+			unauthorizedResponse,
+			forbiddenResponse,
+			badRequestResponse,
+			notFoundResponse,
+		},
+		cfg.requireScopes("apis:write"),
+		tonic.Handler(controller.PatchApi, 200),
+	)
+
+	privateApis.DELETE("/apis/:id",
+		[]fizz.OperationOption{
+			fizz.ID("deleteApi"),
+			fizz.Summary("Delete API"),
+			fizz.Description("Soft-deletes an API. The API disappears from lists, search and filters and can be restored by an administrator during the retention period."),
+			fizz.Security(&openapi.SecurityRequirement{
+				"clientCredentials": {"apis:write"},
+			}),
+			apiVersionHeaderOption,
+			unauthorizedResponse,
+			forbiddenResponse,
+			notFoundResponse,
+		},
+		cfg.requireScopes("apis:write"),
+		tonic.Handler(controller.DeleteApi, 204),
+	)
+
+	privateApis.POST("/apis/:id/restore",
+		[]fizz.OperationOption{
+			fizz.ID("restoreApi"),
+			fizz.Summary("Restore API"),
+			fizz.Description("Restores a deleted API within the retention period."),
+			fizz.Security(&openapi.SecurityRequirement{
+				"clientCredentials": {auth.AdminScope},
+			}),
+			apiVersionHeaderOption,
+			unauthorizedResponse,
+			forbiddenResponse,
+			notFoundResponse,
+		},
+		cfg.requireScopes(auth.AdminScope),
+		tonic.Handler(controller.RestoreApi, 200),
+	)
+
+	privateApis.POST("/apis/:id/transfer",
+		[]fizz.OperationOption{
+			fizz.ID("transferApi"),
+			fizz.Summary("Transfer API ownership"),
+			fizz.Description("Transfers an API to another organisation. Both organisations must consent: the first call records a pending transfer, the call on behalf of the other organisation completes it. Administrators, or callers mapped to both organisations, complete the transfer at once."),
+			fizz.Security(&openapi.SecurityRequirement{
+				"clientCredentials": {"apis:write"},
+			}),
+			apiVersionHeaderOption,
+			unauthorizedResponse,
+			forbiddenResponse,
+			badRequestResponse,
+			notFoundResponse,
+		},
+		cfg.requireScopes("apis:write"),
+		tonic.Handler(controller.TransferApi, 200),
+	)
+
+	privateApis.GET("/reviews",
+		[]fizz.OperationOption{
+			fizz.ID("listReviews"),
+			fizz.Summary("List review queue"),
+			fizz.Description("Returns the registrations awaiting review, or the rejected registrations with status=rejected, with their lint results and generated artifacts."),
+			fizz.Security(&openapi.SecurityRequirement{
+				"clientCredentials": {auth.ReviewScope},
+			}),
+			apiVersionHeaderOption,
+			unauthorizedResponse,
+			forbiddenResponse,
+			badRequestResponse,
+		},
+		cfg.requireScopes(auth.ReviewScope),
+		tonic.Handler(controller.ListReviews, 200),
+	)
+
+	privateApis.POST("/apis/:id/approve",
+		[]fizz.OperationOption{
+			fizz.ID("approveApi"),
+			fizz.Summary("Approve API registration"),
+			fizz.Description("Approves a pending registration. The API becomes visible in listings, filters, JSON-LD and the search index."),
+			fizz.Security(&openapi.SecurityRequirement{
+				"clientCredentials": {auth.ReviewScope},
+			}),
+			apiVersionHeaderOption,
+			unauthorizedResponse,
+			forbiddenResponse,
+			notFoundResponse,
+		},
+		cfg.requireScopes(auth.ReviewScope),
+		tonic.Handler(controller.ApproveApi, 200),
+	)
+
+	privateApis.POST("/apis/:id/reject",
+		[]fizz.OperationOption{
+			fizz.ID("rejectApi"),
+			fizz.Summary("Reject API registration"),
+			fizz.Description("Rejects a pending registration with a reason. The submitter can update the API with PUT to put it back in the review queue."),
+			fizz.Security(&openapi.SecurityRequirement{
+				"clientCredentials": {auth.ReviewScope},
+			}),
+			apiVersionHeaderOption,
+			unauthorizedResponse,
+			forbiddenResponse,
+			badRequestResponse,
+			notFoundResponse,
+		},
+		cfg.requireScopes(auth.ReviewScope),
+		tonic.Handler(controller.RejectApi, 200),
+	)
+
+	privateApis.GET("/apis/:id/audit",
+		[]fizz.OperationOption{
+			fizz.ID("listApiAudit"),
+			fizz.Summary("List audit trail of an API"),
+			fizz.Description("Returns all recorded mutations of an API, newest first, with a field-level before/after diff."),
+			fizz.Security(&openapi.SecurityRequirement{
+				"clientCredentials": {"apis:read"},
+			}),
+			apiVersionHeaderOption,
+			unauthorizedResponse,
+			forbiddenResponse,
+			notFoundResponse,
+		},
+		cfg.requireScopes("apis:read"),
+		tonic.Handler(controller.ListApiAudit, 200),
+	)
+
+	privateApis.GET("/audit",
+		[]fizz.OperationOption{
+			fizz.ID("listAudit"),
+			fizz.Summary("List audit trail"),
+			fizz.Description("Returns the audit trail of all mutations, newest first. Filterable on API, organisation, actor, action and period."),
+			fizz.Security(&openapi.SecurityRequirement{
+				"clientCredentials": {auth.AdminScope},
+			}),
+			apiVersionHeaderOption,
+			unauthorizedResponse,
+			forbiddenResponse,
+			badRequestResponse,
+		},
+		cfg.requireScopes(auth.AdminScope),
+		tonic.Handler(controller.ListAudit, 200),
+	)
+
+	if cfg.apiKeys != nil {
+		keyGroup := f.Group("/v1", "API keys", "Endpoints for issuing and revoking API keys.")
+		keyGroup.POST("/api-keys",
+			[]fizz.OperationOption{
+				fizz.ID("createApiKey"),
+				fizz.Summary("Create API key"),
+				fizz.Description("Issues a new API key for a consumer. The key is only returned once."),
+				fizz.Security(&openapi.SecurityRequirement{
+					"clientCredentials": {auth.AdminScope},
+				}),
+				apiVersionHeaderOption,
+				unauthorizedResponse,
+				forbiddenResponse,
+				badRequestResponse,
+			},
+			cfg.requireScopes(auth.AdminScope),
+			tonic.Handler(cfg.apiKeys.CreateApiKey, 201),
+		)
+		keyGroup.GET("/api-keys",
+			[]fizz.OperationOption{
+				fizz.ID("listApiKeys"),
+				fizz.Summary("List API keys"),
+				fizz.Description("Returns all issued API keys with their usage counters."),
+				fizz.Security(&openapi.SecurityRequirement{
+					"clientCredentials": {auth.AdminScope},
+				}),
+				apiVersionHeaderOption,
+				unauthorizedResponse,
+				forbiddenResponse,
+			},
+			cfg.requireScopes(auth.AdminScope),
+			tonic.Handler(cfg.apiKeys.ListApiKeys, 200),
+		)
+		keyGroup.DELETE("/api-keys/:id",
+			[]fizz.OperationOption{
+				fizz.ID("revokeApiKey"),
+				fizz.Summary("Revoke API key"),
+				fizz.Description("Revokes an API key. Revoked keys are rejected immediately."),
+				fizz.Security(&openapi.SecurityRequirement{
+					"clientCredentials": {auth.AdminScope},
+				}),
+				apiVersionHeaderOption,
+				unauthorizedResponse,
+				forbiddenResponse,
+				notFoundResponse,
+			},
+			cfg.requireScopes(auth.AdminScope),
+			tonic.Handler(cfg.apiKeys.RevokeApiKey, 204),
+		)
+	}
+
 	// 6) OpenAPI documentatie
 	g.GET("/v1/openapi.json", serveOpenAPISpec)
 	g.HEAD("/v1/openapi.json", serveOpenAPISpec)
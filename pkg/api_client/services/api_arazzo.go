@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/openapi"
+	problem "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/problem"
+	toolslint "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/tools"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/models"
+	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
+	"sigs.k8s.io/yaml"
+)
+
+// fetchArazzo haalt het Arazzo-document uit een registratie op en valideert
+// het. Zonder Arazzo geeft hij nil.
+func fetchArazzo(ctx context.Context, input toolslint.ArazzoInput) (*openapi.ArazzoResult, error) {
+	input.Normalize()
+	if input.IsEmpty() {
+		return nil, nil
+	}
+	if err := checkArazzoURL(ctx, input.ArazzoUrl); err != nil {
+		return nil, err
+	}
+	res, err := openapi.FetchParseValidateArazzo(ctx, input, openapi.FetchOpts{})
+	if err != nil {
+		if input.ArazzoBody != "" {
+			return nil, problem.NewBadRequest("arazzoBody", "ongeldig Arazzo-document",
+				problem.InvalidParam{Name: "arazzoBody", Reason: err.Error()})
+		}
+		return nil, problem.NewBadRequest(input.ArazzoUrl, "ongeldig Arazzo-document",
+			problem.InvalidParam{Name: "arazzoUrl", Reason: err.Error()})
+	}
+	return res, nil
+}
+
+// setArazzo legt de herkomst van het Arazzo-document vast. Een als body
+// meegestuurd document heeft geen URL en wordt dus niet ververst.
+func setArazzo(api *models.Api, arazzo *openapi.ArazzoResult) {
+	if arazzo == nil {
+		return
+	}
+	api.ArazzoUri = arazzo.URL
+	api.ArazzoHash = arazzo.Hash
+}
+
+// arazzoToolsInput geeft de tools API de URL, zodat relatieve verwijzingen in
+// sourceDescriptions blijven werken; anders het document zelf.
+func arazzoToolsInput(arazzo *openapi.ArazzoResult) toolslint.ArazzoInput {
+	if arazzo.URL != "" {
+		return toolslint.ArazzoInput{ArazzoUrl: arazzo.URL}
+	}
+	return toolslint.ArazzoInput{ArazzoBody: string(arazzo.Raw)}
+}
+
+// persistArazzoArtifacts bewaart het Arazzo-document zoals aangeleverd en in
+// het andere formaat, en ruimt de vorige versies op.
+func (s *APIsAPIService) persistArazzoArtifacts(ctx context.Context, apiID string, arazzo *openapi.ArazzoResult) error {
+	var (
+		errs []error
+		keep []string
+	)
+	if id, err := s.saveArazzoArtifact(ctx, apiID, arazzo, arazzo.Format, "original", arazzo.Raw); err != nil {
+		errs = append(errs, err)
+	} else {
+		keep = append(keep, id)
+	}
+
+	other, data := "yaml", []byte(nil)
+	if arazzo.Format == "yaml" {
+		other, data = "json", arazzo.JSON
+	} else if converted, err := yaml.JSONToYAML(arazzo.JSON); err != nil {
+		errs = append(errs, fmt.Errorf("kan Arazzo niet als YAML renderen: %w", err))
+	} else {
+		data = converted
+	}
+	if data != nil {
+		if id, err := s.saveArazzoArtifact(ctx, apiID, arazzo, other, "converted", data); err != nil {
+			errs = append(errs, err)
+		} else {
+			keep = append(keep, id)
+		}
+	}
+
+	if len(errs) == 0 {
+		if err := s.repo.DeleteArtifactsByKind(ctx, apiID, "arazzo", keep); err != nil {
+			errs = append(errs, fmt.Errorf("kan oude Arazzo artifacts niet verwijderen: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *APIsAPIService) saveArazzoArtifact(ctx context.Context, apiID string, arazzo *openapi.ArazzoResult, format, source string, data []byte) (string, error) {
+	art := &models.ApiArtifact{
+		ID:          uuid.New().String(),
+		ApiID:       apiID,
+		Kind:        "arazzo",
+		Version:     arazzo.Version,
+		Format:      format,
+		Source:      source,
+		Filename:    "arazzo." + format,
+		ContentType: formatContentType(format),
+		Data:        data,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.repo.SaveArtifact(ctx, art); err != nil {
+		return "", fmt.Errorf("kan artifact %s opslaan: %w", art.Filename, err)
+	}
+	log.Printf("[arazzo] saved artifact id=%s api=%s format=%s source=%s", art.ID, apiID, format, source)
+	return art.ID, nil
+}
+
+// runArazzoTools bewaart het Arazzo-document en laat in g de markdown- en
+// mermaid-weergave genereren.
+func (s *APIsAPIService) runArazzoTools(ctx context.Context, g *errgroup.Group, apiID string, arazzo *openapi.ArazzoResult) {
+	if err := s.persistArazzoArtifacts(ctx, apiID, arazzo); err != nil {
+		log.Printf("[tools] persist arazzo artifacts failed: %v", err)
+	}
+	arazzoInput := arazzoToolsInput(arazzo)
+	g.Go(func() error {
+		if err := s.withRateLimit(ctx); err != nil {
+			return nil
+		}
+		data, ct, err := toolslint.ArazzoMarkdown(ctx, arazzoInput)
+		if err != nil {
+			log.Printf("[tools] arazzo markdown failed: %v", err)
+			return nil
+		}
+		art := &models.ApiArtifact{
+			ID:          uuid.New().String(),
+			ApiID:       apiID,
+			Kind:        "arazzo_markdown",
+			Format:      "markdown",
+			Source:      "tools",
+			Filename:    "arazzo.md",
+			ContentType: ct,
+			Data:        data,
+			CreatedAt:   time.Now(),
+		}
+		if err := s.repo.SaveArtifact(ctx, art); err != nil {
+			log.Printf("[tools] save arazzo markdown failed: %v", err)
+		} else {
+			log.Printf("[tools] saved arazzo markdown artifact id=%s api=%s", art.ID, apiID)
+			if err := s.repo.DeleteArtifactsByKind(ctx, apiID, "arazzo_markdown", []string{art.ID}); err != nil {
+				log.Printf("[tools] cleanup oude arazzo markdown artifacts mislukt api=%s: %v", apiID, err)
+			}
+		}
+		return nil
+	})
+
+	g.Go(func() error {
+		if err := s.withRateLimit(ctx); err != nil {
+			return nil
+		}
+		data, ct, err := toolslint.ArazzoMermaid(ctx, arazzoInput)
+		if err != nil {
+			log.Printf("[tools] arazzo mermaid failed: %v", err)
+			return nil
+		}
+		art := &models.ApiArtifact{
+			ID:          uuid.New().String(),
+			ApiID:       apiID,
+			Kind:        "arazzo_mermaid",
+			Format:      "mermaid",
+			Source:      "tools",
+			Filename:    "arazzo.mmd",
+			ContentType: ct,
+			Data:        data,
+			CreatedAt:   time.Now(),
+		}
+		if err := s.repo.SaveArtifact(ctx, art); err != nil {
+			log.Printf("[tools] save arazzo mermaid failed: %v", err)
+		} else {
+			log.Printf("[tools] saved arazzo mermaid artifact id=%s api=%s", art.ID, apiID)
+			if err := s.repo.DeleteArtifactsByKind(ctx, apiID, "arazzo_mermaid", []string{art.ID}); err != nil {
+				log.Printf("[tools] cleanup oude arazzo mermaid artifacts mislukt api=%s: %v", apiID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// applyArazzoRefresh verwerkt een gewijzigd Arazzo-document bij een
+// ongewijzigde OAS; lint, Postman en Bruno hoeven dan niet opnieuw.
+func (s *APIsAPIService) applyArazzoRefresh(ctx context.Context, api *models.Api, arazzo *openapi.ArazzoResult) error {
+	before := cloneApi(api)
+	setArazzo(api, arazzo)
+	if err := s.updateApi(ctx, api); err != nil {
+		return err
+	}
+	s.recordApiAudit(ctx, models.AuditActionApiRefreshed, before, api)
+
+	g, gctx := errgroup.WithContext(ctx)
+	s.runArazzoTools(gctx, g, api.Id, arazzo)
+	_ = g.Wait()
+	return nil
+}
+
+// GetArazzoDocument geeft de metadata van het Arazzo-document van een API in
+// format; zonder format zoals het is aangeleverd.
+func (s *APIsAPIService) GetArazzoDocument(ctx context.Context, apiID, format string) (*models.ApiArtifact, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "yml" {
+		format = "yaml"
+	}
+	if format != "" && format != "json" && format != "yaml" {
+		return nil, problem.NewBadRequest("format", "Ongeldig formaat",
+			problem.InvalidParam{Name: "format", Reason: "moet json of yaml zijn"})
+	}
+	if err := s.requireArtifactApi(ctx, apiID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetArazzoArtifact(ctx, apiID, format)
+}
+
+// refreshArazzo haalt het Arazzo-document van api opnieuw op. Hij geeft nil
+// als de API geen Arazzo-URL heeft, het ophalen mislukt of het document
+// ongewijzigd is; de bestaande artifacts blijven dan staan.
+func (s *APIsAPIService) refreshArazzo(ctx context.Context, api *models.Api) *openapi.ArazzoResult {
+	if api.ArazzoUri == "" {
+		return nil
+	}
+	arazzo, err := fetchArazzo(ctx, toolslint.ArazzoInput{ArazzoUrl: api.ArazzoUri})
+	if err != nil {
+		log.Printf("[oas-refresh] arazzo overgeslagen api=%s url=%s: %v", api.Id, api.ArazzoUri, err)
+		return nil
+	}
+	if arazzo.Hash == api.ArazzoHash {
+		return nil
+	}
+	return arazzo
+}
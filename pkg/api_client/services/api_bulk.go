@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/openapi"
+	problem "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/problem"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/models"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/repositories"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// maxBulkItems begrenst het aantal items per bulkrequest.
+	maxBulkItems = 100
+	// defaultBulkConcurrency is het aantal items dat tegelijk wordt verwerkt.
+	defaultBulkConcurrency = 4
+)
+
+// bulkConcurrencyFromEnv leest API_BULK_CONCURRENCY.
+func bulkConcurrencyFromEnv() int {
+	raw := strings.TrimSpace(os.Getenv("API_BULK_CONCURRENCY"))
+	if raw == "" {
+		return defaultBulkConcurrency
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		log.Printf("[bulk] ongeldige API_BULK_CONCURRENCY %q, standaard %d wordt gebruikt", raw, defaultBulkConcurrency)
+		return defaultBulkConcurrency
+	}
+	return n
+}
+
+// BulkCreateApis registreert meerdere APIs via hetzelfde pad als POST /apis,
+// met begrensde parallelliteit. Elk item krijgt een eigen uitkomst; een fout
+// bij één item stopt de rest niet.
+func (s *APIsAPIService) BulkCreateApis(ctx context.Context, items []models.ApiPost) (*models.ApiBulkReport, error) {
+	return s.bulkCreateApis(ctx, items, nil)
+}
+
+// bulkCreateApis is BulkCreateApis met een optionele limiter die het starten
+// van elk item afremt, zodat een achtergrondjob de bronnen niet overbelast.
+func (s *APIsAPIService) bulkCreateApis(ctx context.Context, items []models.ApiPost, limiter *rate.Limiter) (*models.ApiBulkReport, error) {
+	if len(items) == 0 {
+		return nil, problem.NewBadRequest("body", "De bulkrequest bevat geen items",
+			problem.InvalidParam{Name: "body", Reason: "Moet minimaal één item bevatten"})
+	}
+	if len(items) > maxBulkItems {
+		return nil, problem.NewBadRequest("body", fmt.Sprintf("Maximaal %d items per bulkrequest", maxBulkItems),
+			problem.InvalidParam{Name: "body", Reason: fmt.Sprintf("Bevat %d items; maximaal %d", len(items), maxBulkItems)})
+	}
+
+	results := make([]models.ApiBulkResult, len(items))
+	concurrency := s.bulkConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	sem := semaphore.NewWeighted(int64(concurrency))
+	var g errgroup.Group
+	for i := range items {
+		if limiter != nil {
+			if err := limiter.Wait(ctx); err != nil {
+				_ = g.Wait()
+				return nil, err
+			}
+		}
+		if err := sem.Acquire(ctx, 1); err != nil {
+			return nil, err
+		}
+		g.Go(func() error {
+			defer sem.Release(1)
+			results[i] = s.bulkCreateItem(ctx, i, items[i])
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	report := &models.ApiBulkReport{Results: results}
+	report.Summary.Total = len(results)
+	for _, res := range results {
+		switch res.Status {
+		case models.BulkStatusCreated:
+			report.Summary.Created++
+		case models.BulkStatusExists:
+			report.Summary.Exists++
+		case models.BulkStatusInvalid:
+			report.Summary.Invalid++
+		case models.BulkStatusFetchFailed:
+			report.Summary.FetchFailed++
+		case models.BulkStatusForbidden:
+			report.Summary.Forbidden++
+		default:
+			report.Summary.Failed++
+		}
+	}
+	return report, nil
+}
+
+func (s *APIsAPIService) bulkCreateItem(ctx context.Context, index int, item models.ApiPost) models.ApiBulkResult {
+	result := models.ApiBulkResult{Index: index, OasUrl: strings.TrimSpace(item.OasUrl)}
+	if params := validateBulkItem(item); len(params) > 0 {
+		result.Status = models.BulkStatusInvalid
+		result.HttpStatus = http.StatusBadRequest
+		result.Detail = "Het item voldoet niet aan het ApiPost-schema"
+		result.InvalidParams = params
+		return result
+	}
+	// Net als bij een dry-run krijgt alleen wie een registratie mag inzien
+	// die terug; anders alleen de melding dat hij bestaat.
+	conflict, err := s.findOasUrlConflict(ctx, result.OasUrl)
+	if err != nil {
+		result.Status = models.BulkStatusFailed
+		result.HttpStatus = http.StatusInternalServerError
+		result.Detail = err.Error()
+		return result
+	}
+	if conflict != nil {
+		result.Status = models.BulkStatusExists
+		result.HttpStatus = http.StatusOK
+		result.Detail = conflict.Detail
+		result.Api = conflict.Api
+		return result
+	}
+
+	created, err := s.CreateApiFromOas(ctx, item)
+	if err == nil {
+		result.Status = models.BulkStatusCreated
+		result.HttpStatus = http.StatusCreated
+		result.Api = created
+		return result
+	}
+	var apiErr problem.APIError
+	if !errors.As(err, &apiErr) {
+		result.Status = models.BulkStatusFailed
+		result.HttpStatus = http.StatusInternalServerError
+		result.Detail = err.Error()
+		return result
+	}
+	result.HttpStatus = apiErr.Status
+	result.Detail = apiErr.Title
+	if len(apiErr.Errors) > 0 {
+		result.Detail = apiErr.Errors[0].Detail
+	}
+	switch {
+	case apiErr.Status == http.StatusForbidden:
+		result.Status = models.BulkStatusForbidden
+	case apiErr.Status != http.StatusBadRequest:
+		result.Status = models.BulkStatusFailed
+	case errors.Is(err, repositories.ErrApiExists):
+		result.Status = models.BulkStatusExists
+		result.HttpStatus = http.StatusOK
+	case errors.Is(err, openapi.ErrOASUnreachable):
+		result.Status = models.BulkStatusFetchFailed
+	default:
+		result.Status = models.BulkStatusInvalid
+		for _, e := range apiErr.Errors {
+			if e.In == "body" && e.Location != "body" {
+				result.InvalidParams = append(result.InvalidParams, problem.InvalidParam{Name: e.Location, Reason: e.Detail})
+			}
+		}
+	}
+	return result
+}
+
+// validateBulkItem past de binding-regels van ApiPost toe op één item en geeft
+// de fouten terug onder hun JSON-naam.
+func validateBulkItem(item models.ApiPost) []problem.InvalidParam {
+	err := binding.Validator.ValidateStruct(&item)
+	if err == nil {
+		return nil
+	}
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return []problem.InvalidParam{{Name: "body", Reason: err.Error()}}
+	}
+	t := reflect.TypeOf(item)
+	params := make([]problem.InvalidParam, 0, len(verrs))
+	for _, fe := range verrs {
+		name := fe.Field()
+		if f, ok := t.FieldByName(fe.StructField()); ok {
+			if tag := f.Tag.Get("json"); tag != "" && tag != "-" {
+				name = strings.Split(tag, ",")[0]
+			}
+		}
+		reason := fe.Error()
+		switch fe.Tag() {
+		case "required", "required_without":
+			reason = "is verplicht"
+		case "url":
+			reason = "Moet een geldige URL zijn (bijv. https://…)"
+		}
+		params = append(params, problem.InvalidParam{Name: name, Reason: reason})
+	}
+	return params
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	problem "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/problem"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/models"
+)
+
+const (
+	defaultChangesLimit = 100
+	maxChangesLimit     = 1000
+
+	// defaultChangesSettleDelay is hoe lang een wijziging oud moet zijn voordat
+	// de feed hem toont. Een insert die zijn seq al heeft maar nog niet is
+	// gecommit, is binnen die tijd zichtbaar.
+	defaultChangesSettleDelay = 5 * time.Second
+)
+
+// changesSettleDelayFromEnv leest CHANGES_SETTLE_DELAY (Go duration, bv. "5s").
+func changesSettleDelayFromEnv() time.Duration {
+	raw := strings.TrimSpace(os.Getenv("CHANGES_SETTLE_DELAY"))
+	if raw == "" {
+		return defaultChangesSettleDelay
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		log.Printf("[changes] ongeldige CHANGES_SETTLE_DELAY %q, standaard %s wordt gebruikt", raw, defaultChangesSettleDelay)
+		return defaultChangesSettleDelay
+	}
+	return d
+}
+
+// ListChanges geeft de wijzigingen na het since-token, oudste eerst. Het
+// token in Next hervat de feed bij de volgende aanroep.
+func (s *APIsAPIService) ListChanges(ctx context.Context, p *models.ListChangesParams) (*models.ChangeFeed, error) {
+	if p == nil {
+		p = &models.ListChangesParams{}
+	}
+	after, err := models.DecodeChangeToken(p.Since)
+	if err != nil {
+		return nil, problem.NewBadRequest("since", "Ongeldig token",
+			problem.InvalidParam{Name: "since", Reason: err.Error()})
+	}
+	limit := p.Limit
+	switch {
+	case limit < 0 || limit > maxChangesLimit:
+		return nil, problem.NewBadRequest("limit", "Ongeldige limit",
+			problem.InvalidParam{Name: "limit", Reason: "moet tussen 1 en 1000 liggen"})
+	case limit == 0:
+		limit = defaultChangesLimit
+	}
+	settledBefore := time.Now().UTC().Add(-s.changesSettleDelay)
+	changes, hasMore, err := s.repo.ListApiChanges(ctx, after, limit, settledBefore)
+	if err != nil {
+		return nil, err
+	}
+	if changes == nil {
+		changes = []models.ApiChange{}
+	}
+	next := after
+	if len(changes) > 0 {
+		next = changes[len(changes)-1].Seq
+	}
+	return &models.ChangeFeed{
+		Changes: changes,
+		Next:    models.EncodeChangeToken(next),
+		HasMore: hasMore,
+	}, nil
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	problem "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/problem"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/util"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/models"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/services/typesense"
+	"gorm.io/gorm"
+)
+
+// defaultDeleteRetention is hoe lang een verwijderde API herstelbaar blijft.
+const defaultDeleteRetention = 30 * 24 * time.Hour
+
+// deleteRetentionFromEnv leest API_DELETE_RETENTION (Go duration, bv. "720h").
+func deleteRetentionFromEnv() time.Duration {
+	raw := strings.TrimSpace(os.Getenv("API_DELETE_RETENTION"))
+	if raw == "" {
+		return defaultDeleteRetention
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		log.Printf("[delete] ongeldige API_DELETE_RETENTION %q, standaard %s wordt gebruikt", raw, defaultDeleteRetention)
+		return defaultDeleteRetention
+	}
+	return d
+}
+
+// DeleteApi verwijdert een API zacht: hij verdwijnt uit lijsten, zoekresultaten,
+// filters en Typesense, maar blijft tot het einde van de retentieperiode herstelbaar.
+func (s *APIsAPIService) DeleteApi(ctx context.Context, id string) error {
+	api, err := s.repo.GetApiByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if api == nil {
+		return problem.NewNotFound(id, "Api not found")
+	}
+	if err := authorizeOrganisation(ctx, deriveOrganisationURI(api)); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteApi(ctx, api.Id); err != nil {
+		return problem.NewInternalServerError("kan API niet verwijderen: " + err.Error())
+	}
+
+	deleted := cloneApi(api)
+	deleted.DeletedAt = gorm.DeletedAt{Time: time.Now().UTC(), Valid: true}
+	s.recordApiAudit(ctx, models.AuditActionApiDeleted, api, deleted)
+
+	go s.removeFromTypesense(api.Id)
+	return nil
+}
+
+// RestoreApi maakt een verwijdering binnen de retentieperiode ongedaan.
+func (s *APIsAPIService) RestoreApi(ctx context.Context, id string) (*models.ApiSummary, error) {
+	api, err := s.repo.GetDeletedApiByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if api == nil {
+		return nil, problem.NewNotFound(id, "Geen verwijderde API gevonden")
+	}
+	if time.Since(api.DeletedAt.Time) > s.deleteRetention {
+		return nil, problem.NewNotFound(id, "De retentieperiode van deze API is verstreken")
+	}
+	if active, err := s.repo.FindByOasUrl(ctx, api.OasUri); err != nil {
+		return nil, err
+	} else if active != nil {
+		return nil, problem.NewConflict(id, fmt.Sprintf("API '%s' gebruikt inmiddels dezelfde oasUrl", active.Id))
+	}
+	if err := s.repo.RestoreApi(ctx, api.Id); err != nil {
+		return nil, problem.NewInternalServerError("kan API niet herstellen: " + err.Error())
+	}
+
+	restored := cloneApi(api)
+	restored.DeletedAt = gorm.DeletedAt{}
+	s.recordApiAudit(ctx, models.AuditActionApiRestored, api, restored)
+
+	go s.publishToTypesense(*restored)
+	summary := util.ToApiSummary(restored)
+	return &summary, nil
+}
+
+// PurgeDeletedApis verwijdert APIs waarvan de retentieperiode verstreken is
+// definitief, inclusief artifacts en lint-historie.
+func (s *APIsAPIService) PurgeDeletedApis(ctx context.Context) (int, error) {
+	ids, err := s.repo.PurgeDeletedApis(ctx, time.Now().Add(-s.deleteRetention))
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		log.Printf("[delete] api=%s definitief verwijderd na retentieperiode", id)
+	}
+	return len(ids), nil
+}
+
+func (s *APIsAPIService) removeFromTypesense(apiID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := typesense.DeleteApi(ctx, apiID); err != nil {
+		if errors.Is(err, typesense.ErrDisabled) {
+			return
+		}
+		log.Printf("[typesense] verwijderen mislukt voor api=%s: %v", apiID, err)
+	}
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/auth"
+	problem "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/problem"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/models"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/repositories"
+	"github.com/google/uuid"
+)
+
+const (
+	apiKeyPrefix      = "don_"
+	apiKeyPrefixChars = 8
+)
+
+// apiKeyScopes zijn de scopes die een API key geeft: alleen lezen van publieke endpoints.
+var apiKeyScopes = []string{"apis:read", "organisations:read"}
+
+// ApiKeyService beheert API keys voor de publieke endpoints.
+type ApiKeyService struct {
+	repo repositories.ApiKeyRepository
+}
+
+// NewApiKeyService Constructor-functie
+func NewApiKeyService(repo repositories.ApiKeyRepository) *ApiKeyService {
+	return &ApiKeyService{repo: repo}
+}
+
+// CreateApiKey genereert een nieuwe sleutel en slaat alleen de hash op.
+func (s *ApiKeyService) CreateApiKey(ctx context.Context, input *models.ApiKeyInput) (*models.ApiKeyCreated, error) {
+	if input == nil || strings.TrimSpace(input.ConsumerName) == "" {
+		return nil, problem.NewBadRequest("", "consumerName is verplicht",
+			problem.InvalidParam{Name: "consumerName", Reason: "is verplicht"})
+	}
+	if strings.TrimSpace(input.ContactEmail) == "" {
+		return nil, problem.NewBadRequest("", "contactEmail is verplicht",
+			problem.InvalidParam{Name: "contactEmail", Reason: "is verplicht"})
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("kan API key niet genereren: %w", err)
+	}
+	random := base64.RawURLEncoding.EncodeToString(secret)
+	plain := apiKeyPrefix + random
+
+	key := models.ApiKey{
+		ID:              uuid.New().String(),
+		Prefix:          apiKeyPrefix + random[:apiKeyPrefixChars],
+		Hash:            hashApiKey(plain),
+		ConsumerName:    strings.TrimSpace(input.ConsumerName),
+		ContactName:     strings.TrimSpace(input.ContactName),
+		ContactEmail:    strings.TrimSpace(input.ContactEmail),
+		OrganisationUri: strings.TrimSpace(input.OrganisationUri),
+		CreatedAt:       time.Now(),
+	}
+	if err := s.repo.CreateApiKey(ctx, &key); err != nil {
+		return nil, problem.NewInternalServerError("kan API key niet opslaan: " + err.Error())
+	}
+	return &models.ApiKeyCreated{ApiKey: key, Key: plain}, nil
+}
+
+// ListApiKeys geeft alle sleutels inclusief gebruiksstatistieken terug.
+func (s *ApiKeyService) ListApiKeys(ctx context.Context) ([]models.ApiKey, error) {
+	return s.repo.ListApiKeys(ctx)
+}
+
+// RevokeApiKey trekt een sleutel in; ingetrokken sleutels worden niet meer geaccepteerd.
+func (s *ApiKeyService) RevokeApiKey(ctx context.Context, id string) (*models.ApiKey, error) {
+	key, err := s.repo.GetApiKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, problem.NewNotFound(id, "API key niet gevonden")
+	}
+	if key.Active() {
+		now := time.Now()
+		if err := s.repo.RevokeApiKey(ctx, id, now); err != nil {
+			return nil, err
+		}
+		key.RevokedAt = &now
+	}
+	return key, nil
+}
+
+// ValidateAPIKey implementeert auth.APIKeyValidator en telt het gebruik van de sleutel.
+func (s *ApiKeyService) ValidateAPIKey(ctx context.Context, raw string) (*auth.Principal, error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.HasPrefix(raw, apiKeyPrefix) {
+		return nil, auth.ErrInvalidAPIKey
+	}
+	key, err := s.repo.FindApiKeyByHash(ctx, hashApiKey(raw))
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || !key.Active() {
+		return nil, auth.ErrInvalidAPIKey
+	}
+	if err := s.repo.RecordApiKeyUsage(ctx, key.ID, time.Now()); err != nil {
+		log.Printf("[api-key] kan gebruik niet registreren key=%s: %v", key.ID, err)
+	}
+	principal := &auth.Principal{
+		Subject:  "apikey:" + key.ID,
+		ClientID: key.ConsumerName,
+		ApiKeyID: key.ID,
+		Scopes:   append([]string(nil), apiKeyScopes...),
+	}
+	if key.OrganisationUri != "" {
+		principal.Organisations = []string{key.OrganisationUri}
+	}
+	return principal, nil
+}
+
+func hashApiKey(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"net/mail"
+	"net/url"
+	"strings"
+
+	problem "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/problem"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/util"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/models"
+)
+
+// PatchApi past de door het register beheerde velden van een API aan zonder
+// de OAS opnieuw op te halen. Gezette waarden worden als override bewaard en
+// blijven gelden na een refresh. Een verwijderde override (null) wist het veld
+// en laat de volgende refresh de waarde weer uit de OAS halen.
+func (s *APIsAPIService) PatchApi(ctx context.Context, body *models.ApiPatch) (*models.ApiSummary, error) {
+	api, err := s.repo.GetApiByID(ctx, body.Id)
+	if err != nil {
+		return nil, err
+	}
+	if api == nil {
+		return nil, problem.NewNotFound(body.Id, "Api not found")
+	}
+	if err := authorizeOrganisation(ctx, deriveOrganisationURI(api)); err != nil {
+		return nil, err
+	}
+	if err := checkIfMatch(body.IfMatch, api); err != nil {
+		return nil, err
+	}
+	if err := validateApiPatch(body); err != nil {
+		return nil, err
+	}
+
+	before := cloneApi(api)
+	if applyApiPatch(api, body) {
+		// Forceer dat de volgende refresh de OAS opnieuw toepast.
+		api.OasHash = ""
+	}
+	if err := s.updateApi(ctx, api); err != nil {
+		return nil, asPreconditionFailed(api.Id, err)
+	}
+	s.recordApiAudit(ctx, models.AuditActionApiPatched, before, api)
+
+	go s.publishToTypesense(*api)
+	summary := util.ToApiSummary(api)
+	return &summary, nil
+}
+
+func validateApiPatch(body *models.ApiPatch) error {
+	if unknown := body.UnknownFields(); len(unknown) > 0 {
+		params := make([]problem.InvalidParam, 0, len(unknown))
+		for _, name := range unknown {
+			params = append(params, problem.InvalidParam{Name: name, Reason: "Dit veld komt uit de OAS en kan niet via PATCH worden gewijzigd"})
+		}
+		return problem.NewBadRequest(body.Id, "De patch bevat velden die niet door het register worden beheerd", params...)
+	}
+	if body.IsEmpty() {
+		return problem.NewBadRequest(body.Id, "De patch bevat geen wijzigingen")
+	}
+	for name, value := range map[string]models.OptionalString{
+		"contact.url":   body.Contact.URL,
+		"docsUrl":       body.DocsUrl,
+		"repositoryUri": body.RepositoryUri,
+	} {
+		if err := validatePatchURL(name, value); err != nil {
+			return err
+		}
+	}
+	if v := body.Contact.Email; v.Set && v.Value != nil && strings.TrimSpace(*v.Value) != "" {
+		if _, err := mail.ParseAddress(strings.TrimSpace(*v.Value)); err != nil {
+			return problem.NewBadRequest(*v.Value, "Ongeldig e-mailadres",
+				problem.InvalidParam{Name: "contact.email", Reason: "Moet een geldig e-mailadres zijn"})
+		}
+	}
+	if err := validateLifecycleDate("sunset", body.Sunset); err != nil {
+		return err
+	}
+	return validateLifecycleDate("deprecated", body.Deprecated)
+}
+
+func validatePatchURL(name string, value models.OptionalString) error {
+	if !value.Set || value.Value == nil || strings.TrimSpace(*value.Value) == "" {
+		return nil
+	}
+	u, err := url.ParseRequestURI(strings.TrimSpace(*value.Value))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return problem.NewBadRequest(*value.Value, "Ongeldige URL",
+			problem.InvalidParam{Name: name, Reason: "Moet een geldige http(s)-URL zijn"})
+	}
+	return nil
+}
+
+// applyApiPatch verwerkt de patch in api en zijn overrides. Het resultaat
+// geeft aan of er een override is verwijderd.
+func applyApiPatch(api *models.Api, body *models.ApiPatch) bool {
+	removed := false
+	for _, f := range []struct {
+		value    models.OptionalString
+		field    *string
+		override **string
+	}{
+		{body.Contact.Name, &api.ContactName, &api.Overrides.ContactName},
+		{body.Contact.URL, &api.ContactUrl, &api.Overrides.ContactUrl},
+		{body.Contact.Email, &api.ContactEmail, &api.Overrides.ContactEmail},
+		{body.DocsUrl, &api.DocsUrl, &api.Overrides.DocsUrl},
+		{body.RepositoryUri, &api.RepositoryUri, &api.Overrides.RepositoryUri},
+		{body.Sunset, &api.Sunset, &api.Overrides.Sunset},
+		{body.Deprecated, &api.Deprecated, &api.Overrides.Deprecated},
+	} {
+		if !f.value.Set {
+			continue
+		}
+		if f.value.Value == nil {
+			removed = removed || *f.override != nil
+			*f.override = nil
+			*f.field = ""
+			continue
+		}
+		value := strings.TrimSpace(*f.value.Value)
+		*f.override = &value
+		*f.field = value
+	}
+	return removed
+}
+
+// applyRegisterOverrides zet de overrides terug over de waarden uit de OAS.
+func applyRegisterOverrides(api *models.Api) {
+	if api == nil {
+		return
+	}
+	o := api.Overrides
+	for _, f := range []struct {
+		override *string
+		field    *string
+	}{
+		{o.ContactName, &api.ContactName},
+		{o.ContactUrl, &api.ContactUrl},
+		{o.ContactEmail, &api.ContactEmail},
+		{o.DocsUrl, &api.DocsUrl},
+		{o.RepositoryUri, &api.RepositoryUri},
+		{o.Sunset, &api.Sunset},
+		{o.Deprecated, &api.Deprecated},
+	} {
+		if f.override != nil {
+			*f.field = *f.override
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	problem "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/problem"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/models"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/repositories"
+)
+
+// defaultReplacedSunsetPeriod is hoe lang een opgevolgde API nog beschikbaar blijft.
+const defaultReplacedSunsetPeriod = 180 * 24 * time.Hour
+
+// replacedSunsetPeriodFromEnv leest API_REPLACED_SUNSET_PERIOD (Go duration, bv. "4320h").
+func replacedSunsetPeriodFromEnv() time.Duration {
+	raw := strings.TrimSpace(os.Getenv("API_REPLACED_SUNSET_PERIOD"))
+	if raw == "" {
+		return defaultReplacedSunsetPeriod
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		log.Printf("[replace] ongeldige API_REPLACED_SUNSET_PERIOD %q, standaard %s wordt gebruikt", raw, defaultReplacedSunsetPeriod)
+		return defaultReplacedSunsetPeriod
+	}
+	return d
+}
+
+// findPredecessor zoekt de API die door een nieuwe registratie wordt opgevolgd
+// en controleert of de aanroeper die mag deprecaten.
+func (s *APIsAPIService) findPredecessor(ctx context.Context, id string) (*models.Api, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, nil
+	}
+	predecessor, err := s.repo.GetApiByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if predecessor == nil {
+		return nil, problem.NewBadRequest(id, "De API in replaces bestaat niet",
+			problem.InvalidParam{Name: "replaces", Reason: "Moet het id van een bestaande API zijn"})
+	}
+	if err := authorizeOrganisation(ctx, deriveOrganisationURI(predecessor)); err != nil {
+		return nil, err
+	}
+	if predecessor.ReplacedByID != nil && *predecessor.ReplacedByID != "" {
+		return nil, problem.NewConflict(id, fmt.Sprintf("API '%s' is al opgevolgd door '%s'", id, *predecessor.ReplacedByID))
+	}
+	return predecessor, nil
+}
+
+// markReplaced koppelt de voorganger aan zijn opvolger en deprecate hem.
+func (s *APIsAPIService) markReplaced(ctx context.Context, predecessor *models.Api, successorID string) error {
+	before := cloneApi(predecessor)
+	s.linkSuccessor(predecessor, successorID)
+	if err := s.updateApi(ctx, predecessor); err != nil {
+		return err
+	}
+	s.announceReplaced(ctx, before, predecessor)
+	return nil
+}
+
+// announceReplaced legt de deprecation van een voorganger vast in de audit en
+// de zoekindex.
+func (s *APIsAPIService) announceReplaced(ctx context.Context, before, predecessor *models.Api) {
+	s.recordApiAudit(ctx, models.AuditActionApiReplaced, before, predecessor)
+	go s.publishToTypesense(*predecessor)
+}
+
+// saveReplacement slaat een goedgekeurde opvolger op en deprecate in dezelfde
+// transactie zijn voorganger, zodat de koppeling niet half kan slagen. Een
+// voorganger die intussen is opgevolgd of gewijzigd geeft een 409; dan is ook
+// de opvolger niet opgeslagen. Geeft de voorganger voor en na de wijziging.
+func (s *APIsAPIService) saveReplacement(ctx context.Context, successor *models.Api, predecessorID string) (before, after *models.Api, err error) {
+	after, err = s.repo.SaveReplacement(ctx, successor, predecessorID, func(predecessor *models.Api) {
+		before = cloneApi(predecessor)
+		s.linkSuccessor(predecessor, successor.Id)
+	})
+	switch {
+	case errors.Is(err, repositories.ErrAlreadyReplaced):
+		return nil, nil, problem.NewConflict(predecessorID, fmt.Sprintf("API '%s' is intussen al opgevolgd", predecessorID)).WithCause(err)
+	case errors.Is(err, repositories.ErrRevisionConflict):
+		return nil, nil, problem.NewConflict(predecessorID, fmt.Sprintf("API '%s' is tijdens de registratie gewijzigd; probeer het opnieuw", predecessorID)).WithCause(err)
+	case err != nil:
+		return nil, nil, err
+	}
+	return before, after, nil
+}
+
+// linkSuccessor zet de opvolger en de deprecation- en sunsetdatum op de
+// voorganger. De datums worden als override vastgelegd zodat de refresh ze
+// niet terugdraait; eerder gezette datums blijven staan.
+func (s *APIsAPIService) linkSuccessor(predecessor *models.Api, successorID string) {
+	predecessor.ReplacedByID = &successorID
+
+	now := time.Now().UTC()
+	predecessor.UpdatedAt = &now
+	if predecessor.Deprecated == "" {
+		deprecated := now.Format(time.DateOnly)
+		predecessor.Deprecated = deprecated
+		predecessor.Overrides.Deprecated = &deprecated
+	}
+	if predecessor.Sunset == "" {
+		sunset := now.Add(s.replacedSunsetPeriod).Format(time.DateOnly)
+		predecessor.Sunset = sunset
+		predecessor.Overrides.Sunset = &sunset
+	}
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/auth"
+	problem "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/problem"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/util"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/models"
+)
+
+// applyInitialReview zet de reviewstatus van een nieuwe registratie. Registraties
+// van curatoren en beheerders zijn direct goedgekeurd; de rest, ook die van de
+// harvester, wacht op review.
+func applyInitialReview(ctx context.Context, api *models.Api) {
+	if !auth.PrincipalFromContext(ctx).CanReview() {
+		api.ReviewStatus = models.ReviewStatusPending
+		return
+	}
+	markReviewed(ctx, api, models.ReviewStatusApproved, "")
+}
+
+func markReviewed(ctx context.Context, api *models.Api, status, reason string) {
+	now := time.Now().UTC()
+	api.ReviewStatus = status
+	api.ReviewReason = reason
+	api.ReviewedBy = auditActor(ctx)
+	api.ReviewedAt = &now
+}
+
+// resubmitForReview zet een afgewezen registratie na een wijziging terug in de
+// wachtrij.
+func resubmitForReview(api *models.Api) {
+	if api.ReviewStatus != models.ReviewStatusRejected {
+		return
+	}
+	api.ReviewStatus = models.ReviewStatusPending
+	api.ReviewReason = ""
+	api.ReviewedBy = ""
+	api.ReviewedAt = nil
+}
+
+// canSeeUnreviewed meldt of de aanroeper een niet goedgekeurde registratie mag
+// inzien: curatoren en de indienende organisatie.
+func canSeeUnreviewed(ctx context.Context, api *models.Api) bool {
+	principal := auth.PrincipalFromContext(ctx)
+	return principal.CanReview() || principal.CanActFor(deriveOrganisationURI(api))
+}
+
+// ListReviews geeft de registraties die op review wachten, of de afgewezen
+// registraties, met lint-resultaten en artifacts.
+func (s *APIsAPIService) ListReviews(ctx context.Context, p *models.ListReviewsParams) ([]models.ApiReviewItem, models.Pagination, error) {
+	status := strings.ToLower(strings.TrimSpace(p.Status))
+	if status == "" {
+		status = models.ReviewStatusPending
+	}
+	if status != models.ReviewStatusPending && status != models.ReviewStatusRejected {
+		return nil, models.Pagination{}, problem.NewBadRequest(p.Status, "Ongeldige reviewstatus",
+			problem.InvalidParam{Name: "status", Reason: "Moet pending of rejected zijn"})
+	}
+	apis, pagination, err := s.repo.ListApisByReviewStatus(ctx, status, p.Page, p.PerPage)
+	if err != nil {
+		return nil, models.Pagination{}, err
+	}
+	items := make([]models.ApiReviewItem, 0, len(apis))
+	for i := range apis {
+		item := models.ApiReviewItem{ApiDetail: *util.ToApiDetail(&apis[i]), Artifacts: []models.ApiArtifact{}}
+		if item.LintResults, err = s.repo.GetLintResults(ctx, apis[i].Id); err != nil {
+			return nil, models.Pagination{}, err
+		}
+		arts, err := s.repo.ListArtifacts(ctx, apis[i].Id)
+		if err != nil {
+			return nil, models.Pagination{}, err
+		}
+		if arts != nil {
+			item.Artifacts = arts
+		}
+		items = append(items, item)
+	}
+	return items, pagination, nil
+}
+
+// ApproveApi keurt een registratie goed en publiceert hem. Volgt de API een
+// andere op, dan wordt de voorganger pas nu deprecated.
+func (s *APIsAPIService) ApproveApi(ctx context.Context, id string) (*models.ApiSummary, error) {
+	api, err := s.findPendingReview(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := cloneApi(api)
+	markReviewed(ctx, api, models.ReviewStatusApproved, "")
+	if err := s.updateApi(ctx, api); err != nil {
+		return nil, asPreconditionFailed(api.Id, err)
+	}
+	s.recordApiAudit(ctx, models.AuditActionApiApproved, before, api)
+
+	if api.ReplacesID != nil && *api.ReplacesID != "" {
+		if err := s.replacePredecessor(ctx, *api.ReplacesID, api.Id); err != nil {
+			return nil, problem.NewInternalServerError("kan voorganger niet als opgevolgd markeren: " + err.Error())
+		}
+	}
+
+	go s.publishToTypesense(*api)
+	summary := util.ToApiSummary(api)
+	return &summary, nil
+}
+
+// RejectApi keurt een registratie af. De indiener ziet de reden en kan de
+// registratie via PUT aanpassen en opnieuw ter review aanbieden.
+func (s *APIsAPIService) RejectApi(ctx context.Context, body *models.ApiRejectInput) (*models.ApiSummary, error) {
+	reason := strings.TrimSpace(body.Reason)
+	if reason == "" {
+		return nil, problem.NewBadRequest(body.Id, "Een reden is verplicht",
+			problem.InvalidParam{Name: "reason", Reason: "Mag niet leeg zijn"})
+	}
+	api, err := s.findPendingReview(ctx, body.Id)
+	if err != nil {
+		return nil, err
+	}
+	before := cloneApi(api)
+	markReviewed(ctx, api, models.ReviewStatusRejected, reason)
+	if err := s.updateApi(ctx, api); err != nil {
+		return nil, asPreconditionFailed(api.Id, err)
+	}
+	s.recordApiAudit(ctx, models.AuditActionApiRejected, before, api)
+	summary := util.ToApiSummary(api)
+	return &summary, nil
+}
+
+func (s *APIsAPIService) findPendingReview(ctx context.Context, id string) (*models.Api, error) {
+	api, err := s.repo.GetApiByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if api == nil {
+		return nil, problem.NewNotFound(id, "Api not found")
+	}
+	if api.ReviewStatus != models.ReviewStatusPending {
+		return nil, problem.NewConflict(id, fmt.Sprintf("API '%s' wacht niet op review (status %s)", id, api.ReviewStatus))
+	}
+	return api, nil
+}
+
+// replacePredecessor deprecate de voorganger van een goedgekeurde registratie,
+// tenzij die intussen al door een andere API is opgevolgd.
+func (s *APIsAPIService) replacePredecessor(ctx context.Context, predecessorID, successorID string) error {
+	predecessor, err := s.repo.GetApiByID(ctx, predecessorID)
+	if err != nil {
+		return err
+	}
+	if predecessor == nil {
+		return nil
+	}
+	if predecessor.ReplacedByID != nil && *predecessor.ReplacedByID != "" {
+		log.Printf("[review] voorganger %s is al opgevolgd door %s; %s wordt niet gekoppeld", predecessorID, *predecessor.ReplacedByID, successorID)
+		return nil
+	}
+	return s.markReplaced(ctx, predecessor, successorID)
+}
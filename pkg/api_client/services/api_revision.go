@@ -0,0 +1,44 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	problem "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/problem"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/models"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/repositories"
+)
+
+// checkIfMatch vergelijkt de If-Match header van een PUT of PATCH met de
+// huidige revisie van api. Zonder header volgt een 428, bij een andere
+// revisie een 412. Alleen strong ETags tellen; "*" past altijd. De ETag van
+// elke representatie van GET /apis/{id} bevat de revisie en past dus ook.
+func checkIfMatch(ifMatch string, api *models.Api) error {
+	ifMatch = strings.TrimSpace(ifMatch)
+	if ifMatch == "" {
+		return problem.NewPreconditionRequired("If-Match",
+			fmt.Sprintf("If-Match is verplicht; gebruik de ETag van GET /v1/apis/%s", api.Id))
+	}
+	if ifMatch == "*" {
+		return nil
+	}
+	current := models.RevisionETag(api.Revision)
+	for _, tag := range strings.Split(ifMatch, ",") {
+		if revision, ok := models.ETagRevision(tag); ok && revision == api.Revision {
+			return nil
+		}
+	}
+	return problem.NewPreconditionFailed("If-Match",
+		fmt.Sprintf("API '%s' is intussen gewijzigd; de huidige ETag is %s", api.Id, current))
+}
+
+// asPreconditionFailed vertaalt een mislukte compare-and-swap in de
+// repository naar een 412.
+func asPreconditionFailed(id string, err error) error {
+	if errors.Is(err, repositories.ErrRevisionConflict) {
+		return problem.NewPreconditionFailed("If-Match",
+			fmt.Sprintf("API '%s' is tijdens de update door een ander gewijzigd; haal hem opnieuw op", id))
+	}
+	return err
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/auth"
 	httpclient "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/httpclient"
 	openapi "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/openapi"
 	problem "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/problem"
@@ -28,13 +29,22 @@ import (
 )
 
 var ErrNeedsPost = errors.New(
-	"oasUri niet gevonden of aangepast; registreer een nieuwe API via POST en markeer de oude als deprecated",
+	"oasUri niet gevonden of aangepast; registreer een nieuwe API via POST met replaces om de oude te laten opvolgen",
 )
 
 // APIsAPIService implementeert APIsAPIServicer met de benodigde repository
 type APIsAPIService struct {
 	repo    repositories.ApiRepository
 	limiter *rate.Limiter
+	// deleteRetention is hoe lang een verwijderde API herstelbaar blijft.
+	deleteRetention time.Duration
+	// replacedSunsetPeriod bepaalt de sunset-datum van een opgevolgde API.
+	replacedSunsetPeriod time.Duration
+	// bulkConcurrency is het aantal items dat een bulkregistratie tegelijk verwerkt.
+	bulkConcurrency int
+	// changesSettleDelay houdt recente wijzigingen uit de feed tot ze zeker
+	// gecommit zijn.
+	changesSettleDelay time.Duration
 }
 
 // NewAPIsAPIService Constructor-functie
@@ -42,6 +52,11 @@ func NewAPIsAPIService(repo repositories.ApiRepository) *APIsAPIService {
 	return &APIsAPIService{
 		repo:    repo,
 		limiter: rate.NewLimiter(rate.Every(time.Second*5), 1), // 1 per 5 seconden, burst 1
+
+		deleteRetention:      deleteRetentionFromEnv(),
+		replacedSunsetPeriod: replacedSunsetPeriodFromEnv(),
+		bulkConcurrency:      bulkConcurrencyFromEnv(),
+		changesSettleDelay:   changesSettleDelayFromEnv(),
 	}
 }
 
@@ -49,6 +64,29 @@ func (s *APIsAPIService) withRateLimit(ctx context.Context) error {
 	return s.limiter.Wait(ctx)
 }
 
+// updateApi schrijft api weg en houdt de revisie in het geheugen gelijk aan
+// die in de database, zodat een volgende update in hetzelfde request niet op
+// de eigen wijziging botst.
+func (s *APIsAPIService) updateApi(ctx context.Context, api *models.Api) error {
+	now := time.Now().UTC()
+	api.UpdatedAt = &now
+	if err := s.repo.UpdateApi(ctx, *api); err != nil {
+		return err
+	}
+	api.Revision++
+	return nil
+}
+
+// setOasHash legt de hash van de opgehaalde OAS vast en onthoudt wanneer die
+// voor het laatst veranderde.
+func setOasHash(api *models.Api, hash string, now time.Time) {
+	if hash == "" || hash == api.OasHash {
+		return
+	}
+	api.OasHash = hash
+	api.LastOASChangeAt = &now
+}
+
 func (s *APIsAPIService) UpdateOasUri(ctx context.Context, body *models.UpdateApiInput) (*models.ApiSummary, error) {
 	api, err := s.repo.GetApiByID(ctx, body.Id)
 	if err != nil || api == nil {
@@ -60,12 +98,23 @@ func (s *APIsAPIService) UpdateOasUri(ctx context.Context, body *models.UpdateAp
 		}
 		return nil, fmt.Errorf("databasefout: %w", err)
 	}
-	if ownerURI := deriveOrganisationURI(api); ownerURI == "" || ownerURI != strings.TrimSpace(body.OrganisationUri) {
-		return nil, problem.NewForbidden(body.OasUrl, "organisationUri komt niet overeen met eigenaar van deze API")
+	ownerURI := deriveOrganisationURI(api)
+	if err := authorizeOrganisation(ctx, ownerURI); err != nil {
+		return nil, err
+	}
+	if ownerURI == "" || ownerURI != strings.TrimSpace(body.OrganisationUri) {
+		return nil, problem.NewForbidden(body.OasUrl, fmt.Sprintf("organisationUri komt niet overeen met eigenaar van deze API; draag de API over via POST /v1/apis/%s/transfer", api.Id))
+	}
+	if err := checkIfMatch(body.IfMatch, api); err != nil {
+		return nil, err
 	}
 	if err := validateLifecycleOverrides(body); err != nil {
 		return nil, err
 	}
+	if err := checkArazzoURL(ctx, body.ArazzoUrl); err != nil {
+		return nil, err
+	}
+	resubmitForReview(api)
 	if !hasOASUpdateInput(body) {
 		return s.applyLifecycleUpdate(ctx, api, body)
 	}
@@ -83,8 +132,12 @@ func (s *APIsAPIService) UpdateOasUri(ctx context.Context, body *models.UpdateAp
 	if err != nil {
 		return nil, problem.NewBadRequest(body.OasUrl, err.Error())
 	}
+	arazzo, err := fetchArazzo(ctx, toolslint.ArazzoInput{ArazzoUrl: body.ArazzoUrl, ArazzoBody: body.ArazzoBody})
+	if err != nil {
+		return nil, err
+	}
 
-	return s.applyOASUpdate(ctx, api, models.ApiPost{
+	return s.applyOASUpdate(ctx, models.AuditActionApiUpdated, api, models.ApiPost{
 		Id:              body.Id,
 		OasUrl:          body.OasUrl,
 		OasBody:         body.OasBody,
@@ -92,16 +145,19 @@ func (s *APIsAPIService) UpdateOasUri(ctx context.Context, body *models.UpdateAp
 		ArazzoBody:      body.ArazzoBody,
 		OrganisationUri: body.OrganisationUri,
 		Contact:         body.Contact,
-	}, body, res, true)
+	}, body, res, arazzo, true)
 }
 
-func (s *APIsAPIService) applyOASUpdate(ctx context.Context, api *models.Api, request models.ApiPost, overrides *models.UpdateApiInput, res *openapi.OASResult, asyncTools bool) (*models.ApiSummary, error) {
+// applyOASUpdate werkt api bij naar de opgehaalde OAS. Zonder arazzo blijft
+// het bestaande Arazzo-document staan.
+func (s *APIsAPIService) applyOASUpdate(ctx context.Context, action string, api *models.Api, request models.ApiPost, overrides *models.UpdateApiInput, res *openapi.OASResult, arazzo *openapi.ArazzoResult, asyncTools bool) (*models.ApiSummary, error) {
 	if api == nil {
 		return nil, fmt.Errorf("api ontbreekt")
 	}
 	if res == nil {
 		return nil, fmt.Errorf("OAS resultaat ontbreekt")
 	}
+	before := cloneApi(api)
 
 	orgLabel := ""
 	if api.Organisation != nil {
@@ -114,6 +170,7 @@ func (s *APIsAPIService) applyOASUpdate(ctx context.Context, api *models.Api, re
 
 	openapi.UpdateApiFromSpec(api, res.Spec, request, orgLabel)
 	applyOASSnapshot(api, res)
+	applyRegisterOverrides(api)
 	applyLifecycleOverrides(api, overrides)
 	if api.Organisation == nil && strings.TrimSpace(request.OrganisationUri) != "" {
 		api.Organisation = &models.Organisation{
@@ -124,15 +181,16 @@ func (s *APIsAPIService) applyOASUpdate(ctx context.Context, api *models.Api, re
 	if api.Organisation != nil && api.OrganisationID == nil {
 		api.OrganisationID = &api.Organisation.Uri
 	}
+	setArazzo(api, arazzo)
 
-	if err := s.repo.UpdateApi(ctx, *api); err != nil {
-		return nil, err
+	if err := s.updateApi(ctx, api); err != nil {
+		return nil, asPreconditionFailed(api.Id, err)
 	}
+	s.recordApiAudit(ctx, action, before, api)
 
 	oasInput := toOASInput(request)
-	arazzoInput := toArazzoInput(request)
 	run := func(runCtx context.Context) error {
-		return s.runToolsAndPersist(runCtx, api.Id, oasInput, arazzoInput, res)
+		return s.runToolsAndPersist(runCtx, api.Id, oasInput, arazzo, res)
 	}
 	if asyncTools {
 		toolslint.Dispatch(context.Background(), "tools", run)
@@ -150,46 +208,144 @@ func (s *APIsAPIService) applyLifecycleUpdate(ctx context.Context, api *models.A
 	if api == nil {
 		return nil, fmt.Errorf("api ontbreekt")
 	}
+	before := cloneApi(api)
 	applyLifecycleOverrides(api, body)
-	if err := s.repo.UpdateApi(ctx, *api); err != nil {
-		return nil, err
+	if err := s.updateApi(ctx, api); err != nil {
+		return nil, asPreconditionFailed(api.Id, err)
 	}
+	s.recordApiAudit(ctx, models.AuditActionApiLifecycleChanged, before, api)
 	updated := util.ToApiSummary(api)
 	return &updated, nil
 }
 
-func (s *APIsAPIService) RetrieveApi(ctx context.Context, id string) (*models.ApiDetail, error) {
-	api, err := s.repo.GetApiByID(ctx, id)
+func (s *APIsAPIService) RetrieveApi(ctx context.Context, p *models.RetrieveApiParams) (*models.ApiDetail, error) {
+	view, err := parseApiView(p.Fields, p.Expand, models.DefaultDetailExpand)
+	if err != nil {
+		return nil, err
+	}
+	// De artifacts zijn altijd nodig voor de downloadlinks; in de body staan
+	// ze alleen met expand.
+	load := view.Expand
+	load.Artifacts = true
+	api, err := s.repo.GetApiExpanded(ctx, p.Id, load)
 	if err != nil || api == nil {
 		return nil, err
 	}
+	if !api.IsApproved() && !canSeeUnreviewed(ctx, api) {
+		return nil, nil
+	}
 	detail := util.ToApiDetail(api)
+	util.ExpandApiSummary(&detail.ApiSummary, api, view)
+	return detail, nil
+}
 
-	lintResults, err := s.repo.GetLintResults(ctx, api.Id)
+func (s *APIsAPIService) ListLintResults(ctx context.Context, p *models.ListLintResultsParams) ([]models.LintResult, models.Pagination, error) {
+	if p == nil {
+		p = &models.ListLintResultsParams{}
+	}
+	page, err := pageRequest(p.Page, p.PerPage, p.Cursor, p.Limit, models.LintResultsCursorSort)
 	if err != nil {
+		return nil, models.Pagination{}, err
+	}
+	filter := models.LintResultFilter{ApiID: trimmedValue(p.ApiId)}
+	if filter.Since, err = parseAuditTime("since", p.Since); err != nil {
+		return nil, models.Pagination{}, err
+	}
+	if filter.Until, err = parseAuditTime("until", p.Until); err != nil {
+		return nil, models.Pagination{}, err
+	}
+	results, pagination, err := s.repo.ListLintResults(ctx, page, filter)
+	if err != nil {
+		return nil, models.Pagination{}, err
+	}
+	return nonNilLintResults(results), pagination, nil
+}
+
+// ListApiLintResults geeft de lint-historie van één API, van nieuw naar oud.
+func (s *APIsAPIService) ListApiLintResults(ctx context.Context, p *models.ApiLintResultsParams) ([]models.LintResult, models.Pagination, error) {
+	if _, err := s.visibleApi(ctx, p.Id); err != nil {
+		return nil, models.Pagination{}, err
+	}
+	page, err := pageRequest(p.Page, p.PerPage, p.Cursor, p.Limit, models.LintResultsCursorSort)
+	if err != nil {
+		return nil, models.Pagination{}, err
+	}
+	filter := models.LintResultFilter{
+		ApiID:          p.Id,
+		Severity:       trimmedValue(p.Severity),
+		Code:           trimmedValue(p.Code),
+		RulesetVersion: trimmedValue(p.RulesetVersion),
+	}
+	if filter.Since, err = parseAuditTime("since", p.Since); err != nil {
+		return nil, models.Pagination{}, err
+	}
+	if filter.Until, err = parseAuditTime("until", p.Until); err != nil {
+		return nil, models.Pagination{}, err
+	}
+	results, pagination, err := s.repo.ListLintResults(ctx, page, filter)
+	if err != nil {
+		return nil, models.Pagination{}, err
+	}
+	return nonNilLintResults(results), pagination, nil
+}
+
+// GetLatestLintResult geeft het nieuwste lintresultaat van een API, of nil als
+// de API nog niet gelint is.
+func (s *APIsAPIService) GetLatestLintResult(ctx context.Context, apiID string) (*models.LintResult, error) {
+	if _, err := s.visibleApi(ctx, apiID); err != nil {
 		return nil, err
 	}
-	detail.LintResults = lintResults
+	return s.repo.GetLatestLintResult(ctx, apiID)
+}
 
-	return detail, nil
+// visibleApi geeft de API met id, of een 404 als die niet bestaat of nog op
+// een review wacht die de aanroeper niet mag zien.
+func (s *APIsAPIService) visibleApi(ctx context.Context, id string) (*models.Api, error) {
+	api, err := s.repo.GetApiByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if api == nil || (!api.IsApproved() && !canSeeUnreviewed(ctx, api)) {
+		return nil, problem.NewNotFound(id, "Api not found")
+	}
+	return api, nil
 }
 
-func (s *APIsAPIService) ListLintResults(ctx context.Context) ([]models.LintResult, error) {
-	return s.repo.ListLintResults(ctx)
+func nonNilLintResults(results []models.LintResult) []models.LintResult {
+	if results == nil {
+		return []models.LintResult{}
+	}
+	return results
 }
 
 func (s *APIsAPIService) ListApis(ctx context.Context, p *models.ListApisParams) ([]models.ApiSummary, models.Pagination, error) {
 	if p == nil {
 		p = &models.ListApisParams{}
 	}
-	apis, pagination, err := s.repo.GetApis(ctx, p.Page, p.PerPage, p.ApiFilters())
+	order, err := parseApiSort(p.Sort)
+	if err != nil {
+		return nil, models.Pagination{}, err
+	}
+	page, err := pageRequest(p.Page, p.PerPage, p.Cursor, p.Limit, order.String())
+	if err != nil {
+		return nil, models.Pagination{}, err
+	}
+	view, err := parseApiView(p.Fields, p.Expand, models.DefaultListExpand)
+	if err != nil {
+		return nil, models.Pagination{}, err
+	}
+	filters := p.ApiFilters()
+	if filters.UpdatedSince, err = parseAuditTime("updatedSince", &p.UpdatedSince); err != nil {
+		return nil, models.Pagination{}, err
+	}
+	apis, pagination, err := s.repo.GetApis(ctx, page, filters, order, view.Expand)
 	if err != nil {
 		return nil, models.Pagination{}, err
 	}
 
 	dtos := make([]models.ApiSummary, len(apis))
 	for i, api := range apis {
-		dtos[i] = util.ToApiSummary(&api)
+		dtos[i] = util.ToApiView(&api, view)
 	}
 
 	return dtos, pagination, nil
@@ -223,61 +379,125 @@ func (s *APIsAPIService) SearchApis(ctx context.Context, p *models.ListApisSearc
 	if trimmed == "" {
 		return []models.ApiSummary{}, models.Pagination{}, nil
 	}
-	apis, pagination, err := s.repo.SearchApis(ctx, p.Page, p.PerPage, p.Organisation, trimmed)
+	order, err := parseApiSort(p.Sort)
+	if err != nil {
+		return nil, models.Pagination{}, err
+	}
+	page, err := pageRequest(p.Page, p.PerPage, p.Cursor, p.Limit, order.String())
+	if err != nil {
+		return nil, models.Pagination{}, err
+	}
+	view, err := parseApiView(p.Fields, p.Expand, models.DefaultListExpand)
+	if err != nil {
+		return nil, models.Pagination{}, err
+	}
+	apis, pagination, err := s.repo.SearchApis(ctx, page, p.Organisation, trimmed, order, view.Expand)
 	if err != nil {
 		return nil, models.Pagination{}, err
 	}
 	results := make([]models.ApiSummary, len(apis))
 	for i := range apis {
-		results[i] = util.ToApiSummary(&apis[i])
+		results[i] = util.ToApiView(&apis[i], view)
 	}
 	return results, pagination, nil
 }
 
+// parseApiSort vertaalt de sort-parameter naar een 400 bij een onbekend veld.
+func parseApiSort(raw string) (models.ApiSort, error) {
+	order, err := models.ParseApiSort(raw)
+	if err != nil {
+		return models.ApiSort{}, problem.NewBadRequest("sort", "Ongeldige sortering",
+			problem.InvalidParam{Name: "sort", Reason: err.Error()})
+	}
+	return order, nil
+}
+
+// parseApiView vertaalt fields en expand naar een 400 bij een onbekende
+// waarde. Zonder expand geldt def.
+func parseApiView(fields, expand string, def models.ApiExpand) (models.ApiView, error) {
+	var view models.ApiView
+	var err error
+	if view.Fields, err = models.ParseApiFields(fields); err != nil {
+		return models.ApiView{}, problem.NewBadRequest("fields", "Ongeldige fields",
+			problem.InvalidParam{Name: "fields", Reason: err.Error()})
+	}
+	if view.Expand, err = models.ParseApiExpand(expand, def); err != nil {
+		return models.ApiView{}, problem.NewBadRequest("expand", "Ongeldige expand",
+			problem.InvalidParam{Name: "expand", Reason: err.Error()})
+	}
+	return view, nil
+}
+
+// pageRequest kiest tussen paginanummers en cursor-paginering. Met cursor of
+// limit wordt vanaf de cursor gepagineerd; de cursor moet bij sort horen, want
+// een positie in de ene sortering zegt niets over een andere.
+func pageRequest(page, perPage int, cursor string, limit int, sort string) (models.PageRequest, error) {
+	if cursor == "" && limit == 0 {
+		return models.PageRequest{Page: page, PerPage: perPage}, nil
+	}
+	if limit < 0 {
+		return models.PageRequest{}, problem.NewBadRequest("limit", "Ongeldige limit",
+			problem.InvalidParam{Name: "limit", Reason: "moet groter dan 0 zijn"})
+	}
+	if limit == 0 {
+		limit = 10
+	}
+	req := models.PageRequest{PerPage: limit, Cursor: true}
+	if cursor == "" {
+		return req, nil
+	}
+	after, err := models.DecodeCursor(cursor)
+	if err == nil && after.Sort != sort {
+		err = fmt.Errorf("hoort bij een andere sortering dan %q", sort)
+	}
+	if err != nil {
+		return models.PageRequest{}, problem.NewBadRequest("cursor", "Ongeldige cursor",
+			problem.InvalidParam{Name: "cursor", Reason: err.Error()})
+	}
+	req.After = &after
+	return req, nil
+}
+
 func (s *APIsAPIService) UpdateApi(ctx context.Context, api models.Api) error {
-	return s.repo.UpdateApi(ctx, api)
+	return s.updateApi(ctx, &api)
 }
 
-func (s *APIsAPIService) CreateApiFromOas(requestBody models.ApiPost) (*models.ApiSummary, error) {
-	ctx := context.Background()
+func (s *APIsAPIService) CreateApiFromOas(ctx context.Context, requestBody models.ApiPost) (*models.ApiSummary, error) {
+	if err := authorizeOrganisation(ctx, requestBody.OrganisationUri); err != nil {
+		return nil, err
+	}
+	predecessor, err := s.findPredecessor(ctx, requestBody.Replaces)
+	if err != nil {
+		return nil, err
+	}
+	arazzo, err := fetchArazzo(ctx, toArazzoInput(requestBody))
+	if err != nil {
+		return nil, err
+	}
 
 	// 1) Strict validate + hash
 	oasInput := toolslint.OASInput{
 		OasUrl:  requestBody.OasUrl,
 		OasBody: requestBody.OasBody,
 	}
-	arazzoInput := toArazzoInput(requestBody)
 	resp, err := openapi.FetchParseValidateAndHash(ctx, oasInput, openapi.FetchOpts{
 		Origin: "https://developer.overheid.nl",
 	})
 	if err != nil {
-		return nil, problem.NewBadRequest(requestBody.OasUrl, err.Error())
+		return nil, problem.NewBadRequest(requestBody.OasUrl, err.Error()).WithCause(err)
 	}
 
 	// 3) Build & validate.
-	var label string
-	var shouldSaveOrg bool
-	if org, err := s.repo.FindOrganisationByURI(context.Background(), requestBody.OrganisationUri); err != nil {
-		return nil, problem.NewInternalServerError("kan organisatie niet ophalen: " + err.Error())
-	} else if org != nil {
-		label = org.Label
-	} else {
-		if _, err := url.ParseRequestURI(requestBody.OrganisationUri); err != nil {
-			return nil, problem.NewBadRequest(
-				requestBody.OrganisationUri,
-				"Ongeldige URL",
-				problem.InvalidParam{Name: "organisationUri", Reason: "Moet een geldige URL zijn"},
-			)
-		}
-		lbl, err := httpclient.FetchOrganisationLabel(context.Background(), requestBody.OrganisationUri)
-		if err != nil {
-			return nil, problem.NewBadRequest(requestBody.OrganisationUri, fmt.Sprintf("fout bij ophalen organisatie: %s", err))
-		}
-		label = lbl
-		shouldSaveOrg = true
+	label, shouldSaveOrg, err := s.resolveOrganisationLabel(ctx, requestBody.OrganisationUri)
+	if err != nil {
+		return nil, err
 	}
 	api := openapi.BuildApi(resp.Spec, requestBody, label)
 	applyOASSnapshot(api, resp)
+	if predecessor != nil {
+		api.ReplacesID = &predecessor.Id
+	}
+	applyInitialReview(ctx, api)
 	if shouldSaveOrg && api.OrganisationID != nil {
 		if err := s.repo.SaveOrganisatie(api.Organisation); err != nil {
 			return nil, problem.NewInternalServerError("kan organisatie niet opslaan: " + err.Error())
@@ -298,21 +518,40 @@ func (s *APIsAPIService) CreateApiFromOas(requestBody models.ApiPost) (*models.A
 			return nil, problem.NewInternalServerError("Probleem bij het opslaan van het server object: " + err.Error())
 		}
 	}
-	if err := s.repo.Save(api); err != nil {
-		if strings.Contains(err.Error(), "api bestaat al") {
-			bad := problem.NewBadRequest(requestBody.OasUrl, "kan API niet opslaan: "+err.Error())
+	createdAt := time.Now().UTC()
+	api.CreatedAt = &createdAt
+	// Een registratie die op review wacht deprecate zijn voorganger pas na
+	// goedkeuring; anders gebeurt dat in dezelfde transactie als het opslaan.
+	var replacedBefore, replaced *models.Api
+	if predecessor != nil && api.IsApproved() {
+		replacedBefore, replaced, err = s.saveReplacement(ctx, api, predecessor.Id)
+	} else {
+		err = s.repo.Save(api)
+	}
+	if err != nil {
+		var apiErr problem.APIError
+		if errors.As(err, &apiErr) {
+			return nil, err
+		}
+		if errors.Is(err, repositories.ErrApiExists) {
+			bad := problem.NewBadRequest(requestBody.OasUrl, "kan API niet opslaan: "+err.Error()).WithCause(err)
 			return nil, bad
 		}
 		return nil, problem.NewInternalServerError("kan API niet opslaan: " + err.Error())
 	}
 
-	api.OasHash = resp.Hash
-	if err := s.repo.UpdateApi(ctx, *api); err != nil {
+	setOasHash(api, resp.Hash, createdAt)
+	setArazzo(api, arazzo)
+	if err := s.updateApi(ctx, api); err != nil {
 		return nil, problem.NewInternalServerError("kan API hash niet opslaan: " + err.Error())
 	}
+	s.recordApiAudit(ctx, models.AuditActionApiCreated, nil, api)
+	if replaced != nil {
+		s.announceReplaced(ctx, replacedBefore, replaced)
+	}
 
 	toolslint.Dispatch(context.Background(), "tools", func(ctx context.Context) error {
-		return s.runToolsAndPersist(ctx, api.Id, oasInput, arazzoInput, resp)
+		return s.runToolsAndPersist(ctx, api.Id, oasInput, arazzo, resp)
 	})
 
 	apiCopy := *api
@@ -322,9 +561,35 @@ func (s *APIsAPIService) CreateApiFromOas(requestBody models.ApiPost) (*models.A
 	return &created, nil
 }
 
+// resolveOrganisationLabel zoekt het label van een organisatie op. Is de
+// organisatie nog niet geregistreerd, dan wordt het label extern opgehaald en
+// meldt isNew dat de organisatie nog moet worden opgeslagen.
+func (s *APIsAPIService) resolveOrganisationLabel(ctx context.Context, uri string) (label string, isNew bool, err error) {
+	org, err := s.repo.FindOrganisationByURI(ctx, uri)
+	if err != nil {
+		return "", false, problem.NewInternalServerError("kan organisatie niet ophalen: " + err.Error())
+	}
+	if org != nil {
+		return org.Label, false, nil
+	}
+	if _, err := url.ParseRequestURI(uri); err != nil {
+		return "", false, problem.NewBadRequest(
+			uri,
+			"Ongeldige URL",
+			problem.InvalidParam{Name: "organisationUri", Reason: "Moet een geldige URL zijn"},
+		)
+	}
+	label, err = httpclient.FetchOrganisationLabel(ctx, uri)
+	if err != nil {
+		return "", false, problem.NewBadRequest(uri, fmt.Sprintf("fout bij ophalen organisatie: %s", err))
+	}
+	return label, true, nil
+}
+
 // RefreshChangedApis haalt alle geregistreerde APIs op, vergelijkt de OAS-hash en
 // voert dezelfde stappen uit als een POST wanneer de remote OAS gewijzigd is.
 func (s *APIsAPIService) RefreshChangedApis(ctx context.Context) (int, error) {
+	ctx = withSystemActor(ctx, "oas-refresh")
 	apis, err := s.repo.AllApis(ctx)
 	if err != nil {
 		return 0, err
@@ -341,7 +606,7 @@ func (s *APIsAPIService) RefreshChangedApis(ctx context.Context) (int, error) {
 			Origin: "https://developer.overheid.nl",
 		})
 		if err != nil {
-			if updateErr := s.updateOASMetadataSnapshot(ctx, candidate.Id, candidate.OAS, models.OASMetadata{
+			if updateErr := s.updateOASMetadataSnapshot(ctx, &candidate, models.OASMetadata{
 				Version: candidate.OAS.Version,
 				Status:  classifyOASStatus(err),
 				Auth:    currentOASAuth(candidate),
@@ -352,11 +617,12 @@ func (s *APIsAPIService) RefreshChangedApis(ctx context.Context) (int, error) {
 			continue
 		}
 
-		if err := s.updateOASMetadataSnapshot(ctx, candidate.Id, candidate.OAS, deriveOASSnapshot(candidate.OAS, res)); err != nil {
+		if err := s.updateOASMetadataSnapshot(ctx, &candidate, deriveOASSnapshot(candidate.OAS, res)); err != nil {
 			log.Printf("[oas-refresh] kon oas snapshot niet bijwerken api=%s: %v", candidate.Id, err)
 		}
 
-		if res.Hash == candidate.OasHash {
+		arazzo := s.refreshArazzo(ctx, &candidate)
+		if res.Hash == candidate.OasHash && arazzo == nil {
 			continue
 		}
 
@@ -366,6 +632,15 @@ func (s *APIsAPIService) RefreshChangedApis(ctx context.Context) (int, error) {
 			continue
 		}
 
+		if res.Hash == candidate.OasHash {
+			if err := s.applyArazzoRefresh(ctx, full, arazzo); err != nil {
+				log.Printf("[oas-refresh] arazzo update van api=%s mislukt: %v", full.Id, err)
+				continue
+			}
+			updated++
+			continue
+		}
+
 		orgURI := deriveOrganisationURI(full)
 		if orgURI == "" {
 			log.Printf("[oas-refresh] sla api=%s over: organisationUri ontbreekt", candidate.Id)
@@ -382,7 +657,7 @@ func (s *APIsAPIService) RefreshChangedApis(ctx context.Context) (int, error) {
 			},
 		}
 
-		if _, err := s.applyOASUpdate(ctx, full, req, nil, res, false); err != nil {
+		if _, err := s.applyOASUpdate(ctx, models.AuditActionApiRefreshed, full, req, nil, res, arazzo, false); err != nil {
 			log.Printf("[oas-refresh] update van api=%s mislukt: %v", full.Id, err)
 			continue
 		}
@@ -429,6 +704,56 @@ func (s *APIsAPIService) LintAllApis(ctx context.Context) error {
 	return g.Wait()
 }
 
+// lintResultFromDTO zet het antwoord van de tools-linter om naar een
+// LintResult en geeft de ADR-score terug.
+func lintResultFromDTO(apiID string, dto *toolslint.LintResultDTO) (*models.LintResult, int) {
+	msgs := make([]models.LintMessage, 0, len(dto.Messages))
+	var errCount, warnCount int
+	for _, m := range dto.Messages {
+		if strings.ToLower(m.Severity) == "error" {
+			errCount++
+		} else if strings.ToLower(m.Severity) == "warning" {
+			warnCount++
+		}
+		infos := make([]models.LintMessageInfo, 0, len(m.Infos))
+		for _, i := range m.Infos {
+			infos = append(infos, models.LintMessageInfo{
+				ID:            i.ID,
+				LintMessageID: i.LintMessageID,
+				Message:       i.Message,
+				Path:          i.Path,
+			})
+		}
+		id := m.ID
+		if strings.TrimSpace(id) == "" {
+			id = uuid.New().String()
+		}
+		msgs = append(msgs, models.LintMessage{
+			ID:             id,
+			Severity:       m.Severity,
+			Code:           m.Code,
+			RulesetVersion: dto.RulesetVersion,
+			Infos:          infos,
+			CreatedAt:      m.CreatedAt,
+		})
+	}
+	log.Printf("[lint] messages=%d errors=%d warnings=%d score=%d", len(msgs), errCount, warnCount, dto.Score)
+
+	rid := dto.ID
+	if strings.TrimSpace(rid) == "" {
+		rid = uuid.New().String()
+	}
+	return &models.LintResult{
+		ID:        rid,
+		ApiID:     apiID,
+		Successes: dto.Successes,
+		Failures:  dto.Failures,
+		Warnings:  dto.Warnings,
+		Messages:  msgs,
+		CreatedAt: dto.CreatedAt,
+	}, dto.Score
+}
+
 // lintAndPersist runs the linter when the OAS has changed and stores
 // both the lint result and updated hash.
 func (s *APIsAPIService) lintAndPersist(ctx context.Context, apiID string, input toolslint.OASInput, expectedHash string) error {
@@ -452,65 +777,29 @@ func (s *APIsAPIService) lintAndPersist(ctx context.Context, apiID string, input
 			return lintErr
 		}
 
-		// Map DTO to our persistence model
-		msgs := make([]models.LintMessage, 0, len(dto.Messages))
-		var errCount, warnCount int
-		for _, m := range dto.Messages {
-			if strings.ToLower(m.Severity) == "error" {
-				errCount++
-			} else if strings.ToLower(m.Severity) == "warning" {
-				warnCount++
-			}
-			infos := make([]models.LintMessageInfo, 0, len(m.Infos))
-			for _, i := range m.Infos {
-				infos = append(infos, models.LintMessageInfo{
-					ID:            i.ID,
-					LintMessageID: i.LintMessageID,
-					Message:       i.Message,
-					Path:          i.Path,
-				})
-			}
-			id := m.ID
-			if strings.TrimSpace(id) == "" {
-				id = uuid.New().String()
-			}
-			msgs = append(msgs, models.LintMessage{
-				ID:             id,
-				Severity:       m.Severity,
-				Code:           m.Code,
-				RulesetVersion: dto.RulesetVersion,
-				Infos:          infos,
-				CreatedAt:      m.CreatedAt,
-			})
-		}
-		score := dto.Score
-		log.Printf("[lint] messages=%d errors=%d warnings=%d score=%d", len(msgs), errCount, warnCount, score)
-
-		rid := dto.ID
-		if strings.TrimSpace(rid) == "" {
-			rid = uuid.New().String()
-		}
-		res := &models.LintResult{
-			ID:        rid,
-			ApiID:     apiID,
-			Successes: dto.Successes,
-			Failures:  dto.Failures,
-			Warnings:  dto.Warnings,
-			Messages:  msgs,
-			CreatedAt: dto.CreatedAt,
-		}
+		res, score := lintResultFromDTO(apiID, dto)
 		if err := s.repo.SaveLintResult(ctx, res); err != nil {
 			log.Printf("[lint] save result failed: %v", err)
 			return err
 		}
 		log.Printf("[lint] saved lint result id=%s", res.ID)
 
-		// ✅ Hash en score wegschrijven
-		current.AdrScore = &score
-		current.OasHash = expectedHash
-		if err := s.repo.UpdateApi(ctx, *current); err != nil {
-			log.Printf("[lint] update api failed: %v", err)
-			return err
+		// ✅ Hash en score wegschrijven; bij een gelijktijdige wijziging opnieuw
+		// laden en nogmaals proberen.
+		for attempt := 1; ; attempt++ {
+			current.AdrScore = &score
+			setOasHash(current, expectedHash, time.Now().UTC())
+			err := s.updateApi(ctx, current)
+			if err == nil {
+				break
+			}
+			if !errors.Is(err, repositories.ErrRevisionConflict) || attempt == 3 {
+				log.Printf("[lint] update api failed: %v", err)
+				return err
+			}
+			if current, err = s.repo.GetApiByID(ctx, apiID); err != nil || current == nil {
+				return err
+			}
 		}
 		log.Printf("[lint] updated AdrScore=%d & OasHash=%s api=%s", score, expectedHash, apiID)
 	}
@@ -519,8 +808,10 @@ func (s *APIsAPIService) lintAndPersist(ctx context.Context, apiID string, input
 
 // runToolsAndPersist runs lint, bruno and postman generation
 // and persists their outputs. Lint result + ADR score are stored as before;
-// Bruno and Postman artifacts are stored as blobs linked to the API.
-func (s *APIsAPIService) runToolsAndPersist(ctx context.Context, apiID string, oasInput toolslint.OASInput, arazzoInput toolslint.ArazzoInput, result *openapi.OASResult) error {
+// Bruno and Postman artifacts are stored as blobs linked to the API. With an
+// Arazzo document it also stores the document and its markdown and mermaid
+// renderings.
+func (s *APIsAPIService) runToolsAndPersist(ctx context.Context, apiID string, oasInput toolslint.OASInput, arazzo *openapi.ArazzoResult, result *openapi.OASResult) error {
 	if err := s.lintAndPersist(ctx, apiID, oasInput, result.Hash); err != nil {
 		log.Printf("[tools] lint failed: %v", err)
 	}
@@ -589,63 +880,8 @@ func (s *APIsAPIService) runToolsAndPersist(ctx context.Context, apiID string, o
 		return nil
 	})
 
-	if !arazzoInput.IsEmpty() {
-		arazzoInput.Normalize()
-		g.Go(func() error {
-			if err := s.withRateLimit(ctx); err != nil {
-				return nil
-			}
-			data, ct, err := toolslint.ArazzoMarkdown(ctx, arazzoInput)
-			if err != nil {
-				log.Printf("[tools] arazzo markdown failed: %v", err)
-				return nil
-			}
-			art := &models.ApiArtifact{
-				ID:          uuid.New().String(),
-				ApiID:       apiID,
-				Kind:        "arazzo_markdown",
-				Format:      "markdown",
-				Source:      "tools",
-				Filename:    "arazzo.md",
-				ContentType: ct,
-				Data:        data,
-				CreatedAt:   time.Now(),
-			}
-			if err := s.repo.SaveArtifact(ctx, art); err != nil {
-				log.Printf("[tools] save arazzo markdown failed: %v", err)
-			} else {
-				log.Printf("[tools] saved arazzo markdown artifact id=%s api=%s", art.ID, apiID)
-			}
-			return nil
-		})
-
-		g.Go(func() error {
-			if err := s.withRateLimit(ctx); err != nil {
-				return nil
-			}
-			data, ct, err := toolslint.ArazzoMermaid(ctx, arazzoInput)
-			if err != nil {
-				log.Printf("[tools] arazzo mermaid failed: %v", err)
-				return nil
-			}
-			art := &models.ApiArtifact{
-				ID:          uuid.New().String(),
-				ApiID:       apiID,
-				Kind:        "arazzo_mermaid",
-				Format:      "mermaid",
-				Source:      "tools",
-				Filename:    "arazzo.mmd",
-				ContentType: ct,
-				Data:        data,
-				CreatedAt:   time.Now(),
-			}
-			if err := s.repo.SaveArtifact(ctx, art); err != nil {
-				log.Printf("[tools] save arazzo mermaid failed: %v", err)
-			} else {
-				log.Printf("[tools] saved arazzo mermaid artifact id=%s api=%s", art.ID, apiID)
-			}
-			return nil
-		})
+	if arazzo != nil {
+		s.runArazzoTools(ctx, g, apiID, arazzo)
 	}
 
 	_ = g.Wait()
@@ -653,6 +889,9 @@ func (s *APIsAPIService) runToolsAndPersist(ctx context.Context, apiID string, o
 }
 
 func (s *APIsAPIService) publishToTypesense(api models.Api) {
+	if !api.IsApproved() {
+		return
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -664,8 +903,15 @@ func (s *APIsAPIService) publishToTypesense(api models.Api) {
 	}
 }
 
-func (s *APIsAPIService) ListOrganisations(ctx context.Context) ([]models.Organisation, int, error) {
-	return s.repo.GetOrganisations(ctx)
+func (s *APIsAPIService) ListOrganisations(ctx context.Context, p *models.ListOrganisationsParams) ([]models.Organisation, models.Pagination, error) {
+	if p == nil {
+		p = &models.ListOrganisationsParams{}
+	}
+	page, err := pageRequest(p.Page, p.PerPage, p.Cursor, p.Limit, models.OrganisationsCursorSort)
+	if err != nil {
+		return nil, models.Pagination{}, err
+	}
+	return s.repo.GetOrganisations(ctx, page)
 }
 
 // PublishAllApisToTypesense pushes every stored API to Typesense. Intended as a one-off helper.
@@ -684,6 +930,9 @@ func (s *APIsAPIService) PublishAllApisToTypesense(ctx context.Context) error {
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
+		if !api.IsApproved() {
+			continue
+		}
 		apiCopy := api
 		itemCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 		err := typesense.PublishApi(itemCtx, &apiCopy)
@@ -709,20 +958,67 @@ func (s *APIsAPIService) CreateOrganisation(ctx context.Context, org *models.Org
 		return nil, problem.NewBadRequest(org.Uri, "label is verplicht",
 			problem.InvalidParam{Name: "label", Reason: "label is verplicht"})
 	}
+	if err := authorizeOrganisation(ctx, org.Uri); err != nil {
+		return nil, err
+	}
 	if err := s.repo.SaveOrganisatie(org); err != nil {
 		return nil, err
 	}
+	s.recordOrganisationAudit(ctx, org)
 	return org, nil
 }
 
-// GetArtifact retrieves the latest artifact for an API and kind.
+// GetArtifact retrieves the metadata of the latest artifact for an API and kind.
 func (s *APIsAPIService) GetArtifact(ctx context.Context, apiID, kind string) (*models.ApiArtifact, error) {
 	if strings.TrimSpace(apiID) == "" || strings.TrimSpace(kind) == "" {
 		return nil, fmt.Errorf("apiID en kind zijn verplicht")
 	}
+	if err := s.requireArtifactApi(ctx, apiID); err != nil {
+		return nil, err
+	}
 	return s.repo.GetArtifact(ctx, apiID, kind)
 }
 
+// requireArtifactApi geeft een 404 als de API van een artifact niet bestaat,
+// verwijderd is of op een review wacht die de aanroeper niet mag zien. De
+// artifacts van een verwijderde API blijven bewaard tot PurgeDeletedApis ze
+// opruimt, maar worden niet meer uitgeleverd.
+func (s *APIsAPIService) requireArtifactApi(ctx context.Context, apiID string) error {
+	_, err := s.visibleApi(ctx, apiID)
+	return err
+}
+
+// ListApiArtifacts geeft de metadata van alle artifacts van een API. Een
+// onbekende of voor de aanroeper onzichtbare API geeft nil.
+func (s *APIsAPIService) ListApiArtifacts(ctx context.Context, apiID string) ([]models.ApiArtifact, error) {
+	api, err := s.repo.GetApiByID(ctx, apiID)
+	if err != nil || api == nil {
+		return nil, err
+	}
+	if !api.IsApproved() && !canSeeUnreviewed(ctx, api) {
+		return nil, nil
+	}
+	arts, err := s.repo.ListArtifacts(ctx, apiID)
+	if err != nil {
+		return nil, err
+	}
+	if arts == nil {
+		arts = []models.ApiArtifact{}
+	}
+	return arts, nil
+}
+
+// GetArtifactByID geeft de metadata van één artifact van een API.
+func (s *APIsAPIService) GetArtifactByID(ctx context.Context, apiID, artifactID string) (*models.ApiArtifact, error) {
+	if strings.TrimSpace(apiID) == "" || strings.TrimSpace(artifactID) == "" {
+		return nil, fmt.Errorf("apiID en artifactID zijn verplicht")
+	}
+	if err := s.requireArtifactApi(ctx, apiID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetArtifactByID(ctx, apiID, artifactID)
+}
+
 func (s *APIsAPIService) GetOasDocument(ctx context.Context, apiID, version, format string) (*models.ApiArtifact, error) {
 	if strings.TrimSpace(apiID) == "" {
 		return nil, fmt.Errorf("apiID is verplicht")
@@ -738,6 +1034,9 @@ func (s *APIsAPIService) GetOasDocument(ctx context.Context, apiID, version, for
 	if format != "json" && format != "yaml" {
 		return nil, problem.NewBadRequest(format, "ondersteunde extensies zijn json en yaml")
 	}
+	if err := s.requireArtifactApi(ctx, apiID); err != nil {
+		return nil, err
+	}
 	art, err := s.repo.GetOasArtifact(ctx, apiID, version, format)
 	if err != nil {
 		return nil, err
@@ -745,6 +1044,19 @@ func (s *APIsAPIService) GetOasDocument(ctx context.Context, apiID, version, for
 	return art, nil
 }
 
+// ArtifactData geeft de inhoud van een artifact van GetArtifact of
+// GetOasDocument. Die laden alleen de metadata, zodat een conditionele GET
+// beantwoord kan worden zonder het document uit de database te halen.
+func (s *APIsAPIService) ArtifactData(ctx context.Context, art *models.ApiArtifact) ([]byte, error) {
+	if art == nil {
+		return nil, fmt.Errorf("artifact ontbreekt")
+	}
+	if art.Data != nil {
+		return art.Data, nil
+	}
+	return s.repo.GetArtifactData(ctx, art.ID)
+}
+
 func (s *APIsAPIService) persistOASArtifacts(ctx context.Context, apiID string, res *openapi.OASResult) error {
 	if res == nil {
 		return errors.New("leeg OAS resultaat")
@@ -892,10 +1204,8 @@ func (s *APIsAPIService) BackfillOASArtifacts(ctx context.Context) error {
 			log.Printf("[backfill] persist failed api=%s: %v", api.Id, err)
 		}
 		applyOASSnapshot(&api, res)
-		if api.OasHash != res.Hash {
-			api.OasHash = res.Hash
-		}
-		if err := s.repo.UpdateApi(ctx, api); err != nil {
+		setOasHash(&api, res.Hash, time.Now().UTC())
+		if err := s.updateApi(ctx, &api); err != nil {
 			log.Printf("[backfill] update hash/oas snapshot failed api=%s: %v", api.Id, err)
 		}
 	}
@@ -931,20 +1241,18 @@ func classifyOASStatus(err error) string {
 	if err == nil {
 		return models.OASStatusValid
 	}
-	msg := strings.ToLower(err.Error())
 	switch {
-	case strings.Contains(msg, "kan oas niet ophalen"),
-		strings.Contains(msg, "kan oas niet lezen"),
-		strings.Contains(msg, "geen oasurl opgegeven"):
+	case errors.Is(err, openapi.ErrOASUnreachable):
 		return models.OASStatusUnreachable
-	case strings.Contains(msg, "invalid oas"):
+	case errors.Is(err, openapi.ErrOASInvalid):
 		return models.OASStatusInvalid
 	default:
 		return models.OASStatusUnknown
 	}
 }
 
-func (s *APIsAPIService) updateOASMetadataSnapshot(ctx context.Context, apiID string, current, next models.OASMetadata) error {
+func (s *APIsAPIService) updateOASMetadataSnapshot(ctx context.Context, api *models.Api, next models.OASMetadata) error {
+	current := api.OAS
 	current.Version = strings.TrimSpace(current.Version)
 	current.Status = strings.TrimSpace(current.Status)
 	current.Auth = strings.TrimSpace(current.Auth)
@@ -954,7 +1262,13 @@ func (s *APIsAPIService) updateOASMetadataSnapshot(ctx context.Context, apiID st
 	if current == next {
 		return nil
 	}
-	return s.repo.UpdateOASMetadata(ctx, apiID, next)
+	if err := s.repo.UpdateOASMetadata(ctx, api.Id, next); err != nil {
+		return err
+	}
+	after := cloneApi(api)
+	after.OAS = next
+	s.recordApiAudit(ctx, models.AuditActionApiRefreshed, api, after)
+	return nil
 }
 
 func deriveOrganisationURI(api *models.Api) string {
@@ -970,6 +1284,17 @@ func deriveOrganisationURI(api *models.Api) string {
 	return ""
 }
 
+// authorizeOrganisation controleert of de aanroeper namens de organisatie mag
+// schrijven. Zonder principal in de context gaat het om een interne aanroep
+// (refresh-job, harvester) en is de actie toegestaan.
+func authorizeOrganisation(ctx context.Context, organisationURI string) error {
+	principal := auth.PrincipalFromContext(ctx)
+	if principal == nil || principal.CanActFor(organisationURI) {
+		return nil
+	}
+	return problem.NewForbidden(organisationURI, fmt.Sprintf("geen schrijfrechten voor organisatie '%s'", organisationURI))
+}
+
 func hasOASUpdateInput(body *models.UpdateApiInput) bool {
 	if body == nil {
 		return false
@@ -1054,6 +1379,19 @@ func toArazzoInput(post models.ApiPost) toolslint.ArazzoInput {
 	}
 }
 
+// checkArazzoURL past de fetch-policy toe op een opgegeven Arazzo URL.
+func checkArazzoURL(ctx context.Context, raw string) error {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	if err := httpclient.DefaultFetcher().CheckURL(ctx, raw); err != nil {
+		return problem.NewBadRequest(raw, "arazzoUrl is niet toegestaan",
+			problem.InvalidParam{Name: "arazzoUrl", Reason: err.Error()})
+	}
+	return nil
+}
+
 func detectOASFormat(raw []byte, contentType string) (string, error) {
 	ct := strings.ToLower(contentType)
 	switch {
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	openapihelper "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/openapi"
 	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/models"
@@ -14,6 +15,7 @@ import (
 	"github.com/pb33f/libopenapi"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
 )
 
 type artifactRepoStub struct {
@@ -22,15 +24,18 @@ type artifactRepoStub struct {
 	updates []models.Api
 }
 
-func (a *artifactRepoStub) GetApis(ctx context.Context, page, perPage int, p *models.ApiFiltersParams) ([]models.Api, models.Pagination, error) {
+func (a *artifactRepoStub) GetApis(ctx context.Context, page models.PageRequest, p *models.ApiFiltersParams, order models.ApiSort, expand models.ApiExpand) ([]models.Api, models.Pagination, error) {
 	return nil, models.Pagination{}, nil
 }
-func (a *artifactRepoStub) SearchApis(ctx context.Context, page, perPage int, organisation *string, query string) ([]models.Api, models.Pagination, error) {
+func (a *artifactRepoStub) SearchApis(ctx context.Context, page models.PageRequest, organisation *string, query string, order models.ApiSort, expand models.ApiExpand) ([]models.Api, models.Pagination, error) {
 	return nil, models.Pagination{}, nil
 }
 func (a *artifactRepoStub) GetApiByID(ctx context.Context, id string) (*models.Api, error) {
 	return nil, nil
 }
+func (a *artifactRepoStub) GetApiExpanded(ctx context.Context, id string, expand models.ApiExpand) (*models.Api, error) {
+	return nil, nil
+}
 func (a *artifactRepoStub) Save(api *models.Api) error { return nil }
 func (a *artifactRepoStub) UpdateApi(ctx context.Context, api models.Api) error {
 	a.updates = append(a.updates, api)
@@ -54,11 +59,11 @@ func (a *artifactRepoStub) SaveLintResult(ctx context.Context, res *models.LintR
 func (a *artifactRepoStub) GetLintResults(ctx context.Context, apiID string) ([]models.LintResult, error) {
 	return nil, nil
 }
-func (a *artifactRepoStub) ListLintResults(ctx context.Context) ([]models.LintResult, error) {
-	return nil, nil
+func (a *artifactRepoStub) ListLintResults(ctx context.Context, page models.PageRequest, filter models.LintResultFilter) ([]models.LintResult, models.Pagination, error) {
+	return nil, models.Pagination{}, nil
 }
-func (a *artifactRepoStub) GetOrganisations(ctx context.Context) ([]models.Organisation, int, error) {
-	return nil, 0, nil
+func (a *artifactRepoStub) GetOrganisations(ctx context.Context, page models.PageRequest) ([]models.Organisation, models.Pagination, error) {
+	return nil, models.Pagination{}, nil
 }
 func (a *artifactRepoStub) FindOrganisationByURI(ctx context.Context, uri string) (*models.Organisation, error) {
 	return nil, nil
@@ -80,6 +85,9 @@ func (a *artifactRepoStub) GetOasArtifact(ctx context.Context, apiID, version, f
 func (a *artifactRepoStub) GetArtifact(ctx context.Context, apiID, kind string) (*models.ApiArtifact, error) {
 	return nil, nil
 }
+func (a *artifactRepoStub) GetArtifactData(ctx context.Context, id string) ([]byte, error) {
+	return nil, nil
+}
 func (a *artifactRepoStub) HasArtifactOfKind(ctx context.Context, apiID, kind string) (bool, error) {
 	for _, art := range a.saved {
 		if art.ApiID == apiID && art.Kind == kind {
@@ -110,6 +118,70 @@ func (a *artifactRepoStub) DeleteArtifactsByKind(ctx context.Context, apiID, kin
 func (a *artifactRepoStub) GetApiFilterCounts(ctx context.Context, p *models.ApiFiltersParams) (*models.ApiFilterCounts, error) {
 	return &models.ApiFilterCounts{}, nil
 }
+func (a *artifactRepoStub) SaveAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	return nil
+}
+func (a *artifactRepoStub) ListAuditEntries(ctx context.Context, page, perPage int, filter models.AuditFilter) ([]models.AuditEntry, models.Pagination, error) {
+	return nil, models.Pagination{}, nil
+}
+func (a *artifactRepoStub) DeleteApi(ctx context.Context, id string) error {
+	return nil
+}
+func (a *artifactRepoStub) GetDeletedApiByID(ctx context.Context, id string) (*models.Api, error) {
+	return nil, nil
+}
+func (a *artifactRepoStub) RestoreApi(ctx context.Context, id string) error {
+	return nil
+}
+func (a *artifactRepoStub) PurgeDeletedApis(ctx context.Context, deletedBefore time.Time) ([]string, error) {
+	return nil, nil
+}
+func (a *artifactRepoStub) ListApisByOrganisation(ctx context.Context, uri string) ([]models.Api, error) {
+	return nil, nil
+}
+func (a *artifactRepoStub) CountApisByOrganisation(ctx context.Context, uri string) (int, error) {
+	return 0, nil
+}
+func (a *artifactRepoStub) DeleteOrganisation(ctx context.Context, uri string) error { return nil }
+func (a *artifactRepoStub) MergeOrganisations(ctx context.Context, duplicateURI, canonicalURI string) ([]string, error) {
+	return nil, nil
+}
+func (a *artifactRepoStub) BumpApiRevisionsByOrganisation(ctx context.Context, uri string) error {
+	return nil
+}
+func (a *artifactRepoStub) FindPendingTransfer(ctx context.Context, apiID string) (*models.ApiTransfer, error) {
+	return nil, nil
+}
+func (a *artifactRepoStub) SaveTransfer(ctx context.Context, transfer *models.ApiTransfer) error {
+	return nil
+}
+func (a *artifactRepoStub) CompleteTransfer(ctx context.Context, transfer *models.ApiTransfer, revision int64) error {
+	return nil
+}
+func (a *artifactRepoStub) ListApisByReviewStatus(ctx context.Context, status string, page, perPage int) ([]models.Api, models.Pagination, error) {
+	return nil, models.Pagination{}, nil
+}
+func (a *artifactRepoStub) ListArtifacts(ctx context.Context, apiID string) ([]models.ApiArtifact, error) {
+	return nil, nil
+}
+func (a *artifactRepoStub) GetArtifactByID(ctx context.Context, apiID, id string) (*models.ApiArtifact, error) {
+	return nil, nil
+}
+func (a *artifactRepoStub) GetLatestLintResult(ctx context.Context, apiID string) (*models.LintResult, error) {
+	return nil, nil
+}
+func (a *artifactRepoStub) GetArazzoArtifact(ctx context.Context, apiID, format string) (*models.ApiArtifact, error) {
+	return nil, nil
+}
+func (a *artifactRepoStub) SaveApiChange(ctx context.Context, change *models.ApiChange) error {
+	return nil
+}
+func (a *artifactRepoStub) ListApiChanges(ctx context.Context, afterSeq int64, limit int, settledBefore time.Time) ([]models.ApiChange, bool, error) {
+	return nil, false, nil
+}
+func (a *artifactRepoStub) SaveReplacement(ctx context.Context, successor *models.Api, predecessorID string, link func(predecessor *models.Api)) (*models.Api, error) {
+	return nil, nil
+}
 
 func TestPersistOASArtifacts_StoresOriginalAndConverted(t *testing.T) {
 	repo := &artifactRepoStub{}
@@ -252,3 +324,19 @@ paths: {}
 	assert.NotEmpty(t, repo.updates)
 	assert.NotEmpty(t, repo.updates[0].OasHash)
 }
+
+func TestBulkCreateApis_LimiterThrottlesItems(t *testing.T) {
+	service := NewAPIsAPIService(&artifactRepoStub{})
+	// Ongeldige items worden zonder fetch afgewezen; alleen de limiter remt.
+	items := []models.ApiPost{{}, {}, {}}
+
+	limiter := rate.NewLimiter(rate.Every(time.Hour), 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := service.bulkCreateApis(ctx, items, limiter)
+	require.Error(t, err, "het tweede item wacht op de limiter")
+
+	report, err := service.bulkCreateApis(context.Background(), items, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Summary.Invalid)
+}
@@ -2,17 +2,22 @@ package services_test
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
+	"sync"
 	"testing"
+	"time"
 
 	httpclient "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/httpclient"
 	openapihelper "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/openapi"
 	problem "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/problem"
 	toolslint "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/tools"
 	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/models"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/repositories"
 	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/services"
 	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 )
 
@@ -28,12 +33,13 @@ type stubRepo struct {
 	saveServer   func(server models.Server) error
 	saveApi      func(api *models.Api) error
 	saveOrg      func(org *models.Organisation) error
-	getOrgs      func(ctx context.Context) ([]models.Organisation, int, error)
+	getOrgs      func(ctx context.Context, page, perPage int) ([]models.Organisation, models.Pagination, error)
 	allApis      func(ctx context.Context) ([]models.Api, error)
 	updateApi    func(ctx context.Context, api models.Api) error
 	updateOAS    func(ctx context.Context, apiID string, oas models.OASMetadata) error
 	delArtifacts func(ctx context.Context, apiID, kind string, keep []string) error
 	filterCounts func(ctx context.Context, p *models.ApiFiltersParams) (*models.ApiFilterCounts, error)
+	saveAudit    func(ctx context.Context, entry *models.AuditEntry) error
 }
 
 func (s *stubRepo) FindByOasUrl(ctx context.Context, url string) (*models.Api, error) {
@@ -45,24 +51,33 @@ func (s *stubRepo) FindOrganisationByURI(ctx context.Context, uri string) (*mode
 func (s *stubRepo) GetApiByID(ctx context.Context, id string) (*models.Api, error) {
 	return s.getByID(ctx, id)
 }
+func (s *stubRepo) GetApiExpanded(ctx context.Context, id string, expand models.ApiExpand) (*models.Api, error) {
+	api, err := s.getByID(ctx, id)
+	if err != nil || api == nil || expand.LintResults == "" {
+		return api, err
+	}
+	api.LintResults, err = s.GetLintResults(ctx, id)
+	return api, err
+}
 func (s *stubRepo) GetLintResults(ctx context.Context, apiID string) ([]models.LintResult, error) {
 	if s.getLintRes != nil {
 		return s.getLintRes(ctx, apiID)
 	}
 	return nil, nil
 }
-func (s *stubRepo) ListLintResults(ctx context.Context) ([]models.LintResult, error) {
+func (s *stubRepo) ListLintResults(ctx context.Context, page models.PageRequest, filter models.LintResultFilter) ([]models.LintResult, models.Pagination, error) {
 	if s.listLintRes != nil {
-		return s.listLintRes(ctx)
+		results, err := s.listLintRes(ctx)
+		return results, models.Pagination{}, err
 	}
-	return []models.LintResult{}, nil
+	return []models.LintResult{}, models.Pagination{}, nil
 }
-func (s *stubRepo) GetApis(ctx context.Context, page, perPage int, p *models.ApiFiltersParams) ([]models.Api, models.Pagination, error) {
-	return s.getApis(ctx, page, perPage, p)
+func (s *stubRepo) GetApis(ctx context.Context, page models.PageRequest, p *models.ApiFiltersParams, order models.ApiSort, expand models.ApiExpand) ([]models.Api, models.Pagination, error) {
+	return s.getApis(ctx, page.Page, page.PerPage, p)
 }
-func (s *stubRepo) SearchApis(ctx context.Context, page, perPage int, organisation *string, query string) ([]models.Api, models.Pagination, error) {
+func (s *stubRepo) SearchApis(ctx context.Context, page models.PageRequest, organisation *string, query string, order models.ApiSort, expand models.ApiExpand) ([]models.Api, models.Pagination, error) {
 	if s.searchApis != nil {
-		return s.searchApis(ctx, page, perPage, organisation, query)
+		return s.searchApis(ctx, page.Page, page.PerPage, organisation, query)
 	}
 	return []models.Api{}, models.Pagination{}, nil
 }
@@ -95,8 +110,8 @@ func (s *stubRepo) AllApis(ctx context.Context) ([]models.Api, error) {
 	return nil, nil
 }
 func (s *stubRepo) SaveLintResult(ctx context.Context, result *models.LintResult) error { return nil }
-func (s *stubRepo) GetOrganisations(ctx context.Context) ([]models.Organisation, int, error) {
-	return s.getOrgs(ctx)
+func (s *stubRepo) GetOrganisations(ctx context.Context, page models.PageRequest) ([]models.Organisation, models.Pagination, error) {
+	return s.getOrgs(ctx, page.Page, page.PerPage)
 }
 func (s *stubRepo) SaveArtifact(ctx context.Context, art *models.ApiArtifact) error { return nil }
 func (s *stubRepo) HasArtifactOfKind(ctx context.Context, apiID, kind string) (bool, error) {
@@ -108,6 +123,9 @@ func (s *stubRepo) GetOasArtifact(ctx context.Context, apiID, version, format st
 func (s *stubRepo) GetArtifact(ctx context.Context, apiID, kind string) (*models.ApiArtifact, error) {
 	return nil, nil
 }
+func (s *stubRepo) GetArtifactData(ctx context.Context, id string) ([]byte, error) {
+	return nil, nil
+}
 func (s *stubRepo) DeleteArtifactsByKind(ctx context.Context, apiID, kind string, keep []string) error {
 	if s.delArtifacts != nil {
 		return s.delArtifacts(ctx, apiID, kind, keep)
@@ -120,6 +138,85 @@ func (s *stubRepo) GetApiFilterCounts(ctx context.Context, p *models.ApiFiltersP
 	}
 	return &models.ApiFilterCounts{}, nil
 }
+func (s *stubRepo) SaveAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	if s.saveAudit != nil {
+		return s.saveAudit(ctx, entry)
+	}
+	return nil
+}
+func (s *stubRepo) ListAuditEntries(ctx context.Context, page, perPage int, filter models.AuditFilter) ([]models.AuditEntry, models.Pagination, error) {
+	return nil, models.Pagination{}, nil
+}
+func (s *stubRepo) DeleteApi(ctx context.Context, id string) error {
+	return nil
+}
+func (s *stubRepo) GetDeletedApiByID(ctx context.Context, id string) (*models.Api, error) {
+	return nil, nil
+}
+func (s *stubRepo) RestoreApi(ctx context.Context, id string) error {
+	return nil
+}
+func (s *stubRepo) PurgeDeletedApis(ctx context.Context, deletedBefore time.Time) ([]string, error) {
+	return nil, nil
+}
+func (s *stubRepo) ListApisByOrganisation(ctx context.Context, uri string) ([]models.Api, error) {
+	return nil, nil
+}
+func (s *stubRepo) CountApisByOrganisation(ctx context.Context, uri string) (int, error) {
+	return 0, nil
+}
+func (s *stubRepo) DeleteOrganisation(ctx context.Context, uri string) error { return nil }
+func (s *stubRepo) MergeOrganisations(ctx context.Context, duplicateURI, canonicalURI string) ([]string, error) {
+	return nil, nil
+}
+func (s *stubRepo) BumpApiRevisionsByOrganisation(ctx context.Context, uri string) error {
+	return nil
+}
+func (s *stubRepo) FindPendingTransfer(ctx context.Context, apiID string) (*models.ApiTransfer, error) {
+	return nil, nil
+}
+func (s *stubRepo) SaveTransfer(ctx context.Context, transfer *models.ApiTransfer) error {
+	return nil
+}
+func (s *stubRepo) CompleteTransfer(ctx context.Context, transfer *models.ApiTransfer, revision int64) error {
+	return nil
+}
+func (s *stubRepo) ListApisByReviewStatus(ctx context.Context, status string, page, perPage int) ([]models.Api, models.Pagination, error) {
+	return nil, models.Pagination{}, nil
+}
+func (s *stubRepo) ListArtifacts(ctx context.Context, apiID string) ([]models.ApiArtifact, error) {
+	return nil, nil
+}
+func (s *stubRepo) GetArtifactByID(ctx context.Context, apiID, id string) (*models.ApiArtifact, error) {
+	return nil, nil
+}
+func (s *stubRepo) GetLatestLintResult(ctx context.Context, apiID string) (*models.LintResult, error) {
+	return nil, nil
+}
+func (s *stubRepo) GetArazzoArtifact(ctx context.Context, apiID, format string) (*models.ApiArtifact, error) {
+	return nil, nil
+}
+func (s *stubRepo) SaveApiChange(ctx context.Context, change *models.ApiChange) error {
+	return nil
+}
+func (s *stubRepo) ListApiChanges(ctx context.Context, afterSeq int64, limit int, settledBefore time.Time) ([]models.ApiChange, bool, error) {
+	return nil, false, nil
+}
+func (s *stubRepo) SaveReplacement(ctx context.Context, successor *models.Api, predecessorID string, link func(predecessor *models.Api)) (*models.Api, error) {
+	if err := s.Save(successor); err != nil {
+		return nil, err
+	}
+	predecessor, err := s.getByID(ctx, predecessorID)
+	if err != nil {
+		return nil, err
+	}
+	link(predecessor)
+	if err := s.UpdateApi(ctx, *predecessor); err != nil {
+		return nil, err
+	}
+	predecessor.Revision++
+	return predecessor, nil
+}
 
 func TestGetOasDocument_InvalidVersion(t *testing.T) {
 	repo := &stubRepo{}
@@ -178,6 +275,7 @@ func TestUpdateOasUri_LifecycleOnlyUpdateWithoutOAS(t *testing.T) {
 	service := services.NewAPIsAPIService(repo)
 	summary, err := service.UpdateOasUri(context.Background(), &models.UpdateApiInput{
 		Id:              "api-123",
+		IfMatch:         `"0"`,
 		OrganisationUri: orgURI,
 		Sunset:          models.NewOptionalString("2027-11-11"),
 		Deprecated:      models.NewOptionalString("2026-10-10"),
@@ -220,6 +318,7 @@ func TestUpdateOasUri_LifecycleOnlyUpdateWithUnchangedUnavailableOASURL(t *testi
 	service := services.NewAPIsAPIService(repo)
 	summary, err := service.UpdateOasUri(context.Background(), &models.UpdateApiInput{
 		Id:              "api-123",
+		IfMatch:         `"0"`,
 		OasUrl:          oasURL,
 		OrganisationUri: orgURI,
 		Sunset:          models.NewOptionalString("2023-12-31"),
@@ -264,6 +363,7 @@ func TestUpdateOasUri_UnchangedOASURLWithoutSunsetStillValidatesOAS(t *testing.T
 	service := services.NewAPIsAPIService(repo)
 	summary, err := service.UpdateOasUri(context.Background(), &models.UpdateApiInput{
 		Id:              "api-123",
+		IfMatch:         `"0"`,
 		OasUrl:          srv.URL,
 		OrganisationUri: orgURI,
 		Contact: models.Contact{
@@ -294,6 +394,7 @@ func TestUpdateOasUri_InvalidLifecycleDate(t *testing.T) {
 	service := services.NewAPIsAPIService(repo)
 	summary, err := service.UpdateOasUri(context.Background(), &models.UpdateApiInput{
 		Id:              "api-123",
+		IfMatch:         `"0"`,
 		OrganisationUri: orgURI,
 		Sunset:          models.NewOptionalString("11-11-2027"),
 	})
@@ -369,7 +470,7 @@ func TestCreateApiFromOas_UsesSpecContactOverBody(t *testing.T) {
 		},
 	}
 
-	summary, err := service.CreateApiFromOas(input)
+	summary, err := service.CreateApiFromOas(context.Background(), input)
 	assert.NoError(t, err)
 	assert.NotNil(t, summary)
 
@@ -478,6 +579,7 @@ func TestUpdateOasUri_PersistsUpdatedFields(t *testing.T) {
 	service := services.NewAPIsAPIService(repo)
 	input := &models.UpdateApiInput{
 		Id:              "api-123",
+		IfMatch:         `"0"`,
 		OasUrl:          srv.URL,
 		OrganisationUri: orgURI,
 		Sunset:          models.NewOptionalString("2027-12-31"),
@@ -638,6 +740,65 @@ func TestRefreshChangedApis_SkipsWhenHashUnchanged(t *testing.T) {
 	assert.Equal(t, 1, snapshotUpdates)
 }
 
+func TestRefreshChangedApis_RefetchesChangedArazzo(t *testing.T) {
+	spec := `{
+  "openapi": "3.0.0",
+  "info": { "title": "Met workflows", "version": "1" },
+  "paths": { "/ping": { "get": { "responses": { "200": { "description": "ok" } } } } }
+}`
+	arazzo := `arazzo: 1.0.1
+info: { title: Workflows, version: 1.0.0 }
+sourceDescriptions: [{ name: api, url: openapi.json }]
+workflows: [{ workflowId: ping, steps: [{ stepId: ping, operationId: ping }] }]
+`
+
+	srv := testutil.NewTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/arazzo.yaml" {
+			_, _ = w.Write([]byte(arazzo))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(spec))
+	}))
+
+	res, err := openapihelper.FetchParseValidateAndHash(context.Background(), toolslint.OASInput{OasUrl: srv.URL}, openapihelper.FetchOpts{})
+	assert.NoError(t, err)
+
+	stored := models.Api{Id: "api-arazzo", OasUri: srv.URL, OasHash: res.Hash, ArazzoUri: srv.URL + "/arazzo.yaml", ArazzoHash: "outdated"}
+	var (
+		updated models.Api
+		cleaned []string
+	)
+	repo := &stubRepo{
+		allApis: func(ctx context.Context) ([]models.Api, error) {
+			return []models.Api{stored}, nil
+		},
+		getByID: func(ctx context.Context, id string) (*models.Api, error) {
+			api := stored
+			return &api, nil
+		},
+		updateApi: func(ctx context.Context, api models.Api) error {
+			updated = api
+			return nil
+		},
+		delArtifacts: func(ctx context.Context, apiID, kind string, keep []string) error {
+			if kind == "arazzo" {
+				cleaned = keep
+			}
+			return nil
+		},
+	}
+
+	service := services.NewAPIsAPIService(repo)
+	count, err := service.RefreshChangedApis(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, srv.URL+"/arazzo.yaml", updated.ArazzoUri)
+	assert.NotEqual(t, "outdated", updated.ArazzoHash)
+	assert.Equal(t, res.Hash, updated.OasHash)
+	assert.Len(t, cleaned, 2)
+}
+
 func TestRefreshChangedApis_MarksOASUnreachableOnFetchError(t *testing.T) {
 	srv := testutil.NewTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadGateway)
@@ -674,6 +835,135 @@ func TestRefreshChangedApis_MarksOASUnreachableOnFetchError(t *testing.T) {
 	assert.Equal(t, models.OASStatusUnreachable, captured.Status)
 }
 
+func TestRefreshChangedApis_KeepsRegisterOverrides(t *testing.T) {
+	spec := `{
+  "openapi": "3.0.0",
+  "info": {
+    "title": "Overrides",
+    "version": "2.0.0",
+    "x-sunset": "2030-01-01",
+    "contact": { "name": "Spec Contact", "email": "spec@example.com" }
+  },
+  "externalDocs": { "url": "https://spec.example.com/docs" },
+  "paths": { "/ping": { "get": { "responses": { "200": { "description": "ok" } } } } }
+}`
+
+	srv := testutil.NewTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(spec))
+	}))
+
+	orgURI := "https://org.example.com"
+	docs := "https://register.example.com/docs"
+	contact := "Register Contact"
+	sunset := "2031-06-30"
+	stored := models.Api{
+		Id:             "api-overrides",
+		OasUri:         srv.URL,
+		OasHash:        "outdated",
+		DocsUrl:        docs,
+		ContactName:    contact,
+		Sunset:         sunset,
+		OrganisationID: &orgURI,
+		Organisation:   &models.Organisation{Uri: orgURI, Label: "Org"},
+		Overrides: models.ApiOverrides{
+			DocsUrl:     &docs,
+			ContactName: &contact,
+			Sunset:      &sunset,
+		},
+	}
+	var updated models.Api
+	repo := &stubRepo{
+		allApis: func(ctx context.Context) ([]models.Api, error) {
+			return []models.Api{stored}, nil
+		},
+		getByID: func(ctx context.Context, id string) (*models.Api, error) {
+			api := stored
+			return &api, nil
+		},
+		updateApi: func(ctx context.Context, api models.Api) error {
+			updated = api
+			return nil
+		},
+	}
+
+	service := services.NewAPIsAPIService(repo)
+	count, err := service.RefreshChangedApis(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, "Overrides", updated.Title)
+	assert.Equal(t, "2.0.0", updated.Version)
+	assert.Equal(t, docs, updated.DocsUrl)
+	assert.Equal(t, contact, updated.ContactName)
+	assert.Equal(t, "spec@example.com", updated.ContactEmail, "velden zonder override komen uit de OAS")
+	assert.Equal(t, sunset, updated.Sunset)
+	assert.Equal(t, stored.Overrides, updated.Overrides)
+}
+
+func TestPatchApi_SetsAndRemovesOverrides(t *testing.T) {
+	docs := "https://register.example.com/docs"
+	var updated models.Api
+	repo := &stubRepo{
+		getByID: func(ctx context.Context, id string) (*models.Api, error) {
+			return &models.Api{
+				Id:           id,
+				OasHash:      "hash",
+				DocsUrl:      docs,
+				ContactName:  "Spec Contact",
+				Organisation: &models.Organisation{Uri: "https://org.example.com", Label: "Org"},
+				Overrides:    models.ApiOverrides{DocsUrl: &docs},
+			}, nil
+		},
+		updateApi: func(ctx context.Context, api models.Api) error {
+			updated = api
+			return nil
+		},
+	}
+	service := services.NewAPIsAPIService(repo)
+
+	var patch models.ApiPatch
+	require.NoError(t, json.Unmarshal([]byte(`{"contact":{"name":"Beheerder"},"repositoryUri":"https://github.com/org/repo","docsUrl":null}`), &patch))
+	patch.Id = "api-patch"
+	patch.IfMatch = "*"
+
+	summary, err := service.PatchApi(context.Background(), &patch)
+	require.NoError(t, err)
+	require.NotNil(t, summary)
+	assert.Equal(t, "Beheerder", updated.ContactName)
+	assert.Equal(t, "https://github.com/org/repo", updated.RepositoryUri)
+	assert.Empty(t, updated.DocsUrl)
+	assert.Nil(t, updated.Overrides.DocsUrl)
+	require.NotNil(t, updated.Overrides.ContactName)
+	assert.Equal(t, "Beheerder", *updated.Overrides.ContactName)
+	assert.Empty(t, updated.OasHash, "volgende refresh moet de OAS-waarde terugzetten")
+
+	t.Run("rejects OAS-owned fields", func(t *testing.T) {
+		var patch models.ApiPatch
+		require.NoError(t, json.Unmarshal([]byte(`{"title":"Nieuw","docsUrl":"https://example.com"}`), &patch))
+		patch.Id = "api-patch"
+		patch.IfMatch = "*"
+		_, err := service.PatchApi(context.Background(), &patch)
+		var apiErr problem.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusBadRequest, apiErr.Status)
+		require.Len(t, apiErr.Errors, 1)
+		assert.Equal(t, "title", apiErr.Errors[0].Location)
+	})
+
+	t.Run("validates values", func(t *testing.T) {
+		for _, body := range []string{`{}`, `{"sunset":"morgen"}`, `{"contact":{"email":"geen-mail"}}`, `{"repositoryUri":"ftp://example.com"}`} {
+			var patch models.ApiPatch
+			require.NoError(t, json.Unmarshal([]byte(body), &patch))
+			patch.Id = "api-patch"
+			patch.IfMatch = "*"
+			_, err := service.PatchApi(context.Background(), &patch)
+			var apiErr problem.APIError
+			require.ErrorAs(t, err, &apiErr, body)
+			assert.Equal(t, http.StatusBadRequest, apiErr.Status, body)
+		}
+	})
+}
+
 func TestRetrieveApi_Success(t *testing.T) {
 	api := &models.Api{
 		Id: "1234",
@@ -688,7 +978,7 @@ func TestRetrieveApi_Success(t *testing.T) {
 		},
 	}
 	service := services.NewAPIsAPIService(repo)
-	resp, err := service.RetrieveApi(context.Background(), "1234")
+	resp, err := service.RetrieveApi(context.Background(), &models.RetrieveApiParams{Id: "1234"})
 	assert.NoError(t, err)
 	assert.Equal(t, api.Id, resp.Id)
 }
@@ -863,6 +1153,37 @@ func TestSearchApis_EmptyQueryReturnsNoResults(t *testing.T) {
 	assert.Equal(t, 0, pagination.TotalPages)
 }
 
+func TestListApis_CursorMustMatchSort(t *testing.T) {
+	var got models.PageRequest
+	repo := &stubRepo{
+		getApis: func(ctx context.Context, page, perPage int, p *models.ApiFiltersParams) ([]models.Api, models.Pagination, error) {
+			got.PerPage = perPage
+			return nil, models.Pagination{}, nil
+		},
+	}
+	service := services.NewAPIsAPIService(repo)
+
+	_, _, err := service.ListApis(context.Background(), &models.ListApisParams{Limit: 5})
+	require.NoError(t, err)
+	assert.Equal(t, 5, got.PerPage)
+
+	cursor := models.Cursor{Sort: "title", ID: "api-1"}.Encode()
+	_, _, err = service.ListApis(context.Background(), &models.ListApisParams{Cursor: cursor})
+	require.NoError(t, err)
+	assert.Equal(t, 10, got.PerPage, "limit is standaard 10")
+
+	for _, params := range []*models.ListApisParams{
+		{Cursor: cursor, Sort: "-adrScore"},
+		{Cursor: "kapot"},
+		{Limit: -1},
+	} {
+		_, _, err = service.ListApis(context.Background(), params)
+		var apiErr problem.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusBadRequest, apiErr.Status)
+	}
+}
+
 func TestCreateApiFromOas_Success(t *testing.T) {
 	spec := `{
   "openapi": "3.0.0",
@@ -910,7 +1231,7 @@ func TestCreateApiFromOas_Success(t *testing.T) {
 		OasUrl:          server.URL,
 		OrganisationUri: "https://example.com",
 	}
-	resp, err := service.CreateApiFromOas(apiReq)
+	resp, err := service.CreateApiFromOas(context.Background(), apiReq)
 	assert.NoError(t, err)
 	assert.Equal(t, saved.Id, resp.Id)
 	assert.Equal(t, "T", resp.Title)
@@ -919,19 +1240,121 @@ func TestCreateApiFromOas_Success(t *testing.T) {
 	assert.Equal(t, "", saved.OAS.Auth)
 }
 
+func TestTransferApi_WithoutPrincipalIsForbidden(t *testing.T) {
+	from, to := "https://example.org/oud", "https://example.org/nieuw"
+	repo := &stubRepo{
+		getByID: func(ctx context.Context, id string) (*models.Api, error) {
+			return &models.Api{Id: id, OrganisationID: &from}, nil
+		},
+		findOrg: func(ctx context.Context, uri string) (*models.Organisation, error) {
+			return &models.Organisation{Uri: uri}, nil
+		},
+	}
+	service := services.NewAPIsAPIService(repo)
+
+	_, err := service.TransferApi(context.Background(), &models.ApiTransferInput{Id: "api-1", OrganisationUri: to})
+	var apiErr problem.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusForbidden, apiErr.Status)
+}
+
+func TestCreateApiFromOas_ReplacesPredecessor(t *testing.T) {
+	spec := `{
+  "openapi": "3.0.0",
+  "info": { "title": "Opvolger", "version": "2.0.0", "contact": { "name": "Team", "email": "team@example.com", "url": "https://example.com" } },
+  "paths": { "/ping": { "get": { "responses": { "200": { "description": "pong" } } } } }
+}`
+	server := testutil.NewTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(spec))
+	}))
+
+	orgURI := "https://example.com"
+	successor := "api-nieuw"
+	updated := map[string]models.Api{}
+	repo := &stubRepo{
+		saveServer: func(server models.Server) error { return nil },
+		saveApi:    func(api *models.Api) error { return nil },
+		findOrg: func(ctx context.Context, uri string) (*models.Organisation, error) {
+			return &models.Organisation{Uri: uri, Label: "Org"}, nil
+		},
+		getByID: func(ctx context.Context, id string) (*models.Api, error) {
+			switch id {
+			case "api-oud":
+				return &models.Api{Id: id, Organisation: &models.Organisation{Uri: orgURI}, OrganisationID: &orgURI, Deprecated: "2026-01-01"}, nil
+			case "api-al-opgevolgd":
+				return &models.Api{Id: id, OrganisationID: &orgURI, ReplacedByID: &successor}, nil
+			}
+			if api, ok := updated[id]; ok {
+				return &api, nil
+			}
+			return nil, nil
+		},
+		updateApi: func(ctx context.Context, api models.Api) error {
+			updated[api.Id] = api
+			return nil
+		},
+	}
+	service := services.NewAPIsAPIService(repo)
+
+	resp, err := service.CreateApiFromOas(context.Background(), models.ApiPost{
+		OasUrl:          server.URL,
+		OrganisationUri: orgURI,
+		Replaces:        "api-oud",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp.Links.Replaces)
+	assert.Equal(t, "/v1/apis/api-oud", resp.Links.Replaces.Href)
+
+	created := updated[resp.Id]
+	require.NotNil(t, created.ReplacesID)
+	assert.Equal(t, "api-oud", *created.ReplacesID)
+	require.NotNil(t, resp.Review)
+	assert.Equal(t, models.ReviewStatusPending, resp.Review.Status)
+	_, marked := updated["api-oud"]
+	assert.False(t, marked, "voorganger blijft ongemoeid tot de registratie is goedgekeurd")
+
+	_, err = service.ApproveApi(context.Background(), resp.Id)
+	require.NoError(t, err)
+	assert.Equal(t, models.ReviewStatusApproved, updated[resp.Id].ReviewStatus)
+
+	predecessor := updated["api-oud"]
+	require.NotNil(t, predecessor.ReplacedByID)
+	assert.Equal(t, resp.Id, *predecessor.ReplacedByID)
+	assert.Equal(t, "2026-01-01", predecessor.Deprecated, "bestaande deprecated-datum blijft staan")
+	expectedSunset := time.Now().UTC().Add(180 * 24 * time.Hour).Format(time.DateOnly)
+	assert.Equal(t, expectedSunset, predecessor.Sunset)
+	require.NotNil(t, predecessor.Overrides.Sunset)
+	assert.Equal(t, expectedSunset, *predecessor.Overrides.Sunset)
+
+	t.Run("unknown predecessor", func(t *testing.T) {
+		_, err := service.CreateApiFromOas(context.Background(), models.ApiPost{OasUrl: server.URL, OrganisationUri: orgURI, Replaces: "onbekend"})
+		var apiErr problem.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusBadRequest, apiErr.Status)
+	})
+
+	t.Run("predecessor already replaced", func(t *testing.T) {
+		_, err := service.CreateApiFromOas(context.Background(), models.ApiPost{OasUrl: server.URL, OrganisationUri: orgURI, Replaces: "api-al-opgevolgd"})
+		var apiErr problem.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusConflict, apiErr.Status)
+	})
+}
+
 func TestListOrganisations_Service(t *testing.T) {
 	repo := &stubRepo{
-		getOrgs: func(ctx context.Context) ([]models.Organisation, int, error) {
+		getOrgs: func(ctx context.Context, page, perPage int) ([]models.Organisation, models.Pagination, error) {
 			orgs := []models.Organisation{
 				{Uri: "https://example.org/a", Label: "A"},
 				{Uri: "https://example.org/b", Label: "B"},
 			}
-			return orgs, len(orgs), nil
+			return orgs, models.Pagination{TotalRecords: len(orgs)}, nil
 		},
 	}
 
 	service := services.NewAPIsAPIService(repo)
-	orgs, _, err := service.ListOrganisations(context.Background())
+	orgs, _, err := service.ListOrganisations(context.Background(), &models.ListOrganisationsParams{Page: 1, PerPage: 10})
 
 	assert.NoError(t, err)
 	assert.Len(t, orgs, 2)
@@ -965,9 +1388,17 @@ func TestPublishAllApisToTypesense_Disabled(t *testing.T) {
 }
 
 func TestPublishAllApisToTypesense_SendsDocuments(t *testing.T) {
-	var calls int
+	var mu sync.Mutex
+	published := map[string]int{}
 	server := testutil.NewTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		calls++
+		// Asynchrone publicaties uit eerdere tests kunnen hier binnenkomen; tel alleen de eigen documenten.
+		var doc struct {
+			ID string `json:"id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&doc)
+		mu.Lock()
+		published[doc.ID]++
+		mu.Unlock()
 		w.WriteHeader(http.StatusCreated)
 	}))
 
@@ -988,5 +1419,29 @@ func TestPublishAllApisToTypesense_SendsDocuments(t *testing.T) {
 	service := services.NewAPIsAPIService(repo)
 	err := service.PublishAllApisToTypesense(context.Background())
 	assert.NoError(t, err)
-	assert.Equal(t, 2, calls)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, published["api-1"])
+	assert.Equal(t, 1, published["api-2"])
+}
+
+func TestReviewApi_RevisionConflictIsPreconditionFailed(t *testing.T) {
+	repo := &stubRepo{
+		getByID: func(ctx context.Context, id string) (*models.Api, error) {
+			return &models.Api{Id: id, Revision: 3, ReviewStatus: models.ReviewStatusPending}, nil
+		},
+		updateApi: func(ctx context.Context, api models.Api) error {
+			return repositories.ErrRevisionConflict
+		},
+	}
+	service := services.NewAPIsAPIService(repo)
+
+	_, err := service.ApproveApi(context.Background(), "api-1")
+	var apiErr problem.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusPreconditionFailed, apiErr.Status)
+
+	_, err = service.RejectApi(context.Background(), &models.ApiRejectInput{Id: "api-1", Reason: "onvolledig"})
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusPreconditionFailed, apiErr.Status)
 }
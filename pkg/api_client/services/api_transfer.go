@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/auth"
+	problem "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/problem"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/models"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/repositories"
+	"github.com/google/uuid"
+)
+
+// TransferApi draagt een API over aan een andere organisatie. Beide
+// organisaties moeten instemmen: de eerste aanroep legt een openstaande
+// overdracht vast, de aanroep namens de andere organisatie voltooit hem. Een
+// beheerder, of een aanroeper die namens beide organisaties mag handelen,
+// voltooit de overdracht direct.
+func (s *APIsAPIService) TransferApi(ctx context.Context, body *models.ApiTransferInput) (*models.ApiTransfer, error) {
+	api, err := s.repo.GetApiByID(ctx, body.Id)
+	if err != nil {
+		return nil, err
+	}
+	if api == nil {
+		return nil, problem.NewNotFound(body.Id, "Api not found")
+	}
+	from := deriveOrganisationURI(api)
+	to := strings.TrimSpace(body.OrganisationUri)
+	if to == from {
+		return nil, problem.NewBadRequest(to, "De API is al van deze organisatie",
+			problem.InvalidParam{Name: "organisationUri", Reason: "Moet een andere organisatie zijn dan de huidige eigenaar"})
+	}
+	target, err := s.repo.FindOrganisationByURI(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, problem.NewBadRequest(to, "Onbekende organisatie",
+			problem.InvalidParam{Name: "organisationUri", Reason: "Moet een geregistreerde organisatie zijn"})
+	}
+
+	// Instemming vraagt altijd een aanroeper die namens de organisatie mag
+	// handelen; zonder principal stemt niemand in.
+	principal := auth.PrincipalFromContext(ctx)
+	actsForFrom := principal.CanActFor(from)
+	actsForTo := principal.CanActFor(to)
+	if !actsForFrom && !actsForTo {
+		return nil, problem.NewForbidden(from, fmt.Sprintf("geen schrijfrechten voor organisatie '%s' of '%s'", from, to))
+	}
+
+	transfer, err := s.repo.FindPendingTransfer(ctx, api.Id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer != nil && (transfer.FromOrganisationUri != from || transfer.ToOrganisationUri != to) {
+		// Alleen de huidige eigenaar mag een lopende aanvraag vervangen.
+		if !actsForFrom {
+			return nil, problem.NewConflict(api.Id, fmt.Sprintf("Er loopt al een overdracht van deze API naar '%s'", transfer.ToOrganisationUri))
+		}
+		transfer.FromOrganisationUri = from
+		transfer.ToOrganisationUri = to
+		transfer.ApprovedByFrom = ""
+		transfer.ApprovedByTo = ""
+	}
+	if transfer == nil {
+		transfer = &models.ApiTransfer{
+			ID:                  uuid.New().String(),
+			ApiID:               api.Id,
+			FromOrganisationUri: from,
+			ToOrganisationUri:   to,
+			Status:              models.ApiTransferPending,
+			CreatedAt:           time.Now().UTC(),
+		}
+	}
+	actor := auditActor(ctx)
+	if actsForFrom {
+		transfer.ApprovedByFrom = actor
+	}
+	if actsForTo {
+		transfer.ApprovedByTo = actor
+	}
+
+	if transfer.ApprovedByFrom == "" || transfer.ApprovedByTo == "" {
+		if err := s.repo.SaveTransfer(ctx, transfer); err != nil {
+			return nil, problem.NewInternalServerError("kan overdracht niet opslaan: " + err.Error())
+		}
+		return transfer, nil
+	}
+
+	completedAt := time.Now().UTC()
+	transfer.Status = models.ApiTransferCompleted
+	transfer.CompletedAt = &completedAt
+	if err := s.repo.CompleteTransfer(ctx, transfer, api.Revision); err != nil {
+		if errors.Is(err, repositories.ErrRevisionConflict) {
+			return nil, problem.NewConflict(api.Id, fmt.Sprintf("API '%s' is tijdens de overdracht gewijzigd; probeer het opnieuw", api.Id)).WithCause(err)
+		}
+		return nil, problem.NewInternalServerError("kan API niet overdragen: " + err.Error())
+	}
+
+	// CompleteTransfer verhoogt de revisie en zet updated_at op het moment van
+	// afronden; houd de kopie in het geheugen gelijk, net als updateApi.
+	before := cloneApi(api)
+	api.OrganisationID = &to
+	api.Organisation = target
+	api.Revision++
+	api.UpdatedAt = &completedAt
+	s.recordApiAudit(ctx, models.AuditActionApiTransferred, before, api)
+
+	go s.publishToTypesense(*api)
+	return transfer, nil
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/openapi"
+	problem "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/problem"
+	toolslint "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/tools"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/util"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/models"
+)
+
+// ValidateApiFromOas doorloopt dezelfde stappen als CreateApiFromOas tot en met
+// de ADR-lint, maar slaat niets op, genereert geen artifacts en publiceert niet.
+// Fouten die een registratie al voor het bouwen van de API zouden stoppen
+// (autorisatie, onleesbare OAS) geven dezelfde foutresponse als POST /apis.
+func (s *APIsAPIService) ValidateApiFromOas(ctx context.Context, requestBody models.ApiPost) (*models.ApiValidation, error) {
+	if err := authorizeOrganisation(ctx, requestBody.OrganisationUri); err != nil {
+		return nil, err
+	}
+	predecessor, err := s.findPredecessor(ctx, requestBody.Replaces)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fetchArazzo(ctx, toArazzoInput(requestBody)); err != nil {
+		return nil, err
+	}
+
+	oasInput := toolslint.OASInput{
+		OasUrl:  requestBody.OasUrl,
+		OasBody: requestBody.OasBody,
+	}
+	resp, err := openapi.FetchParseValidateAndHash(ctx, oasInput, openapi.FetchOpts{
+		Origin: "https://developer.overheid.nl",
+	})
+	if err != nil {
+		return nil, problem.NewBadRequest(requestBody.OasUrl, err.Error())
+	}
+
+	label, _, err := s.resolveOrganisationLabel(ctx, requestBody.OrganisationUri)
+	if err != nil {
+		return nil, err
+	}
+	api := openapi.BuildApi(resp.Spec, requestBody, label)
+	applyOASSnapshot(api, resp)
+	if predecessor != nil {
+		api.ReplacesID = &predecessor.Id
+	}
+	applyInitialReview(ctx, api)
+	// De API bestaat nog niet; een id zou suggereren dat hij wel is aangemaakt.
+	api.Id = ""
+
+	result := &models.ApiValidation{
+		InvalidParams:   openapi.ValidateApi(api),
+		Auth:            strings.TrimSpace(api.Auth),
+		LifecycleStatus: api.LifecycleStatus(time.Now()),
+	}
+	if result.Conflict, err = s.findOasUrlConflict(ctx, api.OasUri); err != nil {
+		return nil, err
+	}
+
+	// Geen withRateLimit: die limiter is voor de achtergrondjobs. Een dry-run
+	// valt onder de rate limiting per client.
+	dto, lintErr := toolslint.LintGet(ctx, oasInput)
+	if dto != nil {
+		lint, score := lintResultFromDTO("", dto)
+		result.Lint = lint
+		api.AdrScore = &score
+	} else if lintErr != nil {
+		result.LintError = lintErr.Error()
+	}
+
+	result.Api = util.ToApiDetail(api)
+	result.Valid = len(result.InvalidParams) == 0 && result.Conflict == nil
+	return result, nil
+}
+
+// findOasUrlConflict zoekt een bestaande registratie met dezelfde oasUrl. Een
+// registratie die nog niet is goedgekeurd wordt alleen getoond aan wie hem mag
+// inzien.
+func (s *APIsAPIService) findOasUrlConflict(ctx context.Context, oasUrl string) (*models.ApiConflict, error) {
+	oasUrl = strings.TrimSpace(oasUrl)
+	if oasUrl == "" {
+		return nil, nil
+	}
+	existing, err := s.repo.FindByOasUrl(ctx, oasUrl)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, nil
+	}
+	conflict := &models.ApiConflict{Detail: fmt.Sprintf("Er is al een API geregistreerd met oasUrl %s", oasUrl)}
+	if existing.IsApproved() || canSeeUnreviewed(ctx, existing) {
+		summary := util.ToApiSummary(existing)
+		conflict.Api = &summary
+	}
+	return conflict, nil
+}
@@ -0,0 +1,321 @@
+package services
+
+import (
+	"context"
+	"log"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/auth"
+	problem "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/problem"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const systemActorPrefix = "system:"
+
+type systemActorKey struct{}
+
+// withSystemActor markeert een interne aanroep (refresh-job, harvester) zodat
+// de audit trail laat zien welk proces de wijziging deed.
+func withSystemActor(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, systemActorKey{}, name)
+}
+
+// auditActor bepaalt de actor voor de audit trail: de geauthenticeerde
+// aanroeper, anders het interne proces.
+func auditActor(ctx context.Context) string {
+	if id := auth.PrincipalFromContext(ctx).ID(); id != "" {
+		return id
+	}
+	if name, ok := ctx.Value(systemActorKey{}).(string); ok && name != "" {
+		return systemActorPrefix + name
+	}
+	return systemActorPrefix + "unknown"
+}
+
+// recordApiAudit schrijft een audit-regel met de veldwijzigingen tussen before
+// en after. Fouten worden gelogd; de mutatie zelf is dan al geslaagd.
+func (s *APIsAPIService) recordApiAudit(ctx context.Context, action string, before, after *models.Api) {
+	subject := after
+	if subject == nil {
+		subject = before
+	}
+	if subject == nil {
+		return
+	}
+	changes := diffApi(before, after)
+	s.recordApiChange(ctx, subject.Id, apiChangeType(before, after, changes))
+	if len(changes) == 0 && action != models.AuditActionApiCreated {
+		return
+	}
+	apiID := subject.Id
+	s.saveAudit(ctx, &models.AuditEntry{
+		ApiID:           &apiID,
+		OrganisationUri: deriveOrganisationURI(subject),
+		Action:          action,
+		Changes:         changes,
+	})
+}
+
+// apiChangeType bepaalt welke gebeurtenis een mutatie in de wijzigingsfeed
+// oplevert. De feed volgt de publieke weergave: een API die zichtbaar wordt
+// is created, een API die verdwijnt deleted. Leeg betekent geen gebeurtenis.
+func apiChangeType(before, after *models.Api, changes []models.FieldChange) string {
+	wasPublic, isPublic := isPublicApi(before), isPublicApi(after)
+	switch {
+	case !wasPublic && isPublic:
+		return models.ApiChangeCreated
+	case wasPublic && !isPublic:
+		return models.ApiChangeDeleted
+	case !isPublic:
+		return ""
+	}
+	for _, change := range changes {
+		if change.Field == "sunset" || change.Field == "deprecated" {
+			return models.ApiChangeLifecycleChanged
+		}
+	}
+	if len(changes) > 0 || !equalTime(before.LastOASChangeAt, after.LastOASChangeAt) {
+		return models.ApiChangeUpdated
+	}
+	return ""
+}
+
+func isPublicApi(api *models.Api) bool {
+	return api != nil && api.IsApproved() && !api.DeletedAt.Valid
+}
+
+func equalTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// recordApiChange zet een gebeurtenis in de wijzigingsfeed. Net als bij de
+// audit trail wordt een fout gelogd.
+func (s *APIsAPIService) recordApiChange(ctx context.Context, apiID, changeType string) {
+	if changeType == "" {
+		return
+	}
+	change := &models.ApiChange{ApiID: apiID, Type: changeType, OccurredAt: time.Now().UTC()}
+	if err := s.repo.SaveApiChange(context.WithoutCancel(ctx), change); err != nil {
+		log.Printf("[changes] kan wijziging niet opslaan api=%s type=%s: %v", apiID, changeType, err)
+	}
+}
+
+func (s *APIsAPIService) recordOrganisationAudit(ctx context.Context, org *models.Organisation) {
+	s.saveAudit(ctx, &models.AuditEntry{
+		OrganisationUri: org.Uri,
+		Action:          models.AuditActionOrganisationCreated,
+		Changes: []models.FieldChange{
+			{Field: "uri", After: org.Uri},
+			{Field: "label", After: org.Label},
+		},
+	})
+}
+
+func (s *APIsAPIService) saveAudit(ctx context.Context, entry *models.AuditEntry) {
+	entry.ID = uuid.New().String()
+	entry.Actor = auditActor(ctx)
+	entry.CreatedAt = time.Now().UTC()
+	if err := s.repo.SaveAuditEntry(context.WithoutCancel(ctx), entry); err != nil {
+		log.Printf("[audit] kan audit-regel niet opslaan action=%s actor=%s: %v", entry.Action, entry.Actor, err)
+	}
+}
+
+// ListAudit geeft de globale audit trail terug, nieuwste eerst.
+func (s *APIsAPIService) ListAudit(ctx context.Context, p *models.ListAuditParams) ([]models.AuditEntry, models.Pagination, error) {
+	filter := models.AuditFilter{
+		ApiID:        trimmedValue(p.ApiId),
+		Organisation: trimmedValue(p.Organisation),
+		Actor:        trimmedValue(p.Actor),
+		Action:       trimmedValue(p.Action),
+	}
+	var err error
+	if filter.Since, err = parseAuditTime("since", p.Since); err != nil {
+		return nil, models.Pagination{}, err
+	}
+	if filter.Until, err = parseAuditTime("until", p.Until); err != nil {
+		return nil, models.Pagination{}, err
+	}
+	entries, pagination, err := s.repo.ListAuditEntries(ctx, p.Page, p.PerPage, filter)
+	if err != nil {
+		return nil, models.Pagination{}, err
+	}
+	return nonNilEntries(entries), pagination, nil
+}
+
+// ListApiAudit geeft de audit trail van één API terug.
+func (s *APIsAPIService) ListApiAudit(ctx context.Context, p *models.ApiAuditParams) ([]models.AuditEntry, models.Pagination, error) {
+	api, err := s.repo.GetApiByID(ctx, p.Id)
+	if err != nil {
+		return nil, models.Pagination{}, err
+	}
+	if api == nil {
+		return nil, models.Pagination{}, problem.NewNotFound(p.Id, "Api not found")
+	}
+	entries, pagination, err := s.repo.ListAuditEntries(ctx, p.Page, p.PerPage, models.AuditFilter{ApiID: api.Id})
+	if err != nil {
+		return nil, models.Pagination{}, err
+	}
+	return nonNilEntries(entries), pagination, nil
+}
+
+func nonNilEntries(entries []models.AuditEntry) []models.AuditEntry {
+	if entries == nil {
+		return []models.AuditEntry{}
+	}
+	return entries
+}
+
+func trimmedValue(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return strings.TrimSpace(*v)
+}
+
+func parseAuditTime(name string, value *string) (*time.Time, error) {
+	raw := trimmedValue(value)
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, problem.NewBadRequest(raw, "ongeldige datum",
+			problem.InvalidParam{Name: name, Reason: "Moet een RFC 3339 datum-tijd zijn"})
+	}
+	return &t, nil
+}
+
+// cloneApi maakt een kopie die los staat van latere wijzigingen aan api, zodat
+// die als "before" in de audit trail kan dienen.
+func cloneApi(api *models.Api) *models.Api {
+	if api == nil {
+		return nil
+	}
+	clone := *api
+	if api.AdrScore != nil {
+		score := *api.AdrScore
+		clone.AdrScore = &score
+	}
+	if api.OrganisationID != nil {
+		orgID := *api.OrganisationID
+		clone.OrganisationID = &orgID
+	}
+	if api.Organisation != nil {
+		org := *api.Organisation
+		clone.Organisation = &org
+	}
+	clone.Servers = append([]models.Server(nil), api.Servers...)
+	return &clone
+}
+
+// diffApi vergelijkt de persistente velden van twee versies van een API.
+// Relaties worden overgeslagen, behalve de server-URL's.
+func diffApi(before, after *models.Api) []models.FieldChange {
+	beforeFields := auditFields(before)
+	afterFields := auditFields(after)
+	changes := make([]models.FieldChange, 0)
+	for _, name := range auditFieldNames() {
+		b, a := beforeFields[name], afterFields[name]
+		if reflect.DeepEqual(b, a) {
+			continue
+		}
+		changes = append(changes, models.FieldChange{Field: name, Before: b, After: a})
+	}
+	return changes
+}
+
+func auditFieldNames() []string {
+	var names []string
+	collectAuditFields(reflect.ValueOf(models.Api{}), "", func(name string, _ any) {
+		names = append(names, name)
+	})
+	return names
+}
+
+func auditFields(api *models.Api) map[string]any {
+	fields := make(map[string]any)
+	if api == nil {
+		return fields
+	}
+	collectAuditFields(reflect.ValueOf(*api), "", func(name string, value any) {
+		fields[name] = value
+	})
+	return fields
+}
+
+func collectAuditFields(v reflect.Value, prefix string, emit func(name string, value any)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		// Velden met audit:"-" tellen niet als inhoudelijke wijziging.
+		if !field.IsExported() || field.Tag.Get("audit") == "-" {
+			continue
+		}
+		name := prefix + auditFieldName(field)
+		value := v.Field(i)
+
+		switch {
+		case field.Type == reflect.TypeOf(gorm.DeletedAt{}):
+			if deletedAt := value.Interface().(gorm.DeletedAt); deletedAt.Valid {
+				emit(name, deletedAt.Time.UTC().Format(time.RFC3339))
+			} else {
+				emit(name, nil)
+			}
+		case field.Type == reflect.TypeOf([]models.Server{}):
+			uris := make([]string, 0, value.Len())
+			for _, srv := range value.Interface().([]models.Server) {
+				uris = append(uris, srv.Uri)
+			}
+			emit(name, uris)
+		case field.Type == reflect.TypeOf(time.Time{}):
+			ts := value.Interface().(time.Time)
+			if ts.IsZero() {
+				emit(name, nil)
+			} else {
+				emit(name, ts.UTC().Format(time.RFC3339))
+			}
+		case field.Type.Kind() == reflect.Struct:
+			collectAuditFields(value, name+".", emit)
+		case field.Type.Kind() == reflect.Pointer:
+			if field.Type.Elem().Kind() == reflect.Struct && field.Type.Elem() != reflect.TypeOf(time.Time{}) {
+				continue // relatie, bv. Organisation
+			}
+			if value.IsNil() {
+				emit(name, nil)
+			} else {
+				emit(name, auditValue(value.Elem()))
+			}
+		case field.Type.Kind() == reflect.Slice:
+			continue // overige relaties
+		default:
+			emit(name, value.Interface())
+		}
+	}
+}
+
+func auditValue(v reflect.Value) any {
+	if ts, ok := v.Interface().(time.Time); ok {
+		return ts.UTC().Format(time.RFC3339)
+	}
+	return v.Interface()
+}
+
+func auditFieldName(field reflect.StructField) string {
+	if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag != "" && tag != "-" {
+		return tag
+	}
+	if strings.ToUpper(field.Name) == field.Name {
+		return strings.ToLower(field.Name) // bv. OAS
+	}
+	r, size := utf8.DecodeRuneInString(field.Name)
+	return string(unicode.ToLower(r)) + field.Name[size:]
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/auth"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestDiffApi_FieldLevelChanges(t *testing.T) {
+	score := 80
+	org := "https://example.org/org"
+	before := &models.Api{
+		Id:             "api-1",
+		Title:          "Oud",
+		AdrScore:       &score,
+		OrganisationID: &org,
+		OAS:            models.OASMetadata{Version: "3.0.3", Status: models.OASStatusValid},
+		Servers:        []models.Server{{Uri: "https://api.example.org/v1"}},
+		Sunset:         "2030-01-01",
+	}
+	after := cloneApi(before)
+	newScore := 90
+	after.Title = "Nieuw"
+	after.AdrScore = &newScore
+	after.OAS.Status = models.OASStatusInvalid
+	after.Servers = append(after.Servers, models.Server{Uri: "https://api.example.org/v2"})
+	after.Sunset = ""
+
+	changes := diffApi(before, after)
+	byField := make(map[string]models.FieldChange, len(changes))
+	for _, c := range changes {
+		byField[c.Field] = c
+	}
+	require.Len(t, changes, 5)
+	assert.Equal(t, models.FieldChange{Field: "title", Before: "Oud", After: "Nieuw"}, byField["title"])
+	assert.Equal(t, models.FieldChange{Field: "adrScore", Before: 80, After: 90}, byField["adrScore"])
+	assert.Equal(t, models.FieldChange{Field: "oas.status", Before: "valid", After: "invalid"}, byField["oas.status"])
+	assert.Equal(t, []string{"https://api.example.org/v1", "https://api.example.org/v2"}, byField["servers"].After)
+	assert.Equal(t, "", byField["sunset"].After)
+
+	// before is niet meegewijzigd
+	assert.Equal(t, 80, *before.AdrScore)
+	assert.Len(t, before.Servers, 1)
+}
+
+func TestDiffApi_CreatedHasOnlyAfterValues(t *testing.T) {
+	changes := diffApi(nil, &models.Api{Id: "api-1", Title: "Nieuw"})
+	for _, c := range changes {
+		assert.Nil(t, c.Before, c.Field)
+	}
+	assert.Contains(t, changes, models.FieldChange{Field: "title", Before: nil, After: "Nieuw"})
+}
+
+func TestAuditActor(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, "system:unknown", auditActor(ctx))
+	assert.Equal(t, "system:oas-refresh", auditActor(withSystemActor(ctx, "oas-refresh")))
+
+	withClient := auth.WithPrincipal(withSystemActor(ctx, "harvester"), &auth.Principal{Subject: "sub", ClientID: "portaal"})
+	assert.Equal(t, "portaal", auditActor(withClient))
+}
+
+func TestApiChangeType_FollowsPublicView(t *testing.T) {
+	public := &models.Api{Id: "api-1", Title: "Oud"}
+	pending := cloneApi(public)
+	pending.ReviewStatus = models.ReviewStatusPending
+	deleted := cloneApi(public)
+	deleted.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	renamed := cloneApi(public)
+	renamed.Title = "Nieuw"
+	sunset := cloneApi(public)
+	sunset.Sunset = "2031-01-01"
+	now := time.Now()
+	refreshed := cloneApi(public)
+	refreshed.LastOASChangeAt = &now
+
+	tests := []struct {
+		name          string
+		before, after *models.Api
+		expect        string
+	}{
+		{"created", nil, public, models.ApiChangeCreated},
+		{"created pending", nil, pending, ""},
+		{"approved", pending, public, models.ApiChangeCreated},
+		{"restored", deleted, public, models.ApiChangeCreated},
+		{"deleted", public, deleted, models.ApiChangeDeleted},
+		{"rejected", public, pending, models.ApiChangeDeleted},
+		{"updated", public, renamed, models.ApiChangeUpdated},
+		{"lifecycle", public, sunset, models.ApiChangeLifecycleChanged},
+		{"oas changed", public, refreshed, models.ApiChangeUpdated},
+		{"unchanged", public, cloneApi(public), ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expect, apiChangeType(tc.before, tc.after, diffApi(tc.before, tc.after)))
+		})
+	}
+}
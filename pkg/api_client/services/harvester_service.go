@@ -5,12 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
 	"strings"
-	"time"
 
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/httpclient"
 	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/models"
 	"golang.org/x/time/rate"
 )
@@ -19,19 +17,24 @@ const (
 	// Defaults for PDOK-like sources
 	defaultUISuffix = "ui/"
 	defaultOASPath  = "openapi.json"
+
+	// harvestRate is het aantal OAS-fetches per seconde tijdens een harvest.
+	harvestRate = 2
 )
 
 // HarvesterService haalt index.json op, leidt OAS-URLs af en slaat ze op
 type HarvesterService struct {
-	httpClient *http.Client
+	fetcher    *httpclient.Fetcher // optioneel, standaard httpclient.DefaultFetcher()
 	apiService *APIsAPIService
+	limiter    *rate.Limiter // remt de OAS-fetches over alle batches heen
 }
 
 // NewHarvesterService maakt een nieuwe service met een verplichte api service
 func NewHarvesterService(apiService *APIsAPIService) *HarvesterService {
 	return &HarvesterService{
-		httpClient: &http.Client{Timeout: 30 * time.Second},
 		apiService: apiService,
+		// (2 requests per seconde, burst van 1)
+		limiter: rate.NewLimiter(rate.Limit(harvestRate), 1),
 	}
 }
 
@@ -43,33 +46,26 @@ func (s *HarvesterService) RunOnce(ctx context.Context, src models.HarvestSource
 	if strings.TrimSpace(src.IndexURL) == "" {
 		return errors.New("source indexUrl is empty")
 	}
+	ctx = withSystemActor(ctx, "harvester")
 
 	// Fetch index
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src.IndexURL, nil)
-	if err != nil {
-		return err
+	fetcher := s.fetcher
+	if fetcher == nil {
+		fetcher = httpclient.DefaultFetcher()
 	}
-	resp, err := s.httpClient.Do(req)
+	resp, err := fetcher.Get(ctx, src.IndexURL, nil)
 	if err != nil {
 		return err
 	}
-
-	defer func() {
-		if closeErr := resp.Body.Close(); closeErr != nil && err == nil {
-			err = fmt.Errorf("close harvester response body: %w", closeErr)
-		}
-	}()
-
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		b, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		b := resp.Body
+		if len(b) > 4<<10 {
+			b = b[:4<<10]
+		}
 		return fmt.Errorf("unexpected status %d from index: %s", resp.StatusCode, string(b))
 	}
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return err
-	}
 
-	hrefs, err := extractIndexHrefs(body)
+	hrefs, err := extractIndexHrefs(resp.Body)
 	if err != nil {
 		return err
 	}
@@ -87,34 +83,40 @@ func (s *HarvesterService) RunOnce(ctx context.Context, src models.HarvestSource
 		oasPath = defaultOASPath
 	}
 
-	var aggErrs []string
-	successCount := 0
-	// (2 requests per seconde, burst van 1)
-	limiter := rate.NewLimiter(rate.Limit(2), 1)
-
+	items := make([]models.ApiPost, 0, len(hrefs))
 	for _, href := range hrefs {
-		oasURL := deriveOASURLWith(href, uiSuffix, oasPath)
-		payload := models.ApiPost{
-			OasUrl:          oasURL,
+		items = append(items, models.ApiPost{
+			OasUrl:          deriveOASURLWith(href, uiSuffix, oasPath),
 			OrganisationUri: src.OrganisationUri,
 			Contact:         src.Contact,
+		})
+	}
+
+	// Zelfde pad als POST /apis/_bulk, in batches van maximaal maxBulkItems en
+	// afgeremd zoals voorheen per losse registratie.
+	var summary models.ApiBulkSummary
+	var failures []models.ApiBulkResult
+	for start := 0; start < len(items); start += maxBulkItems {
+		end := min(start+maxBulkItems, len(items))
+		report, err := s.apiService.bulkCreateApis(ctx, items[start:end], s.limiter)
+		if err != nil {
+			return fmt.Errorf("bulk registratie mislukt: %w", err)
 		}
-
-		if err := limiter.Wait(ctx); err != nil {
-			return fmt.Errorf("limiter error: %w", err)
-		}
-
-		if _, err := s.apiService.CreateApiFromOas(payload); err != nil {
-			aggErrs = append(aggErrs, fmt.Sprintf("%s: create api from oas failed: %v", oasURL, err))
-			continue
+		summary.Total += report.Summary.Total
+		summary.Created += report.Summary.Created
+		summary.Exists += report.Summary.Exists
+		for _, res := range report.Results {
+			if res.Status != models.BulkStatusCreated && res.Status != models.BulkStatusExists {
+				failures = append(failures, res)
+			}
 		}
-		successCount++
 	}
 
-	log.Printf("[harvest %s] afgerond: candidates=%d success=%d failures=%d", src.Name, len(hrefs), successCount, len(aggErrs))
+	log.Printf("[harvest %s] afgerond: candidates=%d created=%d exists=%d failures=%d", src.Name, summary.Total, summary.Created, summary.Exists, len(failures))
 
-	if len(aggErrs) > 0 {
-		return fmt.Errorf("%d failures; first: %s", len(aggErrs), aggErrs[0])
+	if len(failures) > 0 {
+		first := failures[0]
+		return fmt.Errorf("%d failures; first: %s: %s: %s", len(failures), first.OasUrl, first.Status, first.Detail)
 	}
 	return nil
 }
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	problem "github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/problem"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/util"
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/models"
+)
+
+// RetrieveOrganisation geeft een organisatie met het aantal APIs per
+// lifecycle-status.
+func (s *APIsAPIService) RetrieveOrganisation(ctx context.Context, uri string) (*models.OrganisationDetail, error) {
+	org, err := s.findOrganisation(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	return s.organisationDetail(ctx, org)
+}
+
+// UpdateOrganisation past het label van een organisatie aan en werkt de
+// zoekindex bij voor alle APIs van die organisatie.
+func (s *APIsAPIService) UpdateOrganisation(ctx context.Context, body *models.OrganisationUpdateInput) (*models.OrganisationDetail, error) {
+	org, err := s.findOrganisation(ctx, body.OrganisationURI())
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeOrganisation(ctx, org.Uri); err != nil {
+		return nil, err
+	}
+	label := strings.TrimSpace(body.Label)
+	if label == "" {
+		return nil, problem.NewBadRequest(org.Uri, "label is verplicht",
+			problem.InvalidParam{Name: "label", Reason: "label is verplicht"})
+	}
+	if label != org.Label {
+		before := org.Label
+		org.Label = label
+		if err := s.repo.SaveOrganisatie(org); err != nil {
+			return nil, err
+		}
+		// Het label staat in de representatie van elke API van de organisatie.
+		if err := s.repo.BumpApiRevisionsByOrganisation(ctx, org.Uri); err != nil {
+			return nil, err
+		}
+		s.saveAudit(ctx, &models.AuditEntry{
+			OrganisationUri: org.Uri,
+			Action:          models.AuditActionOrganisationUpdated,
+			Changes:         []models.FieldChange{{Field: "label", Before: before, After: label}},
+		})
+		s.reindexOrganisationApis(ctx, org.Uri, nil)
+	}
+	return s.organisationDetail(ctx, org)
+}
+
+// DeleteOrganisation verwijdert een organisatie waar geen APIs meer naar
+// verwijzen; ook verwijderde APIs binnen de retentieperiode tellen mee.
+func (s *APIsAPIService) DeleteOrganisation(ctx context.Context, uri string) error {
+	org, err := s.findOrganisation(ctx, uri)
+	if err != nil {
+		return err
+	}
+	if err := authorizeOrganisation(ctx, org.Uri); err != nil {
+		return err
+	}
+	count, err := s.repo.CountApisByOrganisation(ctx, org.Uri)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return problem.NewConflict(org.Uri, fmt.Sprintf("Er verwijzen nog %d API's naar deze organisatie", count))
+	}
+	if err := s.repo.DeleteOrganisation(ctx, org.Uri); err != nil {
+		return problem.NewInternalServerError("kan organisatie niet verwijderen: " + err.Error())
+	}
+	s.saveAudit(ctx, &models.AuditEntry{
+		OrganisationUri: org.Uri,
+		Action:          models.AuditActionOrganisationDeleted,
+		Changes: []models.FieldChange{
+			{Field: "uri", Before: org.Uri},
+			{Field: "label", Before: org.Label},
+		},
+	})
+	return nil
+}
+
+// MergeOrganisations verplaatst alle APIs van een dubbele organisatie naar de
+// canonieke en verwijdert de dubbele.
+func (s *APIsAPIService) MergeOrganisations(ctx context.Context, body *models.OrganisationMergeInput) (*models.OrganisationDetail, error) {
+	duplicateURI := strings.TrimSpace(body.DuplicateUri)
+	canonicalURI := strings.TrimSpace(body.CanonicalUri)
+	if duplicateURI == canonicalURI {
+		return nil, problem.NewBadRequest(duplicateURI, "duplicateUri en canonicalUri moeten verschillen",
+			problem.InvalidParam{Name: "canonicalUri", Reason: "Moet een andere organisatie zijn dan duplicateUri"})
+	}
+	duplicate, err := s.findOrganisation(ctx, duplicateURI)
+	if err != nil {
+		return nil, err
+	}
+	canonical, err := s.findOrganisation(ctx, canonicalURI)
+	if err != nil {
+		return nil, err
+	}
+
+	moved, err := s.repo.MergeOrganisations(ctx, duplicate.Uri, canonical.Uri)
+	if err != nil {
+		return nil, problem.NewInternalServerError("kan organisaties niet samenvoegen: " + err.Error())
+	}
+	s.saveAudit(ctx, &models.AuditEntry{
+		OrganisationUri: duplicate.Uri,
+		Action:          models.AuditActionOrganisationMerged,
+		Changes: []models.FieldChange{
+			{Field: "mergedInto", After: canonical.Uri},
+			{Field: "apis", After: moved},
+		},
+	})
+	if len(moved) > 0 {
+		s.reindexOrganisationApis(ctx, canonical.Uri, moved)
+	}
+	return s.organisationDetail(ctx, canonical)
+}
+
+func (s *APIsAPIService) findOrganisation(ctx context.Context, uri string) (*models.Organisation, error) {
+	uri = strings.TrimSpace(uri)
+	org, err := s.repo.FindOrganisationByURI(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	if org == nil {
+		return nil, problem.NewNotFound(uri, "Organisation not found")
+	}
+	return org, nil
+}
+
+func (s *APIsAPIService) organisationDetail(ctx context.Context, org *models.Organisation) (*models.OrganisationDetail, error) {
+	apis, err := s.repo.ListApisByOrganisation(ctx, org.Uri)
+	if err != nil {
+		return nil, err
+	}
+	detail := &models.OrganisationDetail{OrganisationSummary: util.ToOrganisationSummary(org)}
+	now := time.Now()
+	for _, api := range apis {
+		detail.ApiCounts.Total++
+		switch api.LifecycleStatus(now) {
+		case "deprecated":
+			detail.ApiCounts.Deprecated++
+		case "sunset":
+			detail.ApiCounts.Sunset++
+		case "retired":
+			detail.ApiCounts.Retired++
+		default:
+			detail.ApiCounts.Active++
+		}
+	}
+	return detail, nil
+}
+
+// reindexOrganisationApis stuurt de APIs van een organisatie opnieuw naar
+// Typesense, bv. na een gewijzigd label, en meldt de gewijzigde APIs in de
+// wijzigingsfeed. Zonder changed zijn ze allemaal gewijzigd.
+func (s *APIsAPIService) reindexOrganisationApis(ctx context.Context, uri string, changed []string) {
+	apis, err := s.repo.ListApisByOrganisation(ctx, uri)
+	if err != nil {
+		log.Printf("[typesense] kan APIs van organisatie %s niet ophalen voor herindexering: %v", uri, err)
+		return
+	}
+	for _, api := range apis {
+		if isPublicApi(&api) && (changed == nil || slices.Contains(changed, api.Id)) {
+			s.recordApiChange(ctx, api.Id, models.ApiChangeUpdated)
+		}
+	}
+	go func() {
+		for _, api := range apis {
+			s.publishToTypesense(api)
+		}
+	}()
+}
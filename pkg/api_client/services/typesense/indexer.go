@@ -167,6 +167,52 @@ func PublishApi(ctx context.Context, api *models.Api) (err error) {
 	return nil
 }
 
+// DeleteApi removes the document of the API from Typesense. A missing
+// document is not an error.
+func DeleteApi(ctx context.Context, apiID string) (err error) {
+	apiID = strings.TrimSpace(apiID)
+	if apiID == "" {
+		return fmt.Errorf("typesense: api id is empty")
+	}
+
+	cfg := loadConfigFromEnv()
+	if !cfg.enabled() {
+		return ErrDisabled
+	}
+
+	base := strings.TrimRight(cfg.endpoint, "/")
+	target := fmt.Sprintf("%s/collections/%s/documents/%s", base, url.PathEscape(cfg.collection), url.PathEscape(apiID))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, target, nil)
+	if err != nil {
+		return fmt.Errorf("typesense: create request: %w", err)
+	}
+	req.Header.Set("X-TYPESENSE-API-KEY", cfg.apiKey)
+
+	resp, err := httpclient.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("typesense: request failed: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("typesense: close response body: %w", closeErr)
+		}
+	}()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		body, readErr := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if readErr != nil {
+			return fmt.Errorf("typesense: read error response: %w", readErr)
+		}
+		return fmt.Errorf("typesense: delete failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
+
 func buildDocument(cfg config, api *models.Api) map[string]any {
 	doc := map[string]any{
 		"type":          "doc",
@@ -176,3 +176,47 @@ func TestPublishApi_SendsDocument(t *testing.T) {
 		}
 	}
 }
+
+func TestDeleteApi_SendsDeleteAndIgnoresMissingDocument(t *testing.T) {
+	var capturedMethod, capturedPath, capturedKey string
+	status := http.StatusOK
+	server := testutil.NewTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedMethod = r.Method
+		capturedPath = r.URL.Path
+		capturedKey = r.Header.Get("X-TYPESENSE-API-KEY")
+		w.WriteHeader(status)
+	}))
+
+	t.Setenv("TYPESENSE_ENDPOINT", server.URL)
+	t.Setenv("TYPESENSE_API_KEY", "secret")
+	t.Setenv("TYPESENSE_COLLECTION", "apis")
+
+	prevClient := httpclient.HTTPClient
+	httpclient.HTTPClient = server.Client()
+	t.Cleanup(func() {
+		httpclient.HTTPClient = prevClient
+	})
+
+	if err := typesense.DeleteApi(context.Background(), "api-1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if capturedMethod != http.MethodDelete {
+		t.Fatalf("expected DELETE, got %s", capturedMethod)
+	}
+	if capturedPath != "/collections/apis/documents/api-1" {
+		t.Fatalf("unexpected path %s", capturedPath)
+	}
+	if capturedKey != "secret" {
+		t.Fatalf("expected api key header, got %q", capturedKey)
+	}
+
+	status = http.StatusNotFound
+	if err := typesense.DeleteApi(context.Background(), "api-1"); err != nil {
+		t.Fatalf("expected missing document to be ignored, got %v", err)
+	}
+
+	status = http.StatusInternalServerError
+	if err := typesense.DeleteApi(context.Background(), "api-1"); err == nil {
+		t.Fatalf("expected error on server failure")
+	}
+}
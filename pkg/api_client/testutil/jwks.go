@@ -0,0 +1,95 @@
+package testutil
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testKeyID = "test-key"
+
+// TokenIssuer signs bearer tokens for tests and exposes the matching JWKS as a local file.
+type TokenIssuer struct {
+	Issuer   string
+	Audience string
+	JWKSFile string
+	key      *rsa.PrivateKey
+}
+
+// NewTokenIssuer generates an RSA key and writes its public part to a JWKS file in a temp dir.
+func NewTokenIssuer(t *testing.T, issuer, audience string) *TokenIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	jwks := map[string]any{
+		"keys": []map[string]string{{
+			"kid": testKeyID,
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	}
+	data, err := json.Marshal(jwks)
+	if err != nil {
+		t.Fatalf("marshal jwks: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("write jwks: %v", err)
+	}
+	return &TokenIssuer{Issuer: issuer, Audience: audience, JWKSFile: path, key: key}
+}
+
+// Token returns a signed token for subject with the given scopes, valid for one hour.
+func (i *TokenIssuer) Token(t *testing.T, subject string, scopes ...string) string {
+	t.Helper()
+	return i.SignClaims(t, jwt.MapClaims{
+		"iss":   i.Issuer,
+		"aud":   i.Audience,
+		"sub":   subject,
+		"scope": strings.Join(scopes, " "),
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	})
+}
+
+// OrganisationToken returns a signed token whose "organisations" claim maps
+// the client to the given organisation URIs.
+func (i *TokenIssuer) OrganisationToken(t *testing.T, subject string, organisations []string, scopes ...string) string {
+	t.Helper()
+	return i.SignClaims(t, jwt.MapClaims{
+		"iss":           i.Issuer,
+		"aud":           i.Audience,
+		"sub":           subject,
+		"scope":         strings.Join(scopes, " "),
+		"organisations": organisations,
+		"iat":           time.Now().Unix(),
+		"exp":           time.Now().Add(time.Hour).Unix(),
+	})
+}
+
+// SignClaims signs arbitrary claims with the issuer key.
+func (i *TokenIssuer) SignClaims(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testKeyID
+	signed, err := token.SignedString(i.key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
@@ -5,9 +5,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/developer-overheid-nl/don-api-register/pkg/api_client/helpers/httpclient"
 )
 
 // NewTestServer starts an httptest.Server, or skips the test if binding a port is not permitted.
+// While the test runs the default fetcher accepts loopback addresses so the server can be used as an OAS source.
 func NewTestServer(t *testing.T, handler http.Handler) *httptest.Server {
 	t.Helper()
 
@@ -22,5 +25,14 @@ func NewTestServer(t *testing.T, handler http.Handler) *httptest.Server {
 	}
 	srv.Start()
 	t.Cleanup(srv.Close)
+	t.Cleanup(AllowLoopbackFetches())
 	return srv
 }
+
+// AllowLoopbackFetches replaces the default fetcher with one that accepts 127.0.0.0/8 and returns a restore function.
+func AllowLoopbackFetches() (restore func()) {
+	policy := httpclient.DefaultFetchPolicy()
+	_, loopback, _ := net.ParseCIDR("127.0.0.0/8")
+	policy.AllowNets = []*net.IPNet{loopback}
+	return httpclient.SetDefaultFetcher(httpclient.NewFetcher(policy))
+}
@@ -7,9 +7,11 @@ SOURCE_API_KEY=""
 TARGET_API_KEY=""
 
 PER_PAGE="${PER_PAGE:-100}"
+BULK_MAX_ITEMS=100 # maximum aantal items per POST /apis/_bulk van de server
 SLEEP_SECONDS="${SLEEP_SECONDS:-1}"
 OUT="${OUT:-sync-errors.json}"
 SKIP_ORGANISATIONS="0" # Set to "1" or "true" to skip synchronizing organisations
+UPDATED_SINCE="${UPDATED_SINCE:-}" # RFC 3339; alleen APIs die sindsdien zijn aangemaakt of gewijzigd
 
 
 declare -a SOURCE_AUTH_ARGS=()
@@ -296,6 +298,75 @@ post_target() {
   fi
 }
 
+# post_target_bulk registreert hoogstens BULK_MAX_ITEMS APIs in één request via
+# POST /apis/_bulk en telt de uitkomst per item.
+post_target_bulk() {
+  local payloads="$1"
+  local http_code
+  local body_file
+
+  body_file="$(mktemp)"
+  echo "POST URL: ${TARGET_BASE_URL}/apis/_bulk ($(jq 'length' <<<"$payloads") items)" >&2
+  echo "POST Header: Authorization: Bearer <redacted>" >&2
+  http_code="$(request_target_once "${TARGET_BASE_URL}/apis/_bulk" "$payloads" "$body_file")"
+
+  if [[ "$http_code" == "401" ]]; then
+    echo
+    echo "401 ontvangen van doel voor bulkregistratie."
+    prompt_target_auth
+    http_code="$(request_target_once "${TARGET_BASE_URL}/apis/_bulk" "$payloads" "$body_file")"
+    if [[ "$http_code" == "401" ]]; then
+      echo "Nog steeds 401 van het doel na opnieuw invoeren van credentials. Stop." >&2
+      rm -f "$body_file"
+      exit 1
+    fi
+  fi
+
+  if [[ "$http_code" != "207" ]]; then
+    append_error "api-bulk" "bulk" "$payloads" "$http_code" "$body_file"
+    api_failed=$((api_failed + $(jq 'length' <<<"$payloads")))
+    echo "POST [api] bulk -> ${http_code}"
+    rm -f "$body_file"
+    return 0
+  fi
+
+  while read -r result; do
+    local index
+    local oas_url
+    local status
+    local error_body
+
+    index="$(jq -r '.index' <<<"$result")"
+    oas_url="$(jq -r '.oasUrl // empty' <<<"$result")"
+    status="$(jq -r '.status' <<<"$result")"
+
+    case "$status" in
+      created)
+        api_success=$((api_success + 1))
+        echo "POST [api] ${oas_url} -> created"
+        ;;
+      exists)
+        api_skipped=$((api_skipped + 1))
+        echo "SKIP [api] ${oas_url} -> exists (bestaat al)"
+        ;;
+      *)
+        error_body="$(mktemp)"
+        jq '.' <<<"$result" > "$error_body"
+        append_error "api" "$oas_url" "$(jq -c --argjson i "$index" '.[$i]' <<<"$payloads")" "$(jq -r '.httpStatus' <<<"$result")" "$error_body"
+        api_failed=$((api_failed + 1))
+        rm -f "$error_body"
+        echo "POST [api] ${oas_url} -> ${status}"
+        ;;
+    esac
+  done < <(jq -c '.results[]' "$body_file")
+
+  rm -f "$body_file"
+
+  if [[ "$SLEEP_SECONDS" != "0" ]]; then
+    sleep "$SLEEP_SECONDS"
+  fi
+}
+
 build_api_payload() {
   local item="$1"
 
@@ -354,14 +425,17 @@ sync_apis() {
     body_file="$(mktemp)"
     headers_file="$(mktemp)"
     page_url="${SOURCE_BASE_URL}/v1/apis?page=${page}&perPage=${PER_PAGE}"
+    if [[ -n "$UPDATED_SINCE" ]]; then
+      page_url="${page_url}&updatedSince=$(jq -rn --arg v "$UPDATED_SINCE" '$v|@uri')"
+    fi
 
     fetch_source_or_die "$page_url" "$body_file" "$headers_file" "apis pagina ${page}"
     echo "APIs opgehaald uit bron, pagina ${page}."
 
+    local payloads='[]'
     while read -r item; do
       local oas_url
       local organisation_uri
-      local payload
       local request_json
       local error_body
 
@@ -379,10 +453,18 @@ sync_apis() {
         continue
       fi
 
-      payload="$(build_api_payload "$item")"
-      post_target "/apis" "api" "$oas_url" "$payload"
+      payloads="$(jq -c --argjson item "$(build_api_payload "$item")" '. + [$item]' <<<"$payloads")"
     done < <(jq -c '.[]' "$body_file")
 
+    # Een pagina kan groter zijn dan wat de bulkregistratie accepteert; stuur
+    # hem in stukken van hoogstens BULK_MAX_ITEMS.
+    local total
+    local offset
+    total="$(jq 'length' <<<"$payloads")"
+    for ((offset = 0; offset < total; offset += BULK_MAX_ITEMS)); do
+      post_target_bulk "$(jq -c --argjson o "$offset" --argjson n "$BULK_MAX_ITEMS" '.[$o:$o + $n]' <<<"$payloads")"
+    done
+
     next_page="$(extract_next_page "$headers_file")"
     rm -f "$body_file" "$headers_file"
 
@@ -402,6 +484,9 @@ fi
 echo "Bron: ${SOURCE_BASE_URL}"
 echo "Doel: ${TARGET_BASE_URL}"
 echo "Errors worden opgeslagen in: ${OUT}"
+if [[ -n "$UPDATED_SINCE" ]]; then
+  echo "Alleen APIs gewijzigd sinds: ${UPDATED_SINCE}"
+fi
 
 if [[ "$SKIP_ORGANISATIONS" == "1" || "$SKIP_ORGANISATIONS" == "true" ]]; then
   echo "Organisations overslaan (SKIP_ORGANISATIONS=${SKIP_ORGANISATIONS})."